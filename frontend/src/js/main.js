@@ -9,6 +9,7 @@ const state = {
     selectedChannel: null,
     logs: [],
     config: null,
+    configOverrides: {}, // Campos impuestos por entorno o flags (campo -> origen)
     connectedClients: [],
     logFilter: 'all'
 };
@@ -63,6 +64,7 @@ async function loadChannels() {
 async function loadConfig() {
     try {
         state.config = await window.go.app.App.GetConfig();
+        state.configOverrides = await window.go.app.App.GetConfigOverrides();
         applyConfig();
     } catch (error) {
         console.error('Error cargando configuración:', error);
@@ -144,12 +146,28 @@ function setupWailsEvents() {
             if (data.currentFile) {
                 state.channels[index].currentFile = data.currentFile;
             }
+            if (data.restart !== undefined) {
+                state.channels[index].restart = data.restart;
+            }
+            if (data.event === 'transition') {
+                state.channels[index].errorCode = data.errorCode || '';
+                state.channels[index].errorMessage = data.status === 'error' ? data.message : '';
+            }
             // Re-renderizar todo el grid y lista para asegurar sincronización
             renderChannelGrid();
             renderChannelList();
         }
     });
     
+    // Reinicio automático (intento registrado en el historial)
+    window.runtime.EventsOn('channel:restart', (data) => {
+        const index = state.channels.findIndex(c => c.id === data.channelId);
+        if (index !== -1) {
+            state.channels[index].restart = data.restart;
+            renderChannelGrid();
+        }
+    });
+    
     // Nuevo log
     window.runtime.EventsOn('log:new', (entry) => {
         state.logs.push(entry);
@@ -173,6 +191,24 @@ function setupWailsEvents() {
         updateClientCount();
     });
     
+    // Configuración recargada (config.json editado fuera de la aplicación)
+    window.runtime.EventsOn('config:updated', (data) => {
+        state.config = data.config;
+        applyConfig();
+        if (data.source === 'archivo') {
+            showToast('info', 'Configuración recargada', 'Se aplicaron los cambios de config.json');
+            reportConfigUpdate(data.update);
+        }
+    });
+    
+    // Preset activado (desde la interfaz o por un cliente WebSocket)
+    window.runtime.EventsOn('preset:activated', (result) => {
+        reportPresetActivation(result);
+        if (document.getElementById('presetsModal')?.classList.contains('open')) {
+            loadPresets();
+        }
+    });
+    
     // Warning de FFmpeg (fallback de encoder)
     window.runtime.EventsOn('ffmpeg:warning', (data) => {
         console.log('[EVENT] ffmpeg:warning', data);
@@ -203,6 +239,14 @@ function setupEventListeners() {
     document.getElementById('btnSelectTestPattern')?.addEventListener('click', selectTestPatternPath);
     document.querySelector('#settingsModal .modal-overlay')?.addEventListener('click', closeSettingsModal);
     
+    // Modal de presets
+    document.getElementById('btnPresets')?.addEventListener('click', openPresetsModal);
+    document.getElementById('btnClosePresetsModal')?.addEventListener('click', closePresetsModal);
+    document.getElementById('btnCancelPresets')?.addEventListener('click', closePresetsModal);
+    document.getElementById('btnSavePreset')?.addEventListener('click', savePreset);
+    document.getElementById('btnImportPreset')?.addEventListener('click', importPreset);
+    document.querySelector('#presetsModal .modal-overlay')?.addEventListener('click', closePresetsModal);
+    
     // Tabs de configuración
     document.querySelectorAll('.settings-tabs .tab-btn').forEach(btn => {
         btn.addEventListener('click', (e) => switchSettingsTab(e.target.dataset.tab));
@@ -215,6 +259,7 @@ function setupEventListeners() {
         state.logFilter = e.target.value;
         renderLogs();
     });
+    document.getElementById('logLevel')?.addEventListener('change', (e) => setLogLevel(e.target.value));
     
     // Modal de confirmación
     document.getElementById('btnConfirmCancel')?.addEventListener('click', closeConfirmModal);
@@ -305,7 +350,7 @@ function renderChannelGrid() {
             <div class="channel-card-header">
                 <div class="channel-card-title">
                     <span class="status-indicator ${channel.status}"></span>
-                    <h3>${escapeHtml(channel.label)} <span class="channel-id-label">${channel.id.substring(0, 8)}</span></h3>
+                    <h3>${escapeHtml(channel.label)} <span class="channel-id-label">${channel.id.substring(0, 8)}</span>${ephemeralBadge(channel)}</h3>
                 </div>
                 <div class="channel-card-actions">
                     <button class="btn btn-icon btn-sm" onclick="editChannel('${channel.id}')" title="Editar">
@@ -322,15 +367,18 @@ function renderChannelGrid() {
                     <span class="value srt-address">
                         <input type="text" class="inline-input srt-host-input" value="${channel.srtHost || '0.0.0.0'}" 
                             onchange="updateSRTHost('${channel.id}', this.value)" 
-                            ${channel.status === 'active' ? 'disabled' : ''} 
+                            ${isChannelRunning(channel.status) ? 'disabled' : ''} 
                             style="width: 100px;" placeholder="IP">
                         <span>:</span>
-                        <span class="srt-port">${channel.srtPort || 9000}</span>
+                        <input type="number" class="inline-input srt-port-input" value="${channel.srtPort || 9000}"
+                            onchange="updateSRTPort('${channel.id}', this.value)"
+                            ${isChannelRunning(channel.status) ? 'disabled' : ''}
+                            min="1" max="65535" style="width: 70px;" placeholder="Puerto">
                     </span>
                 </div>
                 <div class="channel-info-row">
                     <span class="label">Estado</span>
-                    <span class="value status-${channel.status}">${getStatusText(channel.status)}</span>
+                    <span class="value status-${channel.status}" title="${escapeHtml(channel.errorMessage || '')}">${getStatusText(channel.status)}${channel.errorCode ? ` (${channel.errorCode})` : ''}</span>
                 </div>
                 <div class="channel-info-row">
                     <span class="label">Archivo</span>
@@ -338,9 +386,10 @@ function renderChannelGrid() {
                         ${channel.currentFile ? escapeHtml(getFileName(channel.currentFile)) : '<em>Sin archivo</em>'}
                     </span>
                 </div>
+                ${renderRestartInfo(channel)}
             </div>
             <div class="channel-card-footer">
-                ${channel.status === 'active' 
+                ${isChannelRunning(channel.status) 
                     ? `<button class="btn btn-danger btn-sm" onclick="stopChannel('${channel.id}')" title="Detener">
                         <i class="fas fa-stop"></i> Detener
                        </button>`
@@ -356,6 +405,32 @@ function renderChannelGrid() {
     `).join('');
 }
 
+// Fila con el estado de reinicio automático (solo si el canal ha fallado alguna vez)
+function renderRestartInfo(channel) {
+    const restart = channel.restart;
+    if (!restart) {
+        return '';
+    }
+    
+    const history = (restart.history || []).slice(-5).reverse().map(h =>
+        `${new Date(h.time).toLocaleTimeString()} #${h.attempt} ${h.result}${h.error ? ': ' + h.error : ''}`
+    ).join('\n');
+    
+    let text = `${restart.count} reinicios`;
+    if (restart.circuitOpen) {
+        text = `<span class="status-error">Suspendido (${restart.attempts} fallos)</span>`;
+    } else if (restart.nextAttempt) {
+        const limit = restart.maxAttempts > 0 ? `/${restart.maxAttempts}` : '';
+        text += ` · intento ${restart.attempts}${limit} a las ${new Date(restart.nextAttempt).toLocaleTimeString()}`;
+    }
+    
+    return `
+                <div class="channel-info-row">
+                    <span class="label">Reinicios</span>
+                    <span class="value restart-info" title="${escapeHtml(history || 'Sin historial')}">${text}</span>
+                </div>`;
+}
+
 // Actualizar solo el estado de una tarjeta sin reconstruir todo el grid
 function updateChannelCardStatus(channelId, channel) {
     console.log('[UPDATE] updateChannelCardStatus', channelId, 'status:', channel.status);
@@ -373,7 +448,7 @@ function updateChannelCardStatus(channelId, channel) {
     }
     
     // Actualizar texto de estado (buscar por clase que empiece con status-)
-    const statusText = card.querySelector('[class*="status-active"], [class*="status-inactive"], [class*="status-error"]');
+    const statusText = card.querySelector('[class*="status-active"], [class*="status-inactive"], [class*="status-error"], [class*="status-starting"], [class*="status-stopping"]');
     if (statusText) {
         statusText.className = `value status-${channel.status}`;
         statusText.textContent = getStatusText(channel.status);
@@ -394,7 +469,7 @@ function updateChannelCardStatus(channelId, channel) {
         const firstBtn = footer.querySelector('button:first-child');
         if (firstBtn) {
             console.log('[UPDATE] Updating button, status is:', channel.status);
-            if (channel.status === 'active') {
+            if (isChannelRunning(channel.status)) {
                 firstBtn.className = 'btn btn-danger btn-sm';
                 firstBtn.title = 'Detener';
                 firstBtn.onclick = () => stopChannel(channelId);
@@ -487,6 +562,25 @@ async function updateSRTHost(channelId, host) {
     }
 }
 
+async function updateSRTPort(channelId, value) {
+    const port = parseInt(value, 10);
+    try {
+        await window.go.app.App.SetChannelSRTPort(channelId, port);
+        
+        const index = state.channels.findIndex(c => c.id === channelId);
+        if (index !== -1) {
+            state.channels[index].srtPort = port;
+        }
+        
+        showToast('success', 'Puerto SRT actualizado', `Stream en el puerto ${port}`);
+    } catch (error) {
+        console.error('Error actualizando puerto SRT:', error);
+        showToast('error', 'Error', error.message || 'No se pudo asignar el puerto');
+        // Restaurar el puerto anterior en la tarjeta
+        renderChannelGrid();
+    }
+}
+
 function copySRTUrl(port) {
     // Intentar obtener la IP del servidor desde la configuración o usar placeholder
     const serverIP = state.serverIP || 'IP_SERVIDOR';
@@ -539,6 +633,7 @@ function editChannel(channelId) {
     document.getElementById('channelId').value = channel.id;
     document.getElementById('channelLabel').value = channel.label;
     document.getElementById('channelSRTName').value = channel.srtStreamName;
+    setChannelOutputForm(channel);
     
     openModal('channelModal');
 }
@@ -576,9 +671,31 @@ function openChannelModal() {
     document.getElementById('channelModalTitle').textContent = 'Nuevo Canal';
     document.getElementById('channelForm').reset();
     document.getElementById('channelId').value = '';
+    setChannelOutputForm({});
     openModal('channelModal');
 }
 
+// Parámetros de salida del canal (valores por defecto si no existen)
+function setChannelOutputForm(channel) {
+    document.getElementById('channelResolution').value = channel.resolution || '1920x1080';
+    document.getElementById('channelFrameRate').value = channel.frameRate || 30;
+    document.getElementById('channelScaleMode').value = channel.scaleMode || 'stretch';
+    document.getElementById('channelInterlace').value = channel.interlace || 'progressive';
+    document.getElementById('channelPixelFormat').value = channel.pixelFormat || 'yuv420p';
+    document.getElementById('channelAudioChannels').value = String(channel.audioChannels || 2);
+}
+
+function getChannelOutputForm() {
+    return {
+        resolution: document.getElementById('channelResolution').value.trim(),
+        frameRate: parseInt(document.getElementById('channelFrameRate').value, 10) || 0,
+        scaleMode: document.getElementById('channelScaleMode').value,
+        interlace: document.getElementById('channelInterlace').value,
+        pixelFormat: document.getElementById('channelPixelFormat').value,
+        audioChannels: parseInt(document.getElementById('channelAudioChannels').value, 10),
+    };
+}
+
 function closeChannelModal() {
     closeModal('channelModal');
 }
@@ -587,6 +704,7 @@ async function saveChannel() {
     const id = document.getElementById('channelId').value;
     const label = document.getElementById('channelLabel').value.trim();
     const srtStreamName = document.getElementById('channelSRTName').value.trim();
+    const output = getChannelOutputForm();
     
     if (!label) {
         showToast('warning', 'Campo requerido', 'Ingrese un nombre para el canal');
@@ -596,11 +714,14 @@ async function saveChannel() {
     try {
         if (id) {
             // Actualizar canal existente
+            // Salida primero: si no es válida no se modifica nada
+            await window.go.app.App.UpdateChannelOutput(id, output);
             await window.go.app.App.UpdateChannel(id, label, srtStreamName);
             showToast('success', 'Canal actualizado', `${label} ha sido actualizado`);
         } else {
             // Crear nuevo canal
-            await window.go.app.App.AddChannel(label, srtStreamName);
+            const channel = await window.go.app.App.AddChannel(label, srtStreamName);
+            await window.go.app.App.UpdateChannelOutput(channel.id, output);
             showToast('success', 'Canal creado', `${label} ha sido agregado`);
         }
         closeChannelModal();
@@ -610,6 +731,15 @@ async function saveChannel() {
     }
 }
 
+// Indicador de canal automático de cliente (se elimina tras la desconexión)
+function ephemeralBadge(channel) {
+    if (!channel.ephemeral) return '';
+    const title = channel.disconnectedAt
+        ? `Canal automático de ${channel.owner}: cliente desconectado, se eliminará si no vuelve`
+        : `Canal automático de ${channel.owner}`;
+    return ` <i class="fas fa-user-clock channel-ephemeral ${channel.disconnectedAt ? 'orphaned' : ''}" title="${escapeHtml(title)}"></i>`;
+}
+
 function openSettingsModal() {
     if (state.config) {
         applyConfigToForm();
@@ -630,21 +760,109 @@ function switchSettingsTab(tabId) {
     });
 }
 
+// Campo de configuración -> input del formulario de ajustes
+const settingsFields = {
+    webSocketPort: 'settingsWSPort',
+    ffmpegPath: 'settingsFFmpegPath',
+    testPatternPath: 'settingsTestPattern',
+    srtPrefix: 'settingsSRTPrefix',
+    theme: 'settingsTheme',
+    videoEncoder: 'settingsVideoEncoder',
+    encoderPreset: 'settingsEncoderPreset',
+    encoderProfile: 'settingsEncoderProfile',
+    encoderTune: 'settingsEncoderTune',
+    gopSize: 'settingsGopSize',
+    bFrames: 'settingsBFrames',
+    bitrateMode: 'settingsBitrateMode',
+    defaultVideoBitrate: 'settingsVideoBitrate',
+    defaultAudioBitrate: 'settingsAudioBitrate',
+    maxBitrate: 'settingsMaxBitrate',
+    bufferSize: 'settingsBufferSize',
+    defaultFrameRate: 'settingsFrameRate',
+    srtLatency: 'settingsSRTLatency',
+    srtRecvBuffer: 'settingsSRTRecvBuffer',
+    srtSendBuffer: 'settingsSRTSendBuffer',
+    srtOverheadBW: 'settingsSRTOverheadBW',
+    srtPeerIdleTime: 'settingsSRTPeerIdleTime'
+};
+
+// Marca los campos con error de validación (lista vacía = limpiar)
+function showSettingsErrors(errors) {
+    document.querySelectorAll('#settingsModal .field-invalid').forEach(input => {
+        input.classList.remove('field-invalid');
+        input.title = '';
+    });
+    errors.forEach(error => {
+        const input = document.getElementById(settingsFields[error.field]);
+        if (input) {
+            input.classList.add('field-invalid');
+            input.title = error.message;
+        }
+    });
+}
+
 async function saveSettings() {
     try {
         const config = getConfigFromForm();
         
-        await window.go.app.App.UpdateConfig(config);
+        const errors = await window.go.app.App.ValidateConfig(config);
+        showSettingsErrors(errors);
+        if (errors.length > 0) {
+            showToast('error', 'Configuración inválida',
+                errors.map(e => `${e.field}: ${e.message}`).join('; '));
+            return;
+        }
+        
+        const update = await window.go.app.App.UpdateConfig(config);
         state.config = config;
         applyConfig();
         closeSettingsModal();
         showToast('success', 'Configuración guardada', 'Los cambios han sido aplicados');
+        reportConfigUpdate(update);
     } catch (error) {
         console.error('Error guardando configuración:', error);
         showToast('error', 'Error', 'No se pudo guardar la configuración');
     }
 }
 
+// Avisa de los cambios que no se aplicaron en caliente
+function reportConfigUpdate(update) {
+    if (update.restartRequired) {
+        const fields = update.changes.filter(c => c.apply === 'restart').map(c => c.field);
+        showToast('warning', 'Reinicio necesario',
+            `Reinicie la aplicación para aplicar: ${fields.join(', ')}`);
+    }
+    if (update.affectedChannels.length > 0) {
+        confirmRestartChannels(update.affectedChannels);
+    }
+}
+
+function confirmRestartChannels(channelIds) {
+    const labels = channelIds
+        .map(id => state.channels.find(c => c.id === id)?.label || id)
+        .join(', ');
+    
+    document.getElementById('confirmTitle').textContent = 'Reiniciar Canales';
+    document.getElementById('confirmMessage').textContent =
+        `Los canales activos (${labels}) siguen con los parámetros de encoding/SRT anteriores. ¿Reiniciarlos ahora para aplicar la nueva configuración?`;
+    
+    const btnConfirm = document.getElementById('btnConfirmOk');
+    btnConfirm.onclick = () => restartChannels(channelIds);
+    
+    openModal('confirmModal');
+}
+
+async function restartChannels(channelIds) {
+    closeModal('confirmModal');
+    try {
+        await window.go.app.App.RestartChannels(channelIds);
+        showToast('success', 'Canales reiniciados', `${channelIds.length} canales usan la nueva configuración`);
+    } catch (error) {
+        console.error('Error reiniciando canales:', error);
+        showToast('error', 'Error', error.toString());
+    }
+}
+
 function applyConfigToForm() {
     if (!state.config) return;
     
@@ -678,10 +896,23 @@ function applyConfigToForm() {
     document.getElementById('settingsSRTSendBuffer').value = (state.config.srtSendBuffer || 8388608) / 1048576;
     document.getElementById('settingsSRTOverheadBW').value = state.config.srtOverheadBW || 25;
     document.getElementById('settingsSRTPeerIdleTime').value = state.config.srtPeerIdleTime || 5000;
+    
+    // Campos impuestos por variables de entorno o flags: solo lectura
+    Object.entries(settingsFields).forEach(([field, inputId]) => {
+        const input = document.getElementById(inputId);
+        const source = state.configOverrides[field];
+        if (input) {
+            input.disabled = !!source;
+            input.title = source ? `Impuesto por ${source}` : '';
+        }
+    });
 }
 
 function getConfigFromForm() {
     return {
+        // Conservar campos sin control en el formulario (integraciones, etc.)
+        ...state.config,
+
         // General
         webSocketPort: parseInt(document.getElementById('settingsWSPort').value) || 8765,
         ffmpegPath: document.getElementById('settingsFFmpegPath').value || 'ffmpeg',
@@ -725,6 +956,172 @@ function closeConfirmModal() {
     closeModal('confirmModal');
 }
 
+// ==================== Presets ====================
+async function openPresetsModal() {
+    document.getElementById('presetName').value = state.config?.activePreset || '';
+    document.getElementById('presetDescription').value = '';
+    openModal('presetsModal');
+    await loadPresets();
+}
+
+function closePresetsModal() {
+    closeModal('presetsModal');
+}
+
+async function loadPresets() {
+    try {
+        const presets = await window.go.app.App.ListPresets();
+        renderPresets(presets || []);
+    } catch (error) {
+        console.error('Error cargando presets:', error);
+        showToast('error', 'Error', 'No se pudieron cargar los presets');
+    }
+}
+
+function renderPresets(presets) {
+    const container = document.getElementById('presetList');
+    if (!container) return;
+    
+    if (presets.length === 0) {
+        container.innerHTML = '<p class="form-help">No hay presets guardados</p>';
+        return;
+    }
+    
+    const active = state.config?.activePreset;
+    container.innerHTML = presets.map(p => `
+        <div class="preset-item ${p.name === active ? 'active' : ''}">
+            <div class="preset-info">
+                <div class="preset-name">${escapeHtml(p.name)}${p.name === active ? ' <small>(activo)</small>' : ''}</div>
+                <div class="preset-meta">${p.channels} canales · ${new Date(p.createdAt).toLocaleString()}${p.description ? ' · ' + escapeHtml(p.description) : ''}</div>
+            </div>
+            <button class="btn btn-primary btn-sm" data-action="activate" title="Activar">
+                <i class="fas fa-play"></i>
+            </button>
+            <button class="btn btn-outline btn-sm" data-action="export" title="Exportar">
+                <i class="fas fa-file-export"></i>
+            </button>
+            <button class="btn btn-outline btn-sm" data-action="delete" title="Eliminar">
+                <i class="fas fa-trash"></i>
+            </button>
+        </div>
+    `).join('');
+    
+    container.querySelectorAll('.preset-item').forEach((item, i) => {
+        const name = presets[i].name;
+        item.querySelector('[data-action="activate"]').addEventListener('click', () => confirmActivatePreset(name));
+        item.querySelector('[data-action="export"]').addEventListener('click', () => exportPreset(name));
+        item.querySelector('[data-action="delete"]').addEventListener('click', () => confirmDeletePreset(name));
+    });
+}
+
+async function savePreset() {
+    const name = document.getElementById('presetName').value.trim();
+    const description = document.getElementById('presetDescription').value.trim();
+    if (!name) {
+        showToast('warning', 'Nombre requerido', 'Indique un nombre para el preset');
+        return;
+    }
+    
+    try {
+        const summary = await window.go.app.App.SavePreset(name, description);
+        showToast('success', 'Preset guardado', `"${summary.name}" con ${summary.channels} canales`);
+        await loadPresets();
+    } catch (error) {
+        console.error('Error guardando preset:', error);
+        showToast('error', 'Error', error.toString());
+    }
+}
+
+function confirmActivatePreset(name) {
+    const running = state.channels.filter(c => isChannelRunning(c.status)).length;
+    document.getElementById('confirmTitle').textContent = 'Activar Preset';
+    document.getElementById('confirmMessage').textContent =
+        `¿Activar "${name}"? Los canales que no están en el preset se eliminarán y los que cambian se detendrán` +
+        (running > 0 ? ` (hay ${running} canales emitiendo).` : '.');
+    
+    const btnConfirm = document.getElementById('btnConfirmOk');
+    btnConfirm.onclick = () => activatePreset(name);
+    
+    openModal('confirmModal');
+}
+
+async function activatePreset(name) {
+    closeModal('confirmModal');
+    try {
+        // El resultado se muestra al recibir 'preset:activated'
+        await window.go.app.App.ActivatePreset(name);
+        closePresetsModal();
+    } catch (error) {
+        console.error('Error activando preset:', error);
+        showToast('error', 'Error', error.toString());
+    }
+}
+
+// Resume los cambios de una activación y avisa de lo que falta aplicar
+function reportPresetActivation(result) {
+    const parts = [];
+    if (result.created.length) parts.push(`${result.created.length} creados`);
+    if (result.updated.length) parts.push(`${result.updated.length} modificados`);
+    if (result.removed.length) parts.push(`${result.removed.length} eliminados`);
+    showToast('success', `Preset "${result.name}" activado`,
+        parts.length ? parts.join(', ') : 'Los canales ya coincidían con el preset');
+    
+    if (result.warnings.length > 0) {
+        showToast('warning', 'Avisos del preset', result.warnings.join('; '));
+    }
+    if (result.config) {
+        reportConfigUpdate(result.config);
+    }
+}
+
+async function exportPreset(name) {
+    try {
+        const path = await window.go.app.App.ExportPreset(name);
+        if (path) {
+            showToast('success', 'Preset exportado', path);
+        }
+    } catch (error) {
+        console.error('Error exportando preset:', error);
+        showToast('error', 'Error', error.toString());
+    }
+}
+
+async function importPreset() {
+    try {
+        const summary = await window.go.app.App.ImportPreset();
+        if (summary) {
+            showToast('success', 'Preset importado', `"${summary.name}" con ${summary.channels} canales`);
+            await loadPresets();
+        }
+    } catch (error) {
+        console.error('Error importando preset:', error);
+        showToast('error', 'Error', error.toString());
+    }
+}
+
+function confirmDeletePreset(name) {
+    document.getElementById('confirmTitle').textContent = 'Eliminar Preset';
+    document.getElementById('confirmMessage').textContent =
+        `¿Eliminar el preset "${name}"? Los canales actuales no se modifican.`;
+    
+    const btnConfirm = document.getElementById('btnConfirmOk');
+    btnConfirm.onclick = () => deletePreset(name);
+    
+    openModal('confirmModal');
+}
+
+async function deletePreset(name) {
+    closeModal('confirmModal');
+    try {
+        await window.go.app.App.DeletePreset(name);
+        showToast('success', 'Preset eliminado', name);
+        await loadPresets();
+    } catch (error) {
+        console.error('Error eliminando preset:', error);
+        showToast('error', 'Error', error.toString());
+    }
+}
+
 // ==================== Logs Panel ====================
 function toggleLogsPanel() {
     const panel = document.getElementById('logsPanel');
@@ -735,6 +1132,18 @@ function toggleLogsPanel() {
     }
 }
 
+// setLogLevel cambia el nivel mínimo general; los subsistemas con nivel propio
+// (logLevelWebSocket, logLevelFFmpeg...) lo mantienen
+async function setLogLevel(level) {
+    try {
+        await window.go.app.App.SetLogLevel('', level);
+        showToast('info', 'Nivel de log', `Se registran las entradas desde ${level}`);
+    } catch (error) {
+        showToast('error', 'Error', `No se pudo cambiar el nivel de log: ${error}`);
+        applyConfig();
+    }
+}
+
 async function clearLogs() {
     try {
         await window.go.app.App.ClearLogs();
@@ -767,6 +1176,14 @@ function applyConfig() {
     } else {
         document.body.classList.remove('light-theme');
     }
+
+    const logLevel = document.getElementById('logLevel');
+    if (logLevel) {
+        logLevel.value = state.config?.logLevel || 'info';
+        const source = state.configOverrides?.logLevel;
+        logLevel.disabled = !!source;
+        logLevel.title = source ? `Impuesto por ${source}` : 'Nivel mínimo que se registra (logLevel)';
+    }
 }
 
 function showToast(type, title, message) {
@@ -814,6 +1231,11 @@ function getFileName(path) {
     return path.split(/[\\/]/).pop();
 }
 
+// Un canal iniciando o activo tiene un proceso FFmpeg que se puede detener
+function isChannelRunning(status) {
+    return status === 'active' || status === 'starting';
+}
+
 function getStatusText(status) {
     const statusMap = {
         'active': 'Activo',
@@ -860,3 +1282,4 @@ window.confirmDeleteChannel = confirmDeleteChannel;
 window.playTestPattern = playTestPattern;
 window.copySRTUrl = copySRTUrl;
 window.updateSRTHost = updateSRTHost;
+window.updateSRTPort = updateSRTPort;
@@ -4,6 +4,10 @@ import {channel} from '../models';
 import {config} from '../models';
 import {websocket} from '../models';
 import {app} from '../models';
+import {preset} from '../models';
+import {audit} from '../models';
+
+export function ActivatePreset(arg1:string):Promise<app.PresetActivation>;
 
 export function AddChannel(arg1:string,arg2:string):Promise<channel.Channel>;
 
@@ -11,22 +15,42 @@ export function AddLog(arg1:string,arg2:string,arg3:string):Promise<void>;
 
 export function ClearLogs():Promise<void>;
 
+export function DeletePreset(arg1:string):Promise<void>;
+
+export function ExportPreset(arg1:string):Promise<string>;
+
 export function GetChannels():Promise<Array<channel.Channel>>;
 
 export function GetConfig():Promise<config.Config>;
 
+export function GetConfigOverrides():Promise<{[key: string]: string}>;
+
 export function GetConnectedClients():Promise<Array<websocket.ClientInfo>>;
 
+export function GetLogLevels():Promise<app.LogLevels>;
+
 export function GetLogs():Promise<Array<app.LogEntry>>;
 
 export function GetVideoFiles(arg1:string):Promise<Array<string>>;
 
+export function ImportPreset():Promise<preset.Summary>;
+
+export function ListPresets():Promise<Array<preset.Summary>>;
+
 export function PlayTestPattern(arg1:string):Promise<void>;
 
 export function PlayVideoOnChannel(arg1:string,arg2:string):Promise<void>;
 
+export function QueryAudit(arg1:audit.Query):Promise<audit.Page>;
+
+export function QueryLogs(arg1:app.LogQuery):Promise<app.LogPage>;
+
 export function RemoveChannel(arg1:string):Promise<void>;
 
+export function RestartChannels(arg1:Array<string>):Promise<void>;
+
+export function SavePreset(arg1:string,arg2:string):Promise<preset.Summary>;
+
 export function SelectDirectory():Promise<string>;
 
 export function SelectTestPatternPath():Promise<string>;
@@ -35,6 +59,10 @@ export function SelectVideoPath():Promise<string>;
 
 export function SetChannelSRTHost(arg1:string,arg2:string):Promise<void>;
 
+export function SetChannelSRTPort(arg1:string,arg2:number):Promise<void>;
+
+export function SetLogLevel(arg1:string,arg2:string):Promise<app.LogLevels>;
+
 export function StartChannel(arg1:string):Promise<void>;
 
 export function StopAllStreams():Promise<void>;
@@ -45,4 +73,8 @@ export function ToggleChannel(arg1:string):Promise<void>;
 
 export function UpdateChannel(arg1:string,arg2:string,arg3:string):Promise<channel.Channel>;
 
-export function UpdateConfig(arg1:config.Config):Promise<void>;
+export function UpdateChannelOutput(arg1:string,arg2:channel.OutputSettings):Promise<channel.Channel>;
+
+export function UpdateConfig(arg1:config.Config):Promise<app.ConfigUpdate>;
+
+export function ValidateConfig(arg1:config.Config):Promise<Array<config.FieldError>>;
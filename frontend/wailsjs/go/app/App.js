@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ActivatePreset(arg1) {
+  return window['go']['app']['App']['ActivatePreset'](arg1);
+}
+
 export function AddChannel(arg1, arg2) {
   return window['go']['app']['App']['AddChannel'](arg1, arg2);
 }
@@ -14,6 +18,14 @@ export function ClearLogs() {
   return window['go']['app']['App']['ClearLogs']();
 }
 
+export function DeletePreset(arg1) {
+  return window['go']['app']['App']['DeletePreset'](arg1);
+}
+
+export function ExportPreset(arg1) {
+  return window['go']['app']['App']['ExportPreset'](arg1);
+}
+
 export function GetChannels() {
   return window['go']['app']['App']['GetChannels']();
 }
@@ -22,10 +34,18 @@ export function GetConfig() {
   return window['go']['app']['App']['GetConfig']();
 }
 
+export function GetConfigOverrides() {
+  return window['go']['app']['App']['GetConfigOverrides']();
+}
+
 export function GetConnectedClients() {
   return window['go']['app']['App']['GetConnectedClients']();
 }
 
+export function GetLogLevels() {
+  return window['go']['app']['App']['GetLogLevels']();
+}
+
 export function GetLogs() {
   return window['go']['app']['App']['GetLogs']();
 }
@@ -34,6 +54,14 @@ export function GetVideoFiles(arg1) {
   return window['go']['app']['App']['GetVideoFiles'](arg1);
 }
 
+export function ImportPreset() {
+  return window['go']['app']['App']['ImportPreset']();
+}
+
+export function ListPresets() {
+  return window['go']['app']['App']['ListPresets']();
+}
+
 export function PlayTestPattern(arg1) {
   return window['go']['app']['App']['PlayTestPattern'](arg1);
 }
@@ -42,10 +70,26 @@ export function PlayVideoOnChannel(arg1, arg2) {
   return window['go']['app']['App']['PlayVideoOnChannel'](arg1, arg2);
 }
 
+export function QueryAudit(arg1) {
+  return window['go']['app']['App']['QueryAudit'](arg1);
+}
+
+export function QueryLogs(arg1) {
+  return window['go']['app']['App']['QueryLogs'](arg1);
+}
+
 export function RemoveChannel(arg1) {
   return window['go']['app']['App']['RemoveChannel'](arg1);
 }
 
+export function RestartChannels(arg1) {
+  return window['go']['app']['App']['RestartChannels'](arg1);
+}
+
+export function SavePreset(arg1, arg2) {
+  return window['go']['app']['App']['SavePreset'](arg1, arg2);
+}
+
 export function SelectDirectory() {
   return window['go']['app']['App']['SelectDirectory']();
 }
@@ -62,6 +106,14 @@ export function SetChannelSRTHost(arg1, arg2) {
   return window['go']['app']['App']['SetChannelSRTHost'](arg1, arg2);
 }
 
+export function SetChannelSRTPort(arg1, arg2) {
+  return window['go']['app']['App']['SetChannelSRTPort'](arg1, arg2);
+}
+
+export function SetLogLevel(arg1, arg2) {
+  return window['go']['app']['App']['SetLogLevel'](arg1, arg2);
+}
+
 export function StartChannel(arg1) {
   return window['go']['app']['App']['StartChannel'](arg1);
 }
@@ -82,6 +134,14 @@ export function UpdateChannel(arg1, arg2, arg3) {
   return window['go']['app']['App']['UpdateChannel'](arg1, arg2, arg3);
 }
 
+export function UpdateChannelOutput(arg1, arg2) {
+  return window['go']['app']['App']['UpdateChannelOutput'](arg1, arg2);
+}
+
 export function UpdateConfig(arg1) {
   return window['go']['app']['App']['UpdateConfig'](arg1);
 }
+
+export function ValidateConfig(arg1) {
+  return window['go']['app']['App']['ValidateConfig'](arg1);
+}
@@ -1,10 +1,48 @@
 export namespace app {
 	
+	export class ConfigUpdate {
+	    changes: config.Change[];
+	    affectedChannels: string[];
+	    restartRequired: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.changes = this.convertValues(source["changes"], config.Change);
+	        this.affectedChannels = source["affectedChannels"];
+	        this.restartRequired = source["restartRequired"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class LogEntry {
+	    seq: number;
 	    timestamp: string;
 	    level: string;
+	    subsystem: string;
 	    message: string;
 	    channelId?: string;
+	    channelLabel?: string;
+	    clientId?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new LogEntry(source);
@@ -12,11 +50,297 @@ export namespace app {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seq = source["seq"];
 	        this.timestamp = source["timestamp"];
 	        this.level = source["level"];
+	        this.subsystem = source["subsystem"];
 	        this.message = source["message"];
 	        this.channelId = source["channelId"];
+	        this.channelLabel = source["channelLabel"];
+	        this.clientId = source["clientId"];
+	    }
+	}
+	export class LogLevels {
+	    default: string;
+	    subsystems: {[key: string]: string};
+	
+	    static createFrom(source: any = {}) {
+	        return new LogLevels(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.default = source["default"];
+	        this.subsystems = source["subsystems"];
+	    }
+	}
+	export class LogPage {
+	    entries: LogEntry[];
+	    total: number;
+	    offset: number;
+	    limit: number;
+	    hasMore: boolean;
+	    lastSeq: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], LogEntry);
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	        this.hasMore = source["hasMore"];
+	        this.lastSeq = source["lastSeq"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LogQuery {
+	    level?: string;
+	    subsystem?: string;
+	    channel?: string;
+	    clientId?: string;
+	    // Go type: time
+	    since?: any;
+	    // Go type: time
+	    until?: any;
+	    text?: string;
+	    afterSeq?: number;
+	    limit?: number;
+	    offset?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogQuery(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.level = source["level"];
+	        this.subsystem = source["subsystem"];
+	        this.channel = source["channel"];
+	        this.clientId = source["clientId"];
+	        this.since = this.convertValues(source["since"], null);
+	        this.until = this.convertValues(source["until"], null);
+	        this.text = source["text"];
+	        this.afterSeq = source["afterSeq"];
+	        this.limit = source["limit"];
+	        this.offset = source["offset"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PresetActivation {
+	    name: string;
+	    created: string[];
+	    updated: string[];
+	    removed: string[];
+	    unchanged: string[];
+	    warnings: string[];
+	    config?: ConfigUpdate;
+	
+	    static createFrom(source: any = {}) {
+	        return new PresetActivation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.created = source["created"];
+	        this.updated = source["updated"];
+	        this.removed = source["removed"];
+	        this.unchanged = source["unchanged"];
+	        this.warnings = source["warnings"];
+	        this.config = this.convertValues(source["config"], ConfigUpdate);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace audit {
+	
+	export class Entry {
+	    // Go type: time
+	    time: any;
+	    source: string;
+	    action: string;
+	    clientId?: string;
+	    clientName?: string;
+	    remoteAddr?: string;
+	    channelId?: string;
+	    channelLabel?: string;
+	    params?: any;
+	    success: boolean;
+	    errorCode?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.source = source["source"];
+	        this.action = source["action"];
+	        this.clientId = source["clientId"];
+	        this.clientName = source["clientName"];
+	        this.remoteAddr = source["remoteAddr"];
+	        this.channelId = source["channelId"];
+	        this.channelLabel = source["channelLabel"];
+	        this.params = source["params"];
+	        this.success = source["success"];
+	        this.errorCode = source["errorCode"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Page {
+	    entries: Entry[];
+	    total: number;
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Page(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], Entry);
+	        this.total = source["total"];
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Query {
+	    // Go type: time
+	    since?: any;
+	    // Go type: time
+	    until?: any;
+	    channel?: string;
+	    client?: string;
+	    source?: string;
+	    action?: string;
+	    limit?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Query(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.since = this.convertValues(source["since"], null);
+	        this.until = this.convertValues(source["until"], null);
+	        this.channel = source["channel"];
+	        this.client = source["client"];
+	        this.source = source["source"];
+	        this.action = source["action"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -52,14 +376,27 @@ export namespace channel {
 	    srtHost: string;
 	    resolution: string;
 	    frameRate: number;
+	    scaleMode: string;
+	    interlace: string;
+	    pixelFormat: string;
+	    audioChannels: number;
 	    status: string;
+	    // Go type: time
+	    statusSince: any;
 	    currentFile: string;
+	    loop?: boolean;
 	    // Go type: time
 	    createdAt: any;
 	    // Go type: time
 	    updatedAt: any;
 	    errorMessage?: string;
+	    errorCode?: string;
 	    stats: Stats;
+	    owner?: string;
+	    ephemeral?: boolean;
+	    // Go type: time
+	    disconnectedAt?: any;
+	    restart?: restart.State;
 	
 	    static createFrom(source: any = {}) {
 	        return new Channel(source);
@@ -75,12 +412,23 @@ export namespace channel {
 	        this.srtHost = source["srtHost"];
 	        this.resolution = source["resolution"];
 	        this.frameRate = source["frameRate"];
+	        this.scaleMode = source["scaleMode"];
+	        this.interlace = source["interlace"];
+	        this.pixelFormat = source["pixelFormat"];
+	        this.audioChannels = source["audioChannels"];
 	        this.status = source["status"];
+	        this.statusSince = this.convertValues(source["statusSince"], null);
 	        this.currentFile = source["currentFile"];
+	        this.loop = source["loop"];
 	        this.createdAt = this.convertValues(source["createdAt"], null);
 	        this.updatedAt = this.convertValues(source["updatedAt"], null);
 	        this.errorMessage = source["errorMessage"];
+	        this.errorCode = source["errorCode"];
 	        this.stats = this.convertValues(source["stats"], Stats);
+	        this.owner = source["owner"];
+	        this.ephemeral = source["ephemeral"];
+	        this.disconnectedAt = this.convertValues(source["disconnectedAt"], null);
+	        this.restart = this.convertValues(source["restart"], restart.State);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -101,11 +449,49 @@ export namespace channel {
 		    return a;
 		}
 	}
+	export class OutputSettings {
+	    resolution: string;
+	    frameRate: number;
+	    scaleMode: string;
+	    interlace: string;
+	    pixelFormat: string;
+	    audioChannels: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OutputSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.resolution = source["resolution"];
+	        this.frameRate = source["frameRate"];
+	        this.scaleMode = source["scaleMode"];
+	        this.interlace = source["interlace"];
+	        this.pixelFormat = source["pixelFormat"];
+	        this.audioChannels = source["audioChannels"];
+	    }
+	}
 
 }
 
 export namespace config {
 	
+	export class WebhookTarget {
+	    url: string;
+	    secret?: string;
+	    events?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WebhookTarget(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.secret = source["secret"];
+	        this.events = source["events"];
+	    }
+	}
 	export class Config {
 	    webSocketPort: number;
 	    ffmpegPath: string;
@@ -118,6 +504,18 @@ export namespace config {
 	    srtGroup: string;
 	    defaultVideoPath: string;
 	    logPath: string;
+	    logLevel: string;
+	    logLevelApp: string;
+	    logLevelWebSocket: string;
+	    logLevelFFmpeg: string;
+	    logLevelChannel: string;
+	    logToFile: boolean;
+	    logFormat: string;
+	    logMaxSizeMB: number;
+	    logRotateHours: number;
+	    logMaxFiles: number;
+	    logMaxAgeDays: number;
+	    ffmpegLogs: boolean;
 	    theme: string;
 	    language: string;
 	    maxLogLines: number;
@@ -136,6 +534,31 @@ export namespace config {
 	    srtSendBuffer: number;
 	    srtOverheadBW: number;
 	    srtPeerIdleTime: number;
+	    oscEnabled: boolean;
+	    oscPort: number;
+	    oscFeedbackTargets: string[];
+	    mqttEnabled: boolean;
+	    mqttBroker: string;
+	    mqttClientId: string;
+	    mqttUsername: string;
+	    mqttPassword: string;
+	    mqttTopicPrefix: string;
+	    mqttStatsInterval: number;
+	    webhooks: Array<WebhookTarget>;
+	    webhookMaxRetries: number;
+	    restartMaxAttempts: number;
+	    restartInitialDelay: number;
+	    restartMaxDelay: number;
+	    restartResetWindow: number;
+	    startTimeout: number;
+	    stopTimeout: number;
+	    stopGracePeriod: number;
+	    srtPortRanges: string;
+	    wsChannelManagement: boolean;
+	    wsAdminToken: string;
+	    clientChannelGracePeriod: number;
+	    version: number;
+	    activePreset: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -154,6 +577,18 @@ export namespace config {
 	        this.srtGroup = source["srtGroup"];
 	        this.defaultVideoPath = source["defaultVideoPath"];
 	        this.logPath = source["logPath"];
+	        this.logLevel = source["logLevel"];
+	        this.logLevelApp = source["logLevelApp"];
+	        this.logLevelWebSocket = source["logLevelWebSocket"];
+	        this.logLevelFFmpeg = source["logLevelFFmpeg"];
+	        this.logLevelChannel = source["logLevelChannel"];
+	        this.logToFile = source["logToFile"];
+	        this.logFormat = source["logFormat"];
+	        this.logMaxSizeMB = source["logMaxSizeMB"];
+	        this.logRotateHours = source["logRotateHours"];
+	        this.logMaxFiles = source["logMaxFiles"];
+	        this.logMaxAgeDays = source["logMaxAgeDays"];
+	        this.ffmpegLogs = source["ffmpegLogs"];
 	        this.theme = source["theme"];
 	        this.language = source["language"];
 	        this.maxLogLines = source["maxLogLines"];
@@ -172,7 +607,214 @@ export namespace config {
 	        this.srtSendBuffer = source["srtSendBuffer"];
 	        this.srtOverheadBW = source["srtOverheadBW"];
 	        this.srtPeerIdleTime = source["srtPeerIdleTime"];
+	        this.oscEnabled = source["oscEnabled"];
+	        this.oscPort = source["oscPort"];
+	        this.oscFeedbackTargets = source["oscFeedbackTargets"];
+	        this.mqttEnabled = source["mqttEnabled"];
+	        this.mqttBroker = source["mqttBroker"];
+	        this.mqttClientId = source["mqttClientId"];
+	        this.mqttUsername = source["mqttUsername"];
+	        this.mqttPassword = source["mqttPassword"];
+	        this.mqttTopicPrefix = source["mqttTopicPrefix"];
+	        this.mqttStatsInterval = source["mqttStatsInterval"];
+	        this.webhooks = this.convertValues(source["webhooks"], WebhookTarget);
+	        this.webhookMaxRetries = source["webhookMaxRetries"];
+	        this.restartMaxAttempts = source["restartMaxAttempts"];
+	        this.restartInitialDelay = source["restartInitialDelay"];
+	        this.restartMaxDelay = source["restartMaxDelay"];
+	        this.restartResetWindow = source["restartResetWindow"];
+	        this.startTimeout = source["startTimeout"];
+	        this.stopTimeout = source["stopTimeout"];
+	        this.stopGracePeriod = source["stopGracePeriod"];
+	        this.srtPortRanges = source["srtPortRanges"];
+	        this.wsChannelManagement = source["wsChannelManagement"];
+	        this.wsAdminToken = source["wsAdminToken"];
+	        this.clientChannelGracePeriod = source["clientChannelGracePeriod"];
+	        this.version = source["version"];
+	        this.activePreset = source["activePreset"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+	export class Change {
+	    field: string;
+	    old: any;
+	    new: any;
+	    apply: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Change(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	        this.apply = source["apply"];
+	    }
+	}
+	export class FieldError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+
+}
+
+export namespace preset {
+	
+	export class Summary {
+	    name: string;
+	    description?: string;
+	    // Go type: time
+	    createdAt: any;
+	    channels: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.channels = source["channels"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+export namespace restart {
+	
+	export class Attempt {
+	    // Go type: time
+	    time: any;
+	    attempt: number;
+	    delayMs: number;
+	    reason?: string;
+	    result: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Attempt(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.attempt = source["attempt"];
+	        this.delayMs = source["delayMs"];
+	        this.reason = source["reason"];
+	        this.result = source["result"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class State {
+	    count: number;
+	    attempts: number;
+	    maxAttempts: number;
+	    circuitOpen: boolean;
+	    // Go type: time
+	    lastFailure?: any;
+	    // Go type: time
+	    nextAttempt?: any;
+	    history?: Attempt[];
+	
+	    static createFrom(source: any = {}) {
+	        return new State(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.count = source["count"];
+	        this.attempts = source["attempts"];
+	        this.maxAttempts = source["maxAttempts"];
+	        this.circuitOpen = source["circuitOpen"];
+	        this.lastFailure = this.convertValues(source["lastFailure"], null);
+	        this.nextAttempt = this.convertValues(source["nextAttempt"], null);
+	        this.history = this.convertValues(source["history"], Attempt);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -182,12 +824,14 @@ export namespace websocket {
 	export class ClientInfo {
 	    id: string;
 	    name: string;
+	    key: string;
 	    // Go type: time
 	    connectedAt: any;
 	    // Go type: time
 	    lastMessageAt: any;
 	    messageCount: number;
 	    remoteAddr: string;
+	    transport: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ClientInfo(source);
@@ -197,10 +841,12 @@ export namespace websocket {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.name = source["name"];
+	        this.key = source["key"];
 	        this.connectedAt = this.convertValues(source["connectedAt"], null);
 	        this.lastMessageAt = this.convertValues(source["lastMessageAt"], null);
 	        this.messageCount = source["messageCount"];
 	        this.remoteAddr = source["remoteAddr"];
+	        this.transport = source["transport"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
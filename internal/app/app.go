@@ -10,13 +10,21 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"servidor-stream/internal/audit"
 	"servidor-stream/internal/channel"
 	"servidor-stream/internal/config"
 	"servidor-stream/internal/ffmpeg"
+	"servidor-stream/internal/logfile"
+	"servidor-stream/internal/mqttbridge"
+	"servidor-stream/internal/osc"
+	"servidor-stream/internal/preset"
+	"servidor-stream/internal/restart"
+	"servidor-stream/internal/webhook"
 	"servidor-stream/internal/websocket"
 )
 
@@ -25,45 +33,100 @@ type App struct {
 	ctx            context.Context
 	channelManager *channel.Manager
 	wsServer       *websocket.Server
+	oscServer      *osc.Server
+	mqttBridge     *mqttbridge.Bridge
+	webhooks       *webhook.Dispatcher
+	restarts       *restart.Tracker
+	presets        *preset.Store
+	presetMutex    sync.Mutex // Serializa las activaciones de presets
+	audit          *audit.Log // Registro de auditoría (nil = no disponible)
 	ffmpegManager  *ffmpeg.Manager
-	config         *config.Config
-	logBuffer      []LogEntry
+	config         atomic.Pointer[config.Config] // Configuración vigente (ver cfg)
+	configMutex    sync.Mutex                    // Serializa los cambios de configuración
+	configStamp    fileStamp                     // Última versión conocida de config.json
+	logBuffer      *logRing
+	logSubscribers map[string]LogQuery // Clientes suscritos al log (subscribe_logs)
+	logThresholds  map[string]int      // Nivel mínimo de cada subsistema (índice en logLevels)
 	logMutex       sync.RWMutex
+	logFile        *logfile.Writer // Log en disco (nil = deshabilitado)
+	logFileFormat  string
+	logFileMutex   sync.Mutex
 	cancelFunc     context.CancelFunc
+	startedAt      time.Time
+	ffmpegHealth   ffmpegHealth
+	launches       launchTracker // Lanzamientos de FFmpeg en curso (ver launchFailed)
 }
 
 // LogEntry representa una entrada de log
 type LogEntry struct {
-	Timestamp string `json:"timestamp"`
-	Level     string `json:"level"`
-	Message   string `json:"message"`
-	ChannelID string `json:"channelId,omitempty"`
+	Seq          uint64 `json:"seq"` // Número de secuencia creciente (para afterSeq)
+	Timestamp    string `json:"timestamp"`
+	Level        string `json:"level"`
+	Subsystem    string `json:"subsystem"` // app, websocket, ffmpeg o channel
+	Message      string `json:"message"`
+	ChannelID    string `json:"channelId,omitempty"`
+	ChannelLabel string `json:"channelLabel,omitempty"`
+	ClientID     string `json:"clientId,omitempty"` // Cliente WebSocket que originó la entrada
+
+	at time.Time // Momento exacto (filtros since/until)
 }
 
 // NewApp crea una nueva instancia de la aplicación
 func NewApp() *App {
 	return &App{
-		logBuffer: make([]LogEntry, 0, 1000),
+		logBuffer:      newLogRing(defaultLogLines),
+		logSubscribers: make(map[string]LogQuery),
 	}
 }
 
 // Startup es llamado cuando la aplicación inicia
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
+	a.startedAt = time.Now()
 	cancelCtx, cancel := context.WithCancel(ctx)
 	a.cancelFunc = cancel
 
 	// Cargar configuración
-	cfg, err := config.Load()
+	cfg, err := config.Load() // Siempre retorna una configuración utilizable
+	a.config.Store(cfg)
+	a.applyLogLevels()
+	a.trimLogs()
+	a.openLogFile()
 	if err != nil {
 		a.AddLog("ERROR", fmt.Sprintf("Error cargando configuración: %v", err), "")
-		cfg = config.Default()
 	}
-	a.config = cfg
+	if err := cfg.Validate(); err != nil {
+		a.AddLog("WARNING", err.Error(), "")
+	}
+	for field, source := range config.Overridden() {
+		a.AddLog("INFO", fmt.Sprintf("Configuración: %s impuesto por %s", field, source), "")
+	}
+	if name := config.Instance(); name != "" {
+		a.AddLog("INFO", fmt.Sprintf("Instancia '%s', datos en %s", name, config.DataDir()), "")
+	} else {
+		a.AddLog("INFO", fmt.Sprintf("Directorio de datos: %s", config.DataDir()), "")
+	}
 
 	// Inicializar managers
 	a.channelManager = channel.NewManager()
+	if err := a.channelManager.LoadError(); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error cargando canales: %v", err), "")
+	}
+	a.channelManager.SetTransitionHandler(a.onChannelTransition)
+	a.channelManager.SetPersistErrorHandler(func(err error) {
+		a.AddLog("ERROR", fmt.Sprintf("Error guardando canales: %v", err), "")
+	})
+	a.channelManager.SetPortRanges(a.portRanges())
 	a.ffmpegManager = ffmpeg.NewManager(cfg.FFmpegPath, a.onFFmpegEvent)
+	a.ffmpegManager.SetStopGrace(a.stopGrace())
+	a.ffmpegManager.SetStderrLog(a.openFFmpegLog)
+	a.restarts = restart.NewTracker(a.restartPolicy())
+	a.presets = preset.NewStore(config.GetPresetsDir())
+	a.openAudit()
+
+	// Inicializar webhooks salientes
+	a.webhooks = webhook.NewDispatcher(a.webhookTargets(), cfg.WebhookMaxRetries)
+	a.webhooks.Start(cancelCtx)
 
 	// Inicializar servidor WebSocket
 	a.wsServer = websocket.NewServer(cfg.WebSocketPort, a.handleWebSocketMessage)
@@ -71,20 +134,47 @@ func (a *App) Startup(ctx context.Context) {
 	// Configurar callbacks para eventos de clientes
 	a.wsServer.SetClientCallbacks(
 		func(client websocket.ClientInfo) {
-			a.AddLog("INFO", fmt.Sprintf("Cliente conectado: %s (%s)", client.Name, client.RemoteAddr), "")
+			a.addClientLog("INFO", fmt.Sprintf("Cliente conectado: %s (%s)", client.Name, client.RemoteAddr), "", client.ID)
 			runtime.EventsEmit(a.ctx, "client:connected", client)
+			a.publishMQTTClients()
+			a.claimClientChannels(client)
 		},
-		func(clientID string) {
-			a.AddLog("INFO", fmt.Sprintf("Cliente desconectado: %s", clientID), "")
-			runtime.EventsEmit(a.ctx, "client:disconnected", clientID)
+		func(client websocket.ClientInfo) {
+			a.addClientLog("INFO", fmt.Sprintf("Cliente desconectado: %s", client.ID), "", client.ID)
+			runtime.EventsEmit(a.ctx, "client:disconnected", client.ID)
+			a.publishMQTTClients()
+			a.releaseClientChannels(client)
+			a.unsubscribeLogs(client.ID)
 		},
 	)
 
+	// Endpoints de métricas Prometheus y health checks
+	a.wsServer.Handle("/metrics", a.handleMetrics)
+	a.wsServer.Handle("/health/live", a.handleHealthLive)
+	a.wsServer.Handle("/health/ready", a.handleHealthReady)
+
+	// Consulta de logs
+	a.wsServer.Handle("/api/logs", a.handleLogsAPI)
+	a.wsServer.Handle("/api/audit", a.handleAuditAPI)
+
 	go a.wsServer.Start(cancelCtx)
 
+	// Inicializar listener OSC (opcional)
+	if cfg.OSCEnabled {
+		a.startOSC(cancelCtx)
+	}
+
+	// Inicializar bridge MQTT (opcional)
+	if cfg.MQTTEnabled {
+		a.startMQTT(cancelCtx)
+	}
+
 	// Iniciar monitor de canales
 	go a.monitorChannels(cancelCtx)
 
+	// Recargar config.json si se edita fuera de la aplicación
+	go a.watchConfigFile(cancelCtx)
+
 	a.AddLog("INFO", fmt.Sprintf("SRT Server Stream iniciado en puerto WebSocket %d", cfg.WebSocketPort), "")
 }
 
@@ -97,9 +187,14 @@ func (a *App) Shutdown(ctx context.Context) {
 		a.cancelFunc()
 	}
 
-	// Detener todos los streams
+	// Detener todos los streams en paralelo, con un límite global: lo que no
+	// termine ordenadamente antes del deadline se mata
 	if a.ffmpegManager != nil {
-		a.ffmpegManager.StopAll()
+		stopCtx, cancel := context.WithTimeout(context.Background(), a.stopGrace()+2*time.Second)
+		if err := a.ffmpegManager.StopAll(stopCtx); err != nil {
+			log.Printf("Deadline de cierre alcanzado deteniendo FFmpeg: %v", err)
+		}
+		cancel()
 	}
 
 	// Detener servidor WebSocket
@@ -107,12 +202,28 @@ func (a *App) Shutdown(ctx context.Context) {
 		a.wsServer.Stop()
 	}
 
+	// Detener listener OSC
+	if a.oscServer != nil {
+		a.oscServer.Stop()
+	}
+
+	// Desconectar bridge MQTT
+	if a.mqttBridge != nil {
+		a.mqttBridge.Stop()
+	}
+
 	// Guardar configuración
-	if a.config != nil {
-		config.Save(a.config)
+	if cfg := a.cfg(); cfg != nil {
+		if err := config.Save(cfg); err != nil {
+			a.AddLog("ERROR", fmt.Sprintf("Error guardando configuración: %v", err), "")
+		}
 	}
 
 	a.AddLog("INFO", "SRT Server Stream cerrado correctamente", "")
+	a.closeLogFile()
+	if a.audit != nil {
+		a.audit.Close()
+	}
 }
 
 // DomReady es llamado cuando el DOM está listo
@@ -133,6 +244,18 @@ func (a *App) GetChannels() []channel.Channel {
 
 // AddChannel agrega un nuevo canal (sin videoPath - Aximmetry lo envía vía WebSocket)
 func (a *App) AddChannel(label, srtStreamName string) (*channel.Channel, error) {
+	ch, err := a.addChannel(label, srtStreamName)
+	a.auditUIChannel("AddChannel", ch, map[string]string{"label": label, "srtStreamName": srtStreamName}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	a.notifyChannelAdded(ch, "")
+	return ch, nil
+}
+
+// addChannel crea el canal sin notificar
+func (a *App) addChannel(label, srtStreamName string) (*channel.Channel, error) {
 	ch, err := a.channelManager.Add(label, "", srtStreamName) // videoPath vacío inicialmente
 	if err != nil {
 		a.AddLog("ERROR", fmt.Sprintf("Error agregando canal: %v", err), "")
@@ -140,15 +263,33 @@ func (a *App) AddChannel(label, srtStreamName string) (*channel.Channel, error)
 	}
 
 	a.AddLog("INFO", fmt.Sprintf("Canal agregado: %s (%s)", ch.Label, ch.ID), ch.ID)
-	runtime.EventsEmit(a.ctx, "channel:added", ch)
-
 	return ch, nil
 }
 
 // RemoveChannel elimina un canal
 func (a *App) RemoveChannel(channelID string) error {
+	ch, err := a.channelManager.Get(channelID)
+	if err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error eliminando canal %s: %v", channelID, err), channelID)
+		a.auditUI("RemoveChannel", channelID, nil, err)
+		return err
+	}
+
+	err = a.removeChannel(ch.ID)
+	a.auditUIChannel("RemoveChannel", ch, nil, err)
+	if err != nil {
+		return err
+	}
+
+	a.notifyChannelRemoved(ch, "")
+	return nil
+}
+
+// removeChannel detiene y elimina el canal sin notificar
+func (a *App) removeChannel(channelID string) error {
 	// Detener stream si está activo
 	a.ffmpegManager.Stop(channelID)
+	a.restarts.Remove(channelID)
 
 	// Eliminar canal
 	err := a.channelManager.Remove(channelID)
@@ -158,115 +299,136 @@ func (a *App) RemoveChannel(channelID string) error {
 	}
 
 	a.AddLog("INFO", fmt.Sprintf("Canal eliminado: %s", channelID), channelID)
-	runtime.EventsEmit(a.ctx, "channel:removed", channelID)
+	if a.mqttBridge != nil {
+		a.mqttBridge.RemoveChannel(channelID)
+	}
 
 	return nil
 }
 
 // UpdateChannel actualiza la configuración de un canal (sin videoPath)
 func (a *App) UpdateChannel(channelID, label, srtStreamName string) (*channel.Channel, error) {
+	ch, err := a.updateChannel(channelID, label, srtStreamName)
+	a.auditUI("UpdateChannel", channelID, map[string]string{"label": label, "srtStreamName": srtStreamName}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	a.notifyChannelUpdated(ch, "")
+	return ch, nil
+}
+
+// updateChannel actualiza nombre y stream sin notificar
+func (a *App) updateChannel(channelID, label, srtStreamName string) (*channel.Channel, error) {
 	ch, err := a.channelManager.Update(channelID, label, "", srtStreamName) // videoPath se mantiene
 	if err != nil {
 		a.AddLog("ERROR", fmt.Sprintf("Error actualizando canal %s: %v", channelID, err), channelID)
 		return nil, err
 	}
 
-	a.AddLog("INFO", fmt.Sprintf("Canal actualizado: %s", label), channelID)
-	runtime.EventsEmit(a.ctx, "channel:updated", ch)
+	a.AddLog("INFO", fmt.Sprintf("Canal actualizado: %s", ch.Label), channelID)
+	return ch, nil
+}
+
+// UpdateChannelOutput actualiza resolución, fps, escalado, barrido, formato de
+// pixel y audio de un canal (se aplican en el próximo inicio del stream)
+func (a *App) UpdateChannelOutput(channelID string, output channel.OutputSettings) (*channel.Channel, error) {
+	ch, err := a.updateChannelOutput(channelID, output)
+	a.auditUI("UpdateChannelOutput", channelID, output, err)
+	if err != nil {
+		return nil, err
+	}
+
+	a.notifyChannelUpdated(ch, "")
+	return ch, nil
+}
+
+// updateChannelOutput actualiza los parámetros de salida sin notificar
+func (a *App) updateChannelOutput(channelID string, output channel.OutputSettings) (*channel.Channel, error) {
+	ch, err := a.channelManager.UpdateOutput(channelID, output)
+	if err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error actualizando salida del canal %s: %v", channelID, err), channelID)
+		return nil, err
+	}
 
+	o := ch.Output()
+	a.AddLog("INFO", fmt.Sprintf("Salida actualizada: %s @ %dfps, %s, %s, %s, %d canales de audio",
+		o.Resolution, o.FrameRate, o.ScaleMode, o.Interlace, o.PixelFormat, o.AudioChannels), channelID)
 	return ch, nil
 }
 
-// StartChannel inicia el stream de un canal
+// StartChannel inicia el stream de un canal (acción manual: cierra el circuito de reinicio)
 func (a *App) StartChannel(channelID string) error {
+	err := a.manualStart(channelID)
+	a.auditUI("StartChannel", channelID, nil, err)
+	return err
+}
+
+// manualStart inicia el stream como acción manual sin auditar
+func (a *App) manualStart(channelID string) error {
+	a.resetRestart(channelID)
+	return a.startChannel(channelID)
+}
+
+// startChannel inicia el stream de un canal
+func (a *App) startChannel(channelID string) error {
 	ch, err := a.channelManager.Get(channelID)
 	if err != nil {
 		return err
 	}
 
-	// Usar CurrentFile si VideoPath está vacío (ej: si estaba reproduciendo patrón)
-	inputPath := ch.VideoPath
-	if inputPath == "" && ch.CurrentFile != "" {
-		inputPath = ch.CurrentFile
-	}
-
-	// Parsear resolución del canal
-	width, height := 1920, 1080
-	if ch.Resolution != "" {
-		fmt.Sscanf(ch.Resolution, "%dx%d", &width, &height)
-	}
+	a.launches.begin(channelID)
+	defer a.launches.end(channelID)
 
-	// Usar FPS del canal o el por defecto
-	frameRate := ch.FrameRate
-	if frameRate == 0 {
-		frameRate = a.config.DefaultFrameRate
-	}
+	// Retomar lo que estaba emitiendo, con su modo (el patrón sigue en bucle)
+	inputPath, loop := ch.Source()
 
 	// Configurar y iniciar FFmpeg con SRT
-	ffmpegConfig := ffmpeg.StreamConfig{
-		ChannelID:     ch.ID,
-		InputPath:     inputPath,
-		SRTStreamName: ch.SRTStreamName,
-		SRTPort:       ch.SRTPort,
-		SRTHost:       ch.SRTHost,
-		VideoBitrate:  a.config.DefaultVideoBitrate,
-		AudioBitrate:  a.config.DefaultAudioBitrate,
-		FrameRate:     frameRate,
-		Width:         width,
-		Height:        height,
-		Loop:          false, // Sin loop - reproducir una sola vez
-		// Configuración avanzada
-		VideoEncoder:   a.config.VideoEncoder,
-		EncoderPreset:  a.config.EncoderPreset,
-		EncoderProfile: a.config.EncoderProfile,
-		EncoderTune:    a.config.EncoderTune,
-		GopSize:        a.config.GopSize,
-		BFrames:        a.config.BFrames,
-		BitrateMode:    a.config.BitrateMode,
-		MaxBitrate:     a.config.MaxBitrate,
-		BufferSize:     a.config.BufferSize,
-		SRTLatency:     a.config.SRTLatency,
-		SRTRecvBuffer:  a.config.SRTRecvBuffer,
-		SRTSendBuffer:  a.config.SRTSendBuffer,
-		SRTOverheadBW:  a.config.SRTOverheadBW,
+	ffmpegConfig := a.streamConfig(ch, inputPath, loop)
+
+	// "active" llega con EventReady (primeros frames o listener SRT listo)
+	if err := a.channelManager.Transition(channelID, channel.StatusStarting, "iniciando stream"); err != nil {
+		return err
 	}
 
 	err = a.ffmpegManager.StartWithFallback(ffmpegConfig)
 	if err != nil {
-		a.channelManager.SetStatus(channelID, channel.StatusError)
+		a.channelManager.Transition(channelID, channel.StatusError, err.Error())
 		a.AddLog("ERROR", fmt.Sprintf("Error iniciando stream %s: %v", ch.Label, err), channelID)
 		return err
 	}
 
-	a.channelManager.SetStatus(channelID, channel.StatusActive)
 	a.AddLog("INFO", fmt.Sprintf("Stream SRT iniciado: %s -> srt://%s:%d", ch.Label, ch.SRTHost, ch.SRTPort), channelID)
-	runtime.EventsEmit(a.ctx, "channel:status", map[string]interface{}{
-		"channelId": channelID,
-		"status":    channel.StatusActive,
-		"srtPort":   ch.SRTPort,
-	})
 
 	return nil
 }
 
 // StopAllStreams detiene todos los streams FFmpeg de forma forzada sin reinicio
 func (a *App) StopAllStreams() error {
+	err := a.stopAllStreams()
+	a.auditUI("StopAllStreams", "", nil, err)
+	return err
+}
+
+// stopAllStreams detiene todos los streams sin auditar
+func (a *App) stopAllStreams() error {
 	a.AddLog("INFO", "Deteniendo todos los streams de forma forzada...", "")
 
 	// Obtener todos los canales
 	channels := a.channelManager.GetAll()
 
-	// Detener cada proceso FFmpeg
-	a.ffmpegManager.StopAll()
+	// Detener cada proceso FFmpeg (cancelando reinicios pendientes)
+	for _, ch := range channels {
+		a.resetRestart(ch.ID)
+		if ch.Status != channel.StatusInactive {
+			a.channelManager.Transition(ch.ID, channel.StatusStopping, "detención forzada")
+		}
+	}
+	a.ffmpegManager.StopAll(context.Background())
 
 	// Actualizar el estado de todos los canales a inactivo
 	for _, ch := range channels {
-		a.channelManager.SetStatus(ch.ID, channel.StatusInactive)
-		runtime.EventsEmit(a.ctx, "channel:status", map[string]interface{}{
-			"channelId": ch.ID,
-			"status":    channel.StatusInactive,
-			"event":     "force_stopped",
-		})
+		a.channelManager.Transition(ch.ID, channel.StatusInactive, "detención forzada")
 	}
 
 	a.AddLog("INFO", fmt.Sprintf("Se detuvieron %d streams de forma forzada", len(channels)), "")
@@ -276,20 +438,28 @@ func (a *App) StopAllStreams() error {
 
 // PlayTestPattern reproduce el patrón de prueba en un canal
 func (a *App) PlayTestPattern(channelID string) error {
+	err := a.playTestPattern(channelID)
+	a.auditUI("PlayTestPattern", channelID, nil, err)
+	return err
+}
+
+// playTestPattern reproduce el patrón de prueba sin auditar
+func (a *App) playTestPattern(channelID string) error {
+	cfg := a.cfg()
 	a.AddLog("INFO", fmt.Sprintf("PlayTestPattern llamado para canal: %s", channelID), channelID)
 
 	// Verificar que el patrón está configurado
-	if a.config.TestPatternPath == "" {
+	if cfg.TestPatternPath == "" {
 		a.AddLog("ERROR", "Patrón de prueba no configurado", channelID)
 		return fmt.Errorf("patrón de prueba no configurado. Configure la ruta en Ajustes")
 	}
 
-	a.AddLog("INFO", fmt.Sprintf("Patrón configurado: %s", a.config.TestPatternPath), channelID)
+	a.AddLog("INFO", fmt.Sprintf("Patrón configurado: %s", cfg.TestPatternPath), channelID)
 
 	// Verificar que el archivo existe
-	if _, err := os.Stat(a.config.TestPatternPath); os.IsNotExist(err) {
-		a.AddLog("ERROR", fmt.Sprintf("Archivo no encontrado: %s", a.config.TestPatternPath), channelID)
-		return fmt.Errorf("archivo de patrón no encontrado: %s", a.config.TestPatternPath)
+	if _, err := os.Stat(cfg.TestPatternPath); os.IsNotExist(err) {
+		a.AddLog("ERROR", fmt.Sprintf("Archivo no encontrado: %s", cfg.TestPatternPath), channelID)
+		return fmt.Errorf("archivo de patrón no encontrado: %s", cfg.TestPatternPath)
 	}
 
 	ch, err := a.channelManager.Get(channelID)
@@ -299,75 +469,37 @@ func (a *App) PlayTestPattern(channelID string) error {
 	}
 
 	a.AddLog("INFO", fmt.Sprintf("Canal encontrado: %s, puerto SRT: %d", ch.Label, ch.SRTPort), channelID)
+	a.resetRestart(channelID)
 
-	// Si el canal está activo, detenerlo primero
-	if ch.Status == channel.StatusActive {
-		a.ffmpegManager.Stop(channelID)
-	}
+	a.launches.begin(channelID)
+	defer a.launches.end(channelID)
 
-	// Actualizar el archivo actual a patrón
-	a.channelManager.SetCurrentFile(channelID, a.config.TestPatternPath)
-
-	// Parsear resolución del canal
-	width, height := 1920, 1080 // Valores por defecto
-	if ch.Resolution != "" {
-		fmt.Sscanf(ch.Resolution, "%dx%d", &width, &height)
+	// Si el canal está activo, el manager reemplaza el proceso de forma ordenada
+	if err := a.channelManager.Transition(channelID, channel.StatusStarting, "iniciando patrón de prueba"); err != nil {
+		return err
 	}
 
-	// Usar FPS del canal o el por defecto
-	frameRate := ch.FrameRate
-	if frameRate == 0 {
-		frameRate = a.config.DefaultFrameRate
-	}
+	// Actualizar el archivo actual a patrón
+	a.channelManager.SetCurrentFile(channelID, cfg.TestPatternPath, true)
 
-	// Configurar y iniciar FFmpeg con el patrón
-	ffmpegConfig := ffmpeg.StreamConfig{
-		ChannelID:     ch.ID,
-		InputPath:     a.config.TestPatternPath,
-		SRTStreamName: ch.SRTStreamName,
-		SRTPort:       ch.SRTPort,
-		SRTHost:       ch.SRTHost,
-		VideoBitrate:  a.config.DefaultVideoBitrate,
-		AudioBitrate:  a.config.DefaultAudioBitrate,
-		FrameRate:     frameRate,
-		Width:         width,
-		Height:        height,
-		Loop:          true, // El patrón siempre en loop
-		// Configuración avanzada de encoding
-		VideoEncoder:   a.config.VideoEncoder,
-		EncoderPreset:  a.config.EncoderPreset,
-		EncoderProfile: a.config.EncoderProfile,
-		EncoderTune:    a.config.EncoderTune,
-		GopSize:        a.config.GopSize,
-		BFrames:        a.config.BFrames,
-		// Control de bitrate
-		BitrateMode: a.config.BitrateMode,
-		MaxBitrate:  a.config.MaxBitrate,
-		BufferSize:  a.config.BufferSize,
-		// SRT avanzado
-		SRTLatency:    a.config.SRTLatency,
-		SRTRecvBuffer: a.config.SRTRecvBuffer,
-		SRTSendBuffer: a.config.SRTSendBuffer,
-		SRTOverheadBW: a.config.SRTOverheadBW,
-	}
+	// Configurar y iniciar FFmpeg con el patrón (siempre en loop)
+	ffmpegConfig := a.streamConfig(ch, cfg.TestPatternPath, true)
 
-	a.AddLog("INFO", fmt.Sprintf("Iniciando FFmpeg: %dx%d @ %dfps en %s:%d (encoder: %s)", width, height, frameRate, ch.SRTHost, ch.SRTPort, a.config.VideoEncoder), channelID)
+	a.AddLog("INFO", fmt.Sprintf("Iniciando FFmpeg: %dx%d @ %dfps en %s:%d (encoder: %s)", ffmpegConfig.Width, ffmpegConfig.Height, ffmpegConfig.FrameRate, ch.SRTHost, ch.SRTPort, cfg.VideoEncoder), channelID)
 
 	err = a.ffmpegManager.StartWithFallback(ffmpegConfig)
 	if err != nil {
-		a.channelManager.SetStatus(channelID, channel.StatusError)
+		a.channelManager.Transition(channelID, channel.StatusError, err.Error())
 		a.AddLog("ERROR", fmt.Sprintf("Error iniciando patrón de prueba: %v", err), channelID)
 		return err
 	}
 
-	a.channelManager.SetStatus(channelID, channel.StatusActive)
 	a.AddLog("INFO", fmt.Sprintf("Patrón de prueba iniciado en %s (SRT puerto %d)", ch.Label, ch.SRTPort), channelID)
 
-	// Emitir evento de status ACTIVO al frontend
-	a.AddLog("INFO", fmt.Sprintf("Emitiendo channel:status con status=active para canal %s", channelID), channelID)
-	runtime.EventsEmit(a.ctx, "channel:status", map[string]interface{}{
+	// Informar el archivo al frontend (el estado pasa a "active" con EventReady)
+	a.emitChannelStatus(map[string]interface{}{
 		"channelId":     channelID,
-		"status":        "active", // Usar string directo para asegurar compatibilidad
+		"status":        channel.StatusStarting,
 		"currentFile":   "[PATRÓN DE PRUEBA]",
 		"srtPort":       ch.SRTPort,
 		"isTestPattern": true,
@@ -393,6 +525,7 @@ func (a *App) SelectTestPatternPath() (string, error) {
 // SetChannelSRTHost establece la IP/Host SRT de un canal
 func (a *App) SetChannelSRTHost(channelID, host string) error {
 	err := a.channelManager.SetSRTHost(channelID, host)
+	a.auditUI("SetChannelSRTHost", channelID, map[string]string{"host": host}, err)
 	if err != nil {
 		return err
 	}
@@ -404,38 +537,56 @@ func (a *App) SetChannelSRTHost(channelID, host string) error {
 
 // StopChannel detiene el stream de un canal
 func (a *App) StopChannel(channelID string) error {
+	err := a.stopChannel(channelID)
+	a.auditUI("StopChannel", channelID, nil, err)
+	return err
+}
+
+// stopChannel detiene el stream de un canal sin auditar
+func (a *App) stopChannel(channelID string) error {
 	ch, err := a.channelManager.Get(channelID)
 	if err != nil {
 		return err
 	}
 
+	// Cancelar cualquier reinicio automático pendiente
+	a.resetRestart(channelID)
+
+	if ch.Status != channel.StatusInactive {
+		a.channelManager.Transition(channelID, channel.StatusStopping, "detención solicitada")
+	}
+
 	err = a.ffmpegManager.Stop(channelID)
 	if err != nil {
+		a.channelManager.Transition(channelID, channel.StatusError, err.Error())
 		a.AddLog("ERROR", fmt.Sprintf("Error deteniendo stream %s: %v", ch.Label, err), channelID)
 		return err
 	}
 
-	a.channelManager.SetStatus(channelID, channel.StatusInactive)
+	a.channelManager.Transition(channelID, channel.StatusInactive, "detenido")
 	a.AddLog("INFO", fmt.Sprintf("Stream detenido: %s", ch.Label), channelID)
-	runtime.EventsEmit(a.ctx, "channel:status", map[string]interface{}{
-		"channelId": channelID,
-		"status":    channel.StatusInactive,
-	})
 
 	return nil
 }
 
 // ToggleChannel activa o desactiva un canal
 func (a *App) ToggleChannel(channelID string) error {
+	err := a.toggleChannel(channelID)
+	a.auditUI("ToggleChannel", channelID, nil, err)
+	return err
+}
+
+// toggleChannel activa o desactiva un canal sin auditar
+func (a *App) toggleChannel(channelID string) error {
 	ch, err := a.channelManager.Get(channelID)
 	if err != nil {
 		return err
 	}
 
-	if ch.Status == channel.StatusActive {
-		return a.StopChannel(channelID)
+	if ch.Status == channel.StatusActive || ch.Status == channel.StatusStarting {
+		return a.stopChannel(channelID)
 	}
-	return a.StartChannel(channelID)
+	return a.manualStart(channelID)
 }
 
 // GetLogs retorna los logs recientes
@@ -443,35 +594,27 @@ func (a *App) GetLogs() []LogEntry {
 	a.logMutex.RLock()
 	defer a.logMutex.RUnlock()
 
-	logs := make([]LogEntry, len(a.logBuffer))
-	copy(logs, a.logBuffer)
-	return logs
+	return a.logBuffer.snapshot()
 }
 
 // ClearLogs limpia los logs
 func (a *App) ClearLogs() {
 	a.logMutex.Lock()
-	defer a.logMutex.Unlock()
+	a.logBuffer.clear()
+	a.logMutex.Unlock()
 
-	a.logBuffer = make([]LogEntry, 0, 1000)
+	a.auditUI("ClearLogs", "", nil, nil)
 }
 
 // GetConfig retorna la configuración actual
 func (a *App) GetConfig() *config.Config {
-	return a.config
+	return a.cfg()
 }
 
-// UpdateConfig actualiza la configuración
-func (a *App) UpdateConfig(cfg *config.Config) error {
-	a.config = cfg
-	err := config.Save(cfg)
-	if err != nil {
-		a.AddLog("ERROR", fmt.Sprintf("Error guardando configuración: %v", err), "")
-		return err
-	}
-
-	a.AddLog("INFO", "Configuración actualizada", "")
-	return nil
+// cfg retorna la configuración vigente. Se puede leer sin bloqueo desde
+// cualquier goroutine: nunca se modifica, applyConfig publica una nueva
+func (a *App) cfg() *config.Config {
+	return a.config.Load()
 }
 
 // GetConnectedClients retorna los clientes WebSocket conectados
@@ -531,6 +674,13 @@ func (a *App) GetVideoFiles(dirPath string) ([]string, error) {
 
 // PlayVideoOnChannel reproduce un video específico en un canal
 func (a *App) PlayVideoOnChannel(channelID, videoPath string) error {
+	err := a.playVideoOnChannel(channelID, videoPath)
+	a.auditUI("PlayVideoOnChannel", channelID, map[string]string{"videoPath": videoPath}, err)
+	return err
+}
+
+// playVideoOnChannel reproduce un video en un canal sin auditar
+func (a *App) playVideoOnChannel(channelID, videoPath string) error {
 	a.AddLog("DEBUG", fmt.Sprintf("→ PlayVideoOnChannel: channelID=%s, videoPath=%s", channelID, videoPath), channelID)
 
 	ch, err := a.channelManager.Get(channelID)
@@ -545,61 +695,41 @@ func (a *App) PlayVideoOnChannel(channelID, videoPath string) error {
 		return fmt.Errorf("archivo no encontrado: %s", videoPath)
 	}
 	a.AddLog("DEBUG", fmt.Sprintf("✓ Archivo verificado: %s", videoPath), channelID)
+	a.resetRestart(channelID)
 
-	// Si el canal está activo, detenerlo de forma ultra rápida
-	if ch.Status == channel.StatusActive {
-		a.AddLog("DEBUG", "→ Canal activo, cambiando video rápidamente...", channelID)
-		// Stop ahora es ultra rápido (~50ms)
-		a.ffmpegManager.Stop(channelID)
-		// Pequeña espera adicional solo si es necesario (Windows puede necesitar liberar el socket)
-		time.Sleep(50 * time.Millisecond)
+	if ch.Status == channel.StatusActive || ch.Status == channel.StatusStarting {
+		a.AddLog("DEBUG", "→ Canal activo, cambiando video...", channelID)
 	}
 
-	// Actualizar la ruta del video
-	a.channelManager.SetCurrentFile(channelID, videoPath)
+	a.launches.begin(channelID)
+	defer a.launches.end(channelID)
 
-	// Parsear resolución del canal
-	width, height := 1920, 1080 // Valores por defecto
-	if ch.Resolution != "" {
-		fmt.Sscanf(ch.Resolution, "%dx%d", &width, &height)
+	// El manager serializa las peticiones del canal y reemplaza el proceso
+	// actual de forma ordenada antes de lanzar el nuevo
+	if err := a.channelManager.Transition(channelID, channel.StatusStarting, "reproduciendo "+filepath.Base(videoPath)); err != nil {
+		return err
 	}
 
-	// Usar FPS del canal o el por defecto
-	frameRate := ch.FrameRate
-	if frameRate == 0 {
-		frameRate = a.config.DefaultFrameRate
-	}
+	// Actualizar la ruta del video
+	a.channelManager.SetCurrentFile(channelID, videoPath, false)
 
-	// Iniciar con el nuevo video (SRT)
-	ffmpegConfig := ffmpeg.StreamConfig{
-		ChannelID:     ch.ID,
-		InputPath:     videoPath,
-		SRTStreamName: ch.SRTStreamName,
-		SRTPort:       ch.SRTPort,
-		SRTHost:       ch.SRTHost,
-		VideoBitrate:  a.config.DefaultVideoBitrate,
-		AudioBitrate:  a.config.DefaultAudioBitrate,
-		FrameRate:     frameRate,
-		Width:         width,
-		Height:        height,
-		Loop:          false, // Sin loop - reproducir una sola vez
-	}
+	// Iniciar con el nuevo video (SRT, sin loop - reproducir una sola vez)
+	ffmpegConfig := a.streamConfig(ch, videoPath, false)
 
-	a.AddLog("INFO", fmt.Sprintf("Iniciando FFmpeg: %dx%d @ %dfps en %s:%d", width, height, frameRate, ch.SRTHost, ch.SRTPort), channelID)
+	a.AddLog("INFO", fmt.Sprintf("Iniciando FFmpeg: %dx%d @ %dfps en %s:%d", ffmpegConfig.Width, ffmpegConfig.Height, ffmpegConfig.FrameRate, ch.SRTHost, ch.SRTPort), channelID)
 
 	err = a.ffmpegManager.StartWithFallback(ffmpegConfig)
 	if err != nil {
-		a.channelManager.SetStatus(channelID, channel.StatusError)
+		a.channelManager.Transition(channelID, channel.StatusError, err.Error())
 		a.AddLog("ERROR", fmt.Sprintf("Error reproduciendo video: %v", err), channelID)
 		return err
 	}
 
-	a.channelManager.SetStatus(channelID, channel.StatusActive)
 	a.AddLog("INFO", fmt.Sprintf("Reproduciendo: %s en canal %s (SRT puerto %d)", filepath.Base(videoPath), ch.Label, ch.SRTPort), channelID)
 
-	runtime.EventsEmit(a.ctx, "channel:status", map[string]interface{}{
+	a.emitChannelStatus(map[string]interface{}{
 		"channelId":   channelID,
-		"status":      channel.StatusActive,
+		"status":      channel.StatusStarting,
 		"currentFile": videoPath,
 		"srtPort":     ch.SRTPort,
 	})
@@ -609,58 +739,101 @@ func (a *App) PlayVideoOnChannel(channelID, videoPath string) error {
 
 // ==================== Métodos internos ====================
 
-// AddLog agrega una entrada al log
+// AddLog agrega una entrada al log (subsistema channel si indica un canal, si no app)
 func (a *App) AddLog(level, message, channelID string) {
+	subsystem := subsystemApp
+	if channelID != "" {
+		subsystem = subsystemChannel
+	}
+	a.addLog(subsystem, level, message, channelID, "")
+}
+
+// logTo agrega una entrada al log de un subsistema
+func (a *App) logTo(subsystem, level, message, channelID string) {
+	a.addLog(subsystem, level, message, channelID, "")
+}
+
+// addClientLog agrega una entrada originada por un cliente WebSocket
+func (a *App) addClientLog(level, message, channelID, clientID string) {
+	a.addLog(subsystemWebSocket, level, message, channelID, clientID)
+}
+
+// addLog registra la entrada si alcanza el nivel mínimo del subsistema
+func (a *App) addLog(subsystem, level, message, channelID, clientID string) {
+	if !a.logEnabled(subsystem, level) {
+		return
+	}
+
 	entry := LogEntry{
-		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
 		Level:     level,
+		Subsystem: subsystem,
 		Message:   message,
 		ChannelID: channelID,
+		ClientID:  clientID,
+	}
+	if channelID != "" && a.channelManager != nil {
+		if ch, err := a.channelManager.Get(channelID); err == nil {
+			entry.ChannelLabel = ch.Label
+		}
 	}
 
+	// Buffer circular con máximo configurable (maxLogLines): al llenarse, la
+	// entrada nueva reemplaza a la más antigua. La hora se toma con el lock para
+	// que el buffer quede en orden de secuencia y de tiempo.
 	a.logMutex.Lock()
-	// Pool de logs con máximo configurable (default 1000)
-	// Cuando se excede el límite, se elimina el log más antiguo (índice 0) para optimizar memoria
-	maxLogs := 1000
-	if a.config != nil && a.config.MaxLogLines > 0 {
-		maxLogs = a.config.MaxLogLines
-	}
-	if len(a.logBuffer) >= maxLogs {
-		// Eliminar el primer elemento (índice 0) desplazando el slice
-		a.logBuffer = a.logBuffer[1:]
-	}
-	a.logBuffer = append(a.logBuffer, entry)
+	now := time.Now()
+	entry.Timestamp = now.Format("2006-01-02 15:04:05")
+	entry.at = now
+	entry = a.logBuffer.push(entry)
+	recipients := a.logRecipients(entry)
 	a.logMutex.Unlock()
 
-	// Emitir evento al frontend
+	// Emitir evento al frontend y a los clientes suscritos
 	runtime.EventsEmit(a.ctx, "log:new", entry)
+	a.pushLog(entry, recipients)
 
-	// Log a consola también
+	// Log a consola y a disco
 	log.Printf("[%s] %s", level, message)
+	a.writeLogFile(entry, now)
 }
 
 // handleWebSocketMessage maneja mensajes WebSocket de clientes Aximmetry
 func (a *App) handleWebSocketMessage(clientID string, message []byte) []byte {
-	// Log del mensaje raw para debug
-	a.AddLog("DEBUG", fmt.Sprintf("WebSocket raw message: %s", string(message)), "")
+	// Log del mensaje raw (solo se formatea si el nivel trace está activo)
+	if a.logEnabled(subsystemWebSocket, "TRACE") {
+		a.logTo(subsystemWebSocket, "TRACE", fmt.Sprintf("WebSocket raw message: %s", string(message)), "")
+	}
 
-	// Manejar formato Socket.IO (prefijos numéricos como "42")
+	// Tolerar prefijos numéricos estilo Socket.IO (ej: "42") enviados por /ws.
+	// Los clientes Socket.IO reales usan el endpoint nativo /socket.io/
 	msgStr := string(message)
 	jsonStart := strings.Index(msgStr, "{")
 	if jsonStart > 0 {
 		// Hay un prefijo antes del JSON, eliminarlo
-		a.AddLog("DEBUG", fmt.Sprintf("Detectado prefijo Socket.IO: %s", msgStr[:jsonStart]), "")
+		a.logTo(subsystemWebSocket, "TRACE", fmt.Sprintf("Detectado prefijo Socket.IO: %s", msgStr[:jsonStart]), "")
 		message = []byte(msgStr[jsonStart:])
 	}
 
 	var msg websocket.Message
 	if err := json.Unmarshal(message, &msg); err != nil {
-		a.AddLog("ERROR", fmt.Sprintf("Error parseando mensaje WebSocket: %v", err), "")
+		a.addClientLog("ERROR", fmt.Sprintf("Error parseando mensaje WebSocket: %v", err), "", clientID)
 		return websocket.ErrorResponse("invalid_message", "Error parseando mensaje")
 	}
 
-	a.AddLog("INFO", fmt.Sprintf("WebSocket [%s] acción: %s", clientID, msg.Action), msg.ChannelID)
+	a.addClientLog("DEBUG", fmt.Sprintf("WebSocket [%s] acción: %s", clientID, msg.Action), msg.ChannelID, clientID)
+
+	// El canal se resuelve antes de la acción (delete_channel lo elimina)
+	var ch *channel.Channel
+	if msg.ChannelID != "" {
+		ch = a.findChannel(msg.ChannelID)
+	}
+	response := a.handleWebSocketAction(clientID, msg)
+	a.auditClient(clientID, msg, ch, response)
+	return response
+}
 
+// handleWebSocketAction ejecuta la acción de un mensaje WebSocket
+func (a *App) handleWebSocketAction(clientID string, msg websocket.Message) []byte {
 	switch msg.Action {
 	case "play_video":
 		// Aximmetry solicita reproducir un video específico
@@ -675,6 +848,30 @@ func (a *App) handleWebSocketMessage(clientID string, message []byte) []byte {
 		return a.handleListChannelsRequest(clientID)
 	case "list_files":
 		return a.handleListFilesRequest(clientID, msg)
+	case "set_port":
+		return a.handleSetPortRequest(clientID, msg)
+	case "create_channel":
+		return a.handleCreateChannelRequest(clientID, msg)
+	case "update_channel":
+		return a.handleUpdateChannelRequest(clientID, msg)
+	case "delete_channel":
+		return a.handleDeleteChannelRequest(clientID, msg)
+	case "list_presets":
+		return a.handleListPresetsRequest(clientID)
+	case "activate_preset":
+		return a.handleActivatePresetRequest(clientID, msg)
+	case "get_logs":
+		return a.handleGetLogsRequest(clientID, msg)
+	case "subscribe_logs":
+		return a.handleSubscribeLogsRequest(clientID, msg)
+	case "unsubscribe_logs":
+		return a.handleUnsubscribeLogsRequest(clientID)
+	case "get_log_levels":
+		return a.handleGetLogLevelsRequest()
+	case "set_log_level":
+		return a.handleSetLogLevelRequest(clientID, msg)
+	case "get_audit":
+		return a.handleGetAuditRequest(clientID, msg)
 	default:
 		return websocket.ErrorResponse("unknown_action", "Acción desconocida")
 	}
@@ -683,21 +880,21 @@ func (a *App) handleWebSocketMessage(clientID string, message []byte) []byte {
 // handlePlayVideoRequest maneja solicitudes directas de Aximmetry para reproducir un video
 // Este es el flujo principal: Aximmetry envía la ruta del video que quiere ver
 func (a *App) handlePlayVideoRequest(clientID string, msg websocket.Message) []byte {
-	a.AddLog("DEBUG", fmt.Sprintf("handlePlayVideoRequest: filePath=%s, channelId=%s", msg.FilePath, msg.ChannelID), "")
+	a.addClientLog("DEBUG", fmt.Sprintf("handlePlayVideoRequest: filePath=%s, channelId=%s", msg.FilePath, msg.ChannelID), "", clientID)
 
 	// Validar que se proporcionó una ruta de video
 	if msg.FilePath == "" {
-		a.AddLog("ERROR", "FilePath vacío en solicitud play_video", "")
+		a.addClientLog("ERROR", "FilePath vacío en solicitud play_video", "", clientID)
 		return websocket.ErrorResponse("missing_file_path", "Se requiere la ruta del video (filePath)")
 	}
 
 	// Verificar que el archivo existe
 	if _, err := os.Stat(msg.FilePath); os.IsNotExist(err) {
-		a.AddLog("ERROR", fmt.Sprintf("Archivo no encontrado: %s", msg.FilePath), "")
+		a.addClientLog("ERROR", fmt.Sprintf("Archivo no encontrado: %s", msg.FilePath), "", clientID)
 		return websocket.ErrorResponse("file_not_found", fmt.Sprintf("Archivo no encontrado: %s", msg.FilePath))
 	}
 
-	a.AddLog("DEBUG", fmt.Sprintf("Archivo verificado: %s", msg.FilePath), "")
+	a.addClientLog("DEBUG", fmt.Sprintf("Archivo verificado: %s", msg.FilePath), "", clientID)
 
 	// Determinar el canal a usar
 	var channelID string
@@ -712,7 +909,7 @@ func (a *App) handlePlayVideoRequest(clientID string, msg websocket.Message) []b
 			// Buscar por label si no se encontró por ID
 			ch = a.channelManager.GetByLabel(msg.ChannelID)
 			if ch == nil {
-				a.AddLog("ERROR", fmt.Sprintf("Canal no encontrado: %s", msg.ChannelID), "")
+				a.addClientLog("ERROR", fmt.Sprintf("Canal no encontrado: %s", msg.ChannelID), "", clientID)
 				return websocket.ErrorResponse("channel_not_found", fmt.Sprintf("Canal '%s' no encontrado. Crea el canal primero o no envíes channelId para crear uno automático.", msg.ChannelID))
 			}
 		}
@@ -720,42 +917,22 @@ func (a *App) handlePlayVideoRequest(clientID string, msg websocket.Message) []b
 		streamName = ch.SRTStreamName
 		srtPort = ch.SRTPort
 		srtHost = ch.SRTHost
-		a.AddLog("DEBUG", fmt.Sprintf("Usando canal existente: %s (SRT %s:%d)", ch.Label, srtHost, srtPort), channelID)
+		a.addClientLog("DEBUG", fmt.Sprintf("Usando canal existente: %s (SRT %s:%d)", ch.Label, srtHost, srtPort), channelID, clientID)
 	} else {
-		// Crear o reutilizar un canal para este cliente
-		// Buscar si el cliente ya tiene un canal asignado
-		channels := a.channelManager.GetAll()
-		for _, ch := range channels {
-			if ch.Label == "Client_"+clientID {
-				channelID = ch.ID
-				streamName = ch.SRTStreamName
-				srtPort = ch.SRTPort
-				srtHost = ch.SRTHost
-				break
-			}
-		}
-
-		// Si no existe, crear uno nuevo para este cliente
-		if channelID == "" {
-			newStreamName := fmt.Sprintf("%s%s", a.config.SRTPrefix, clientID[:8])
-			ch, err := a.channelManager.Add("Client_"+clientID, msg.FilePath, newStreamName)
-			if err != nil {
-				a.AddLog("ERROR", fmt.Sprintf("Error creando canal para cliente: %v", err), "")
-				return websocket.ErrorResponse("channel_create_error", err.Error())
-			}
-			channelID = ch.ID
-			streamName = ch.SRTStreamName
-			srtPort = ch.SRTPort
-			srtHost = ch.SRTHost
-
-			// Notificar al frontend del nuevo canal
-			runtime.EventsEmit(a.ctx, "channel:added", ch)
-			a.AddLog("INFO", fmt.Sprintf("Canal creado automáticamente para cliente %s: SRT %s:%d", clientID[:8], srtHost, srtPort), channelID)
+		// Crear o reutilizar el canal automático de este cliente
+		ch, err := a.clientChannel(clientID, msg.FilePath)
+		if err != nil {
+			a.addClientLog("ERROR", fmt.Sprintf("Error creando canal para cliente: %v", err), "", clientID)
+			return websocket.ErrorResponse("channel_create_error", err.Error())
 		}
+		channelID = ch.ID
+		streamName = ch.SRTStreamName
+		srtPort = ch.SRTPort
+		srtHost = ch.SRTHost
 	}
 
 	// Reproducir el video solicitado
-	err := a.PlayVideoOnChannel(channelID, msg.FilePath)
+	err := a.playVideoOnChannel(channelID, msg.FilePath)
 	if err != nil {
 		return websocket.ErrorResponse("play_error", err.Error())
 	}
@@ -767,7 +944,7 @@ func (a *App) handlePlayVideoRequest(clientID string, msg websocket.Message) []b
 	}
 	srtURL := fmt.Sprintf("srt://%s:%d", displayHost, srtPort)
 
-	a.AddLog("INFO", fmt.Sprintf("Aximmetry [%s] solicitó: %s -> %s", clientID[:8], filepath.Base(msg.FilePath), srtURL), channelID)
+	a.addClientLog("INFO", fmt.Sprintf("Aximmetry [%s] solicitó: %s -> %s", clientID[:8], filepath.Base(msg.FilePath), srtURL), channelID, clientID)
 
 	return websocket.SuccessResponse("play_started", map[string]interface{}{
 		"channelId":  channelID,
@@ -781,7 +958,7 @@ func (a *App) handlePlayVideoRequest(clientID string, msg websocket.Message) []b
 }
 
 func (a *App) handlePlayRequest(clientID string, msg websocket.Message) []byte {
-	a.AddLog("DEBUG", fmt.Sprintf("→ handlePlayRequest: buscando canal '%s'", msg.ChannelID), "")
+	a.addClientLog("DEBUG", fmt.Sprintf("→ handlePlayRequest: buscando canal '%s'", msg.ChannelID), "", clientID)
 
 	// Verificar que el canal existe - buscar por ID o por Label
 	ch, err := a.channelManager.Get(msg.ChannelID)
@@ -789,12 +966,12 @@ func (a *App) handlePlayRequest(clientID string, msg websocket.Message) []byte {
 		// Buscar por label si no se encontró por ID
 		ch = a.channelManager.GetByLabel(msg.ChannelID)
 		if ch == nil {
-			a.AddLog("ERROR", fmt.Sprintf("Canal no encontrado: %s (intentado por ID y Label)", msg.ChannelID), "")
+			a.addClientLog("ERROR", fmt.Sprintf("Canal no encontrado: %s (intentado por ID y Label)", msg.ChannelID), "", clientID)
 			return websocket.ErrorResponse("channel_not_found", fmt.Sprintf("Canal '%s' no encontrado. Verifica que el canal exista con ese nombre.", msg.ChannelID))
 		}
-		a.AddLog("DEBUG", fmt.Sprintf("✓ Canal encontrado por label: %s -> ID: %s", msg.ChannelID, ch.ID), ch.ID)
+		a.addClientLog("DEBUG", fmt.Sprintf("✓ Canal encontrado por label: %s -> ID: %s", msg.ChannelID, ch.ID), ch.ID, clientID)
 	} else {
-		a.AddLog("DEBUG", fmt.Sprintf("✓ Canal encontrado por ID: %s", ch.ID), ch.ID)
+		a.addClientLog("DEBUG", fmt.Sprintf("✓ Canal encontrado por ID: %s", ch.ID), ch.ID, clientID)
 	}
 
 	videoPath := msg.FilePath
@@ -803,14 +980,14 @@ func (a *App) handlePlayRequest(clientID string, msg websocket.Message) []byte {
 	}
 
 	if videoPath == "" {
-		a.AddLog("ERROR", "No se especificó filePath y el canal no tiene video asignado", ch.ID)
+		a.addClientLog("ERROR", "No se especificó filePath y el canal no tiene video asignado", ch.ID, clientID)
 		return websocket.ErrorResponse("missing_file_path", "Se requiere especificar filePath porque el canal no tiene video asignado")
 	}
 
-	a.AddLog("DEBUG", fmt.Sprintf("→ Ruta recibida: %s", videoPath), ch.ID)
+	a.addClientLog("DEBUG", fmt.Sprintf("→ Ruta recibida: %s", videoPath), ch.ID, clientID)
 
 	// Iniciar reproducción usando el ID real del canal
-	err = a.PlayVideoOnChannel(ch.ID, videoPath)
+	err = a.playVideoOnChannel(ch.ID, videoPath)
 	if err != nil {
 		return websocket.ErrorResponse("play_error", err.Error())
 	}
@@ -837,12 +1014,12 @@ func (a *App) handleStopRequest(clientID string, msg websocket.Message) []byte {
 	if err != nil {
 		ch = a.channelManager.GetByLabel(msg.ChannelID)
 		if ch == nil {
-			a.AddLog("ERROR", fmt.Sprintf("Canal no encontrado: %s", msg.ChannelID), "")
+			a.addClientLog("ERROR", fmt.Sprintf("Canal no encontrado: %s", msg.ChannelID), "", clientID)
 			return websocket.ErrorResponse("channel_not_found", fmt.Sprintf("Canal '%s' no encontrado", msg.ChannelID))
 		}
 	}
 
-	err = a.StopChannel(ch.ID)
+	err = a.stopChannel(ch.ID)
 	if err != nil {
 		return websocket.ErrorResponse("stop_error", err.Error())
 	}
@@ -892,8 +1069,6 @@ func (a *App) handleListFilesRequest(clientID string, msg websocket.Message) []b
 
 // onFFmpegEvent maneja eventos del gestor FFmpeg
 func (a *App) onFFmpegEvent(event ffmpeg.Event) {
-	var newStatus channel.Status
-
 	switch event.Type {
 	case ffmpeg.EventStarted:
 		// Extraer información adicional del evento
@@ -905,96 +1080,179 @@ func (a *App) onFFmpegEvent(event ffmpeg.Event) {
 				}
 			}
 		}
-		a.AddLog("INFO", fmt.Sprintf("✓ FFmpeg strimeando: %s", msg), event.ChannelID)
-		// No cambiar status aquí - ya se hizo en PlayTestPattern/StartChannel
-		return // No emitir evento duplicado
+		a.logTo(subsystemFFmpeg, "INFO", fmt.Sprintf("✓ FFmpeg strimeando: %s", msg), event.ChannelID)
+		a.dispatchWebhook(webhook.EventChannelStarted, event)
+		// El canal sigue en "starting" hasta EventReady
 	case ffmpeg.EventProgress:
 		// Log periódico de progreso (ya viene limitado desde el manager)
 		if event.Message != "" {
-			a.AddLog("INFO", fmt.Sprintf("→ %s", event.Message), event.ChannelID)
+			a.logTo(subsystemFFmpeg, "INFO", fmt.Sprintf("→ %s", event.Message), event.ChannelID)
+		}
+		if event.Data != nil && event.Data["srtEvent"] == "connected" {
+			a.dispatchWebhook(webhook.EventSRTConnected, event)
 		}
-		return // No cambiar status
 	case ffmpeg.EventWarning:
 		// Encoder de hardware no disponible, usando fallback
-		a.AddLog("WARNING", event.Message, event.ChannelID)
+		a.logTo(subsystemFFmpeg, "WARNING", event.Message, event.ChannelID)
 		runtime.EventsEmit(a.ctx, "ffmpeg:warning", map[string]interface{}{
 			"channelId": event.ChannelID,
 			"message":   event.Message,
 			"data":      event.Data,
 		})
-		return // No cambiar status, el stream continuará con el fallback
+		if event.Data != nil && event.Data["reason"] == "hardware_encoder_unavailable" {
+			a.dispatchWebhook(webhook.EventEncoderFallback, event)
+		}
+		// No cambiar status, el stream continuará con el fallback
+	case ffmpeg.EventReady:
+		signal, _ := event.Data["signal"].(string)
+		a.logTo(subsystemFFmpeg, "INFO", fmt.Sprintf("✓ Stream listo (%s)", signal), event.ChannelID)
+		a.channelManager.Transition(event.ChannelID, channel.StatusActive, signal)
 	case ffmpeg.EventStopped:
-		a.AddLog("INFO", fmt.Sprintf("FFmpeg detenido para canal %s", event.ChannelID), event.ChannelID)
-		a.channelManager.SetStatus(event.ChannelID, channel.StatusInactive)
-		newStatus = channel.StatusInactive
+		a.logTo(subsystemFFmpeg, "INFO", fmt.Sprintf("FFmpeg detenido para canal %s", event.ChannelID), event.ChannelID)
+		a.dispatchWebhook(webhook.EventChannelStopped, event)
+		// Proceso reemplazado al cambiar de video: el canal sigue iniciando
+		if requested, _ := event.Data["requested"].(bool); requested {
+			if ch, err := a.channelManager.Get(event.ChannelID); err == nil && ch.Status == channel.StatusStarting {
+				return
+			}
+		}
+		a.channelManager.Transition(event.ChannelID, channel.StatusInactive, event.Message)
 	case ffmpeg.EventError:
-		// Detectar si es un error de desconexión del cliente SRT (I/O error)
-		isSRTDisconnect := strings.Contains(event.Message, "I/O error") ||
-			strings.Contains(event.Message, "exit status 0xfffffffb") ||
-			strings.Contains(event.Message, "muxing a packet")
-
-		if isSRTDisconnect {
-			a.AddLog("INFO", fmt.Sprintf("Cliente SRT desconectado del canal %s. Pulse 'Patrón' o 'Iniciar' para reanudar.", event.ChannelID), event.ChannelID)
-			a.channelManager.SetStatus(event.ChannelID, channel.StatusInactive)
-			newStatus = channel.StatusInactive
-		} else {
-			a.AddLog("ERROR", fmt.Sprintf("Error FFmpeg en canal %s: %s", event.ChannelID, event.Message), event.ChannelID)
-			a.channelManager.SetStatus(event.ChannelID, channel.StatusError)
-			newStatus = channel.StatusError
-
-			// Intentar reinicio automático si está configurado
-			if a.config.AutoRestart {
-				go a.attemptRestart(event.ChannelID)
+		code := ffmpeg.ErrorUnknown
+		if c, ok := event.Data["code"].(string); ok && c != "" {
+			code = ffmpeg.ErrorCode(c)
+		}
+
+		// La desconexión del receptor SRT no es un fallo del canal
+		if code == ffmpeg.ErrorSRTDisconnected {
+			a.logTo(subsystemFFmpeg, "INFO", fmt.Sprintf("Cliente SRT desconectado del canal %s. Pulse 'Patrón' o 'Iniciar' para reanudar.", event.ChannelID), event.ChannelID)
+			a.channelManager.Transition(event.ChannelID, channel.StatusInactive, "cliente SRT desconectado")
+			a.dispatchWebhook(webhook.EventSRTDisconnected, event)
+			return
+		}
+
+		a.logTo(subsystemFFmpeg, "ERROR", fmt.Sprintf("Error FFmpeg en canal %s [%s]: %s", event.ChannelID, code, event.Message), event.ChannelID)
+		alreadyFailed := false
+		if ch, err := a.channelManager.Get(event.ChannelID); err == nil {
+			alreadyFailed = ch.Status == channel.StatusError
+		}
+		a.channelManager.Fail(event.ChannelID, string(code), event.Message)
+		a.dispatchWebhook(webhook.EventChannelError, event)
+
+		// Intentar reinicio automático según la política configurada
+		// (una sola vez por fallo y solo si la categoría admite reintento)
+		if a.cfg().AutoRestart && !alreadyFailed {
+			if code.Retryable() {
+				a.scheduleRestart(event.ChannelID, event.Message)
+			} else {
+				a.logTo(subsystemFFmpeg, "WARNING", fmt.Sprintf("Reinicio automático omitido: el error %s no se resuelve reintentando", code), event.ChannelID)
 			}
 		}
-	default:
-		return
 	}
-
-	// Emitir channel:status con el status actualizado
-	runtime.EventsEmit(a.ctx, "channel:status", map[string]interface{}{
-		"channelId": event.ChannelID,
-		"status":    newStatus,
-		"event":     event.Type,
-		"message":   event.Message,
-	})
 }
 
-// attemptRestart intenta reiniciar un canal que falló
-// Solo reinicia si hay un archivo para reproducir y no excede el límite de reintentos
-func (a *App) attemptRestart(channelID string) {
-	// No reintentar inmediatamente, usar backoff
-	time.Sleep(10 * time.Second)
+// onChannelTransition notifica cada cambio de estado de un canal
+func (a *App) onChannelTransition(t channel.Transition) {
+	a.AddLog("DEBUG", fmt.Sprintf("Estado %s -> %s (%s)", t.From, t.To, t.Reason), t.ChannelID)
+	runtime.EventsEmit(a.ctx, "channel:transition", t)
+	a.emitChannelStatus(map[string]interface{}{
+		"channelId":      t.ChannelID,
+		"status":         t.To,
+		"previousStatus": t.From,
+		"event":          "transition",
+		"message":        t.Reason,
+		"errorCode":      t.ErrorCode,
+	})
 
-	ch, err := a.channelManager.Get(channelID)
-	if err != nil {
-		return
+	// Push a los clientes WebSocket/Socket.IO
+	update := map[string]interface{}{
+		"channelId":      t.ChannelID,
+		"status":         t.To,
+		"previousStatus": t.From,
+		"reason":         t.Reason,
+		"time":           t.Time,
 	}
-
-	// No reiniciar si no está en error o si no hay archivo configurado
-	if ch.Status != channel.StatusError {
-		return
+	if t.ErrorCode != "" {
+		update["errorCode"] = t.ErrorCode
 	}
-
-	// Verificar que hay un archivo para reproducir
-	inputPath := ch.VideoPath
-	if inputPath == "" {
-		inputPath = ch.CurrentFile
+	if ch, err := a.channelManager.Get(t.ChannelID); err == nil {
+		update["label"] = ch.Label
+		update["srtPort"] = ch.SRTPort
+		update["currentFile"] = ch.CurrentFile
 	}
+	if a.wsServer != nil {
+		a.wsServer.Broadcast(websocket.SuccessResponse("channel_status_update", update))
+	}
+}
 
-	if inputPath == "" {
-		a.AddLog("INFO", fmt.Sprintf("Canal %s en error pero sin archivo para reiniciar. Use 'Patrón' o configure un video.", ch.Label), channelID)
-		return
+// streamConfig configuración de FFmpeg para un canal: encoding y SRT de la
+// configuración actual y parámetros de salida del canal
+func (a *App) streamConfig(ch *channel.Channel, inputPath string, loop bool) ffmpeg.StreamConfig {
+	settings := a.cfg()
+	cfg := ffmpeg.StreamConfig{
+		ChannelID:     ch.ID,
+		InputPath:     inputPath,
+		SRTStreamName: ch.SRTStreamName,
+		SRTPort:       ch.SRTPort,
+		SRTHost:       ch.SRTHost,
+		VideoBitrate:  settings.DefaultVideoBitrate,
+		AudioBitrate:  settings.DefaultAudioBitrate,
+		Loop:          loop,
+		// Configuración avanzada de encoding
+		VideoEncoder:   settings.VideoEncoder,
+		EncoderPreset:  settings.EncoderPreset,
+		EncoderProfile: settings.EncoderProfile,
+		EncoderTune:    settings.EncoderTune,
+		GopSize:        settings.GopSize,
+		BFrames:        settings.BFrames,
+		// Control de bitrate
+		BitrateMode: settings.BitrateMode,
+		MaxBitrate:  settings.MaxBitrate,
+		BufferSize:  settings.BufferSize,
+		// SRT avanzado
+		SRTLatency:    settings.SRTLatency,
+		SRTRecvBuffer: settings.SRTRecvBuffer,
+		SRTSendBuffer: settings.SRTSendBuffer,
+		SRTOverheadBW: settings.SRTOverheadBW,
 	}
+	a.applyChannelOutput(&cfg, ch)
+	return cfg
+}
 
-	// Verificar que el archivo existe antes de reintentar
-	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
-		a.AddLog("ERROR", fmt.Sprintf("Archivo no encontrado para reinicio: %s", inputPath), channelID)
-		return
+// applyChannelOutput copia los parámetros de salida del canal a la configuración de FFmpeg
+func (a *App) applyChannelOutput(cfg *ffmpeg.StreamConfig, ch *channel.Channel) {
+	output := ch.Output()
+	cfg.Width, cfg.Height, _ = output.Size()
+	cfg.FrameRate = output.FrameRate
+	if output.FrameRate == 0 {
+		cfg.FrameRate = a.cfg().DefaultFrameRate
+	}
+	cfg.ScaleMode = output.ScaleMode
+	cfg.Interlace = output.Interlace
+	cfg.PixelFormat = output.PixelFormat
+	cfg.AudioChannels = output.AudioChannels
+}
+
+// stopGrace espera de detención ordenada de FFmpeg configurada
+func (a *App) stopGrace() time.Duration {
+	return time.Duration(a.cfg().StopGracePeriod) * time.Millisecond
+}
+
+// emitChannelStatus notifica un cambio de estado de canal al frontend y a las integraciones
+func (a *App) emitChannelStatus(data map[string]interface{}) {
+	runtime.EventsEmit(a.ctx, "channel:status", data)
+	a.sendOSCStatus(data)
+	if channelID, ok := data["channelId"].(string); ok {
+		a.publishMQTTChannel(channelID)
 	}
+}
 
-	a.AddLog("INFO", fmt.Sprintf("Intentando reiniciar canal %s", ch.Label), channelID)
-	a.StartChannel(channelID)
+// findChannel busca un canal por ID o, si no existe, por label (una copia)
+func (a *App) findChannel(idOrLabel string) *channel.Channel {
+	if ch, err := a.channelManager.Get(idOrLabel); err == nil {
+		return ch
+	}
+	return a.channelManager.GetByLabel(idOrLabel)
 }
 
 // getServerIP obtiene la IP local del servidor
@@ -1024,16 +1282,34 @@ func (a *App) monitorChannels(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			// Canales atascados en starting/stopping pasan a error
+			cfg := a.cfg()
+			a.channelManager.CheckTimeouts(
+				time.Duration(cfg.StartTimeout)*time.Second,
+				time.Duration(cfg.StopTimeout)*time.Second,
+			)
+
+			// Canales automáticos de clientes desconectados más allá del periodo de gracia
+			a.collectClientChannels()
+
 			channels := a.channelManager.GetAll()
 			for _, ch := range channels {
+				if ch.Status == channel.StatusStarting && a.launchFailed(ch.ID) {
+					a.channelManager.Transition(ch.ID, channel.StatusError, "FFmpeg terminó antes de estar listo")
+				}
 				if ch.Status == channel.StatusActive {
+					// Actualizar estadísticas desde el progreso de FFmpeg
+					if info, err := a.ffmpegManager.GetProcessInfo(ch.ID); err == nil {
+						stats := ch.Stats
+						stats.FramesProcessed = info.Progress.Frame
+						stats.BytesSent = info.Progress.TotalSize
+						stats.Uptime = time.Since(info.StartTime)
+						a.channelManager.UpdateStats(ch.ID, stats)
+					}
+
 					// Verificar que FFmpeg sigue corriendo
 					if !a.ffmpegManager.IsRunning(ch.ID) {
-						a.channelManager.SetStatus(ch.ID, channel.StatusInactive)
-						runtime.EventsEmit(a.ctx, "channel:status", map[string]interface{}{
-							"channelId": ch.ID,
-							"status":    channel.StatusInactive,
-						})
+						a.channelManager.Transition(ch.ID, channel.StatusInactive, "proceso FFmpeg no encontrado")
 					}
 				}
 			}
@@ -0,0 +1,272 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"servidor-stream/internal/audit"
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/config"
+	"servidor-stream/internal/mqttbridge"
+	"servidor-stream/internal/osc"
+	"servidor-stream/internal/websocket"
+)
+
+// Registro de auditoría: quién hizo qué, dónde y cuándo. Se registran los
+// métodos de la interfaz que cambian algo (el nombre del método como acción),
+// todas las acciones WebSocket y MQTT y los comandos OSC. Los métodos públicos
+// registran; los privados que usan internamente no, para no duplicar entradas.
+
+// openAudit abre el registro de auditoría en <datos>/audit
+func (a *App) openAudit() {
+	auditLog, err := audit.Open(config.GetAuditDir())
+	if err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("No se puede abrir el registro de auditoría: %v", err), "")
+		return
+	}
+	a.audit = auditLog
+}
+
+// recordAudit agrega una entrada. Un fallo se informa en el log pero no
+// interrumpe la acción.
+func (a *App) recordAudit(entry audit.Entry) {
+	if a.audit == nil {
+		return
+	}
+	if err := a.audit.Record(entry); err != nil {
+		a.AddLog("WARNING", fmt.Sprintf("No se pudo registrar en auditoría %s: %v", entry.Action, err), entry.ChannelID)
+	}
+}
+
+// auditParams parámetros de una acción en JSON (nil si no hay)
+func auditParams(params interface{}) json.RawMessage {
+	if params == nil {
+		return nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// auditUI registra una acción de la interfaz sobre un canal (channelID vacío
+// si no afecta a uno). Uso: defer func() { a.auditUI("StopChannel", channelID, nil, err) }()
+func (a *App) auditUI(action, channelID string, params interface{}, err error) {
+	var ch *channel.Channel
+	if channelID != "" {
+		if ch = a.findChannel(channelID); ch == nil {
+			ch = &channel.Channel{ID: channelID}
+		}
+	}
+	a.auditUIChannel(action, ch, params, err)
+}
+
+// auditUIChannel registra una acción de la interfaz con el canal ya resuelto
+// (ej: tomado antes de eliminarlo)
+func (a *App) auditUIChannel(action string, ch *channel.Channel, params interface{}, err error) {
+	entry := audit.Entry{
+		Source:  audit.SourceUI,
+		Action:  action,
+		Params:  auditParams(params),
+		Success: err == nil,
+	}
+	if ch != nil {
+		entry.ChannelID, entry.ChannelLabel = ch.ID, ch.Label
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	a.recordAudit(entry)
+}
+
+// auditClient registra una acción WebSocket o MQTT con su resultado. ch es el
+// canal indicado en el mensaje, resuelto antes de ejecutar la acción.
+func (a *App) auditClient(clientID string, msg websocket.Message, ch *channel.Channel, response []byte) {
+	// /api/channels reutiliza list_channels sin ser una acción de control
+	if clientID == websocket.APIClientID {
+		return
+	}
+
+	params := map[string]interface{}{}
+	if msg.ChannelID != "" {
+		params["channelId"] = msg.ChannelID
+	}
+	if msg.FilePath != "" {
+		params["filePath"] = msg.FilePath
+	}
+	if len(msg.Parameters) > 0 {
+		params["parameters"] = msg.Parameters
+	}
+
+	entry := audit.Entry{
+		Source: audit.SourceWebSocket,
+		Action: msg.Action,
+	}
+	if clientID == mqttbridge.ClientID {
+		entry.Source = audit.SourceMQTT
+	} else {
+		// Un cliente que ya se desconectó queda registrado solo con su ID
+		entry.ClientID = clientID
+		if info, ok := a.wsServer.GetClient(clientID); ok {
+			entry.ClientName, entry.RemoteAddr = info.Name, info.RemoteAddr
+		}
+	}
+	if len(params) > 0 {
+		entry.Params = auditParams(params)
+	}
+
+	var result struct {
+		Success bool                   `json:"success"`
+		Action  string                 `json:"action"`
+		Error   string                 `json:"error"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	json.Unmarshal(response, &result) // Data puede no ser un objeto: se ignora
+	entry.Success = result.Success
+	if !result.Success {
+		entry.ErrorCode, entry.Error = result.Action, result.Error
+	}
+
+	// Canal creado por la acción o asignado al cliente (play_video sin canal)
+	if ch == nil && result.Success {
+		if id, _ := result.Data["channelId"].(string); id != "" {
+			ch = a.findChannel(id)
+		} else if id, _ := result.Data["id"].(string); id != "" && result.Action == "channel_created" {
+			ch = a.findChannel(id)
+		}
+	}
+	if ch != nil {
+		entry.ChannelID, entry.ChannelLabel = ch.ID, ch.Label
+	}
+	a.recordAudit(entry)
+}
+
+// auditOSC registra un comando OSC (ch nil en /stop_all)
+func (a *App) auditOSC(msg osc.Message, command string, ch *channel.Channel, err error) {
+	entry := audit.Entry{
+		Source:  audit.SourceOSC,
+		Action:  command,
+		Params:  auditParams(map[string]interface{}{"address": msg.Address, "args": msg.Args}),
+		Success: err == nil,
+	}
+	if msg.Source != nil {
+		entry.RemoteAddr = msg.Source.String()
+	}
+	if ch != nil {
+		entry.ChannelID, entry.ChannelLabel = ch.ID, ch.Label
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	a.recordAudit(entry)
+}
+
+// QueryAudit consulta el registro de auditoría (las entradas más recientes
+// que cumplen los filtros, en orden cronológico)
+func (a *App) QueryAudit(q audit.Query) (*audit.Page, error) {
+	if a.audit == nil {
+		return nil, errors.New("registro de auditoría no disponible")
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return nil, errors.New("until es anterior a since")
+	}
+	return a.audit.Query(q)
+}
+
+// auditQueryFromValues lee los filtros de /api/audit y de get_audit
+func auditQueryFromValues(get func(key string) string) (audit.Query, error) {
+	q := audit.Query{
+		Channel: get("channel"),
+		Client:  get("client"),
+		Source:  get("source"),
+		Action:  get("action"),
+	}
+
+	var err error
+	for key, field := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		if value := get(key); value != "" {
+			if *field, err = parseLogTime(key, value); err != nil {
+				return q, err
+			}
+		}
+	}
+	if value := get("limit"); value != "" {
+		if q.Limit, err = strconv.Atoi(value); err != nil || q.Limit < 0 {
+			return q, fmt.Errorf("limit debe ser un número entero positivo")
+		}
+	}
+	return q, nil
+}
+
+// handleAuditAPI endpoint REST del registro de auditoría (GET /api/audit,
+// requiere el token de administración)
+func (a *App) handleAuditAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !a.requireAdminToken(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]string{"error": "método no permitido"})
+		return
+	}
+
+	q, err := auditQueryFromValues(r.URL.Query().Get)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	page, err := a.QueryAudit(q)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+// handleGetAuditRequest consulta el registro de auditoría (mismos filtros que
+// /api/audit en parameters). Solo para administradores.
+func (a *App) handleGetAuditRequest(clientID string, msg websocket.Message) []byte {
+	if err := a.authorizeReader(clientID); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	var paramErr error
+	q, err := auditQueryFromValues(func(key string) string {
+		if key == "limit" {
+			value, ok, err := msg.IntParam(key)
+			if err != nil {
+				paramErr = err
+			}
+			if !ok {
+				return ""
+			}
+			return strconv.Itoa(value)
+		}
+		value, _, err := msg.StringParam(key)
+		if err != nil {
+			paramErr = err
+		}
+		return value
+	})
+	if paramErr != nil {
+		err = paramErr
+	}
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+
+	page, err := a.QueryAudit(q)
+	if err != nil {
+		return websocket.ErrorResponse("audit_error", err.Error())
+	}
+	return websocket.SuccessResponse("audit", page)
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/websocket"
+)
+
+// clientKey identidad estable del cliente WebSocket (el ID si ya no está conectado)
+func (a *App) clientKey(clientID string) string {
+	if a.wsServer != nil {
+		if info, ok := a.wsServer.GetClient(clientID); ok {
+			return info.Key
+		}
+	}
+	return clientID
+}
+
+// clientChannel retorna el canal automático del cliente (play_video sin
+// channelId), creándolo si no tiene. Los clientes que se identifican con
+// clientKey o name recuperan el mismo canal al reconectar.
+func (a *App) clientChannel(clientID, filePath string) (*channel.Channel, error) {
+	cfg := a.cfg()
+	key := a.clientKey(clientID)
+
+	short := clientID
+	if len(short) > 8 {
+		short = short[:8]
+	}
+
+	// Clientes anónimos: Client_<id de conexión>; identificados: Client_<clave>
+	label := channel.ClientLabelPrefix + sanitizeStreamSuffix(key)
+	if a.labelTaken(label, "") {
+		label += "_" + short
+	}
+
+	// Nombres de stream por preferencia: la clave, el ID corto y el ID completo
+	// de la conexión (único) si los anteriores ya están en uso
+	var specs []channel.Spec
+	seen := make(map[string]bool)
+	for _, suffix := range []string{sanitizeStreamSuffix(key), short, clientID} {
+		if seen[suffix] {
+			continue
+		}
+		seen[suffix] = true
+		specs = append(specs, channel.Spec{Label: label, VideoPath: filePath, SRTStreamName: cfg.SRTPrefix + suffix})
+	}
+
+	ch, created, err := a.channelManager.EnsureEphemeral(key, specs...)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		a.AddLog("INFO", fmt.Sprintf("Canal creado automáticamente para cliente %s: SRT %s:%d", key, ch.SRTHost, ch.SRTPort), ch.ID)
+		a.notifyChannelAdded(&ch, clientID)
+	}
+
+	return &ch, nil
+}
+
+// sanitizeStreamSuffix deja solo caracteres seguros para etiquetas y nombres de stream
+func sanitizeStreamSuffix(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// claimClientChannels cancela la expiración de los canales automáticos de un
+// cliente que vuelve a conectarse
+func (a *App) claimClientChannels(client websocket.ClientInfo) {
+	for _, ch := range a.channelManager.ClaimEphemeral(client.Key) {
+		a.addClientLog("INFO", fmt.Sprintf("Cliente %s recuperó su canal %s", client.Name, ch.Label), ch.ID, client.ID)
+		a.notifyChannelUpdated(&ch, "")
+	}
+}
+
+// releaseClientChannels inicia el periodo de gracia de los canales automáticos
+// cuando se desconecta la última conexión con esa identidad
+func (a *App) releaseClientChannels(client websocket.ClientInfo) {
+	if a.wsServer != nil && a.wsServer.HasClientKey(client.Key) {
+		return
+	}
+
+	for _, ch := range a.channelManager.ReleaseEphemeral(client.Key, time.Now()) {
+		a.AddLog("INFO", fmt.Sprintf("Canal %s se eliminará si el cliente no vuelve en %ds", ch.Label, a.cfg().ClientChannelGracePeriod), ch.ID)
+		a.notifyChannelUpdated(&ch, "")
+	}
+}
+
+// collectClientChannels elimina los canales automáticos cuyo cliente lleva
+// desconectado más de clientChannelGracePeriod (detiene el stream si sigue activo)
+func (a *App) collectClientChannels() {
+	grace := time.Duration(a.cfg().ClientChannelGracePeriod) * time.Second
+	for _, ch := range a.channelManager.ExpiredEphemeral(grace, time.Now()) {
+		if err := a.removeChannel(ch.ID); err != nil {
+			continue
+		}
+		a.AddLog("INFO", fmt.Sprintf("Canal automático %s eliminado: el cliente no volvió a conectarse", ch.Label), ch.ID)
+		a.notifyChannelRemoved(&ch, "")
+	}
+}
@@ -0,0 +1,295 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/config"
+)
+
+// configWatchInterval cada cuánto se comprueba si config.json cambió en disco
+const configWatchInterval = 2 * time.Second
+
+// ConfigUpdate resultado de aplicar una configuración nueva
+type ConfigUpdate struct {
+	Changes          []config.Change `json:"changes"`
+	AffectedChannels []string        `json:"affectedChannels"` // Canales emitiendo con los parámetros anteriores
+	RestartRequired  bool            `json:"restartRequired"`  // Hay cambios que solo se aplican al reiniciar la aplicación
+}
+
+// fileStamp identifica una versión de un archivo en disco
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statConfig versión actual de config.json (vacía si no existe)
+func statConfig() fileStamp {
+	info, err := os.Stat(config.GetConfigPath())
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// ValidateConfig verifica una configuración sin aplicarla (errores por campo)
+func (a *App) ValidateConfig(cfg *config.Config) []config.FieldError {
+	var verr config.ValidationError
+	if err := cfg.Validate(); errors.As(err, &verr) {
+		return verr
+	}
+	return []config.FieldError{}
+}
+
+// GetConfigOverrides campos impuestos por variables de entorno o flags (campo ->
+// origen). No se pueden cambiar desde Ajustes.
+func (a *App) GetConfigOverrides() map[string]string {
+	return config.Overridden()
+}
+
+// UpdateConfig valida, guarda y aplica la configuración. Los cambios seguros se
+// aplican al momento; el resultado indica qué canales deben reiniciarse para
+// usar los nuevos parámetros de encoding/SRT y si hace falta reiniciar la
+// aplicación.
+func (a *App) UpdateConfig(cfg *config.Config) (*ConfigUpdate, error) {
+	update, err := a.updateConfig(cfg)
+	a.auditUI("UpdateConfig", "", auditConfigChanges(update), err)
+	return update, err
+}
+
+// updateConfig guarda y aplica la configuración sin auditar
+func (a *App) updateConfig(cfg *config.Config) (*ConfigUpdate, error) {
+	config.ApplyOverrides(cfg) // Entorno y flags siguen teniendo prioridad
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
+	if err := config.Save(cfg); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error guardando configuración: %v", err), "")
+		return nil, err
+	}
+	a.configStamp = statConfig() // No recargar nuestro propio guardado
+
+	return a.applyConfig(cfg, "ajustes"), nil
+}
+
+// auditConfigChanges valor nuevo de cada campo modificado, con los secretos
+// ocultos (nil si no se guardó nada)
+func auditConfigChanges(update *ConfigUpdate) map[string]string {
+	if update == nil {
+		return nil
+	}
+	changes := make(map[string]string, len(update.Changes))
+	for _, c := range update.Changes {
+		changes[c.Field] = describeConfigValue(c.Field, c.New)
+	}
+	return changes
+}
+
+// RestartChannels reinicia los canales indicados para que usen la
+// configuración actual (mismo archivo que estaban reproduciendo)
+func (a *App) RestartChannels(channelIDs []string) error {
+	err := a.restartChannels(channelIDs)
+	a.auditUI("RestartChannels", "", map[string][]string{"channelIds": channelIDs}, err)
+	return err
+}
+
+// restartChannels reinicia los canales sin auditar
+func (a *App) restartChannels(channelIDs []string) error {
+	var errs []string
+	for _, id := range channelIDs {
+		if err := a.restartChannel(id); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error reiniciando canales: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// restartChannel relanza un canal que sigue emitiendo
+func (a *App) restartChannel(channelID string) error {
+	ch, err := a.channelManager.Get(channelID)
+	if err != nil {
+		return err
+	}
+	if ch.Status != channel.StatusActive && ch.Status != channel.StatusStarting {
+		return nil // Ya no está emitiendo: usará la configuración nueva al iniciar
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("Reiniciando %s para aplicar la configuración", ch.Label), channelID)
+	switch {
+	case ch.Loop: // Patrón de prueba (con la ruta configurada ahora)
+		return a.playTestPattern(channelID)
+	case ch.CurrentFile != "":
+		return a.playVideoOnChannel(channelID, ch.CurrentFile)
+	default:
+		return a.manualStart(channelID)
+	}
+}
+
+// applyConfig reemplaza la configuración (ya validada) y aplica en caliente lo
+// que no requiere reiniciar nada. Llamar con configMutex tomado.
+func (a *App) applyConfig(cfg *config.Config, source string) *ConfigUpdate {
+	changes := config.Diff(a.cfg(), cfg)
+	a.config.Store(cfg)
+
+	a.restarts.SetPolicy(a.restartPolicy())
+	a.ffmpegManager.SetStopGrace(a.stopGrace())
+	a.ffmpegManager.SetFFmpegPath(cfg.FFmpegPath)
+	a.channelManager.SetPortRanges(a.portRanges())
+	a.webhooks.SetTargets(a.webhookTargets())
+	a.applyLogLevels()
+	for _, c := range changes {
+		switch c.Field {
+		case "oscFeedbackTargets":
+			if a.oscServer != nil {
+				if err := a.oscServer.SetFeedbackTargets(cfg.OSCFeedbackTargets); err != nil {
+					a.AddLog("WARNING", err.Error(), "")
+				}
+			}
+		case "maxLogLines":
+			a.trimLogs()
+		}
+	}
+	for _, c := range changes {
+		if logFileFields[c.Field] {
+			a.openLogFile()
+			break
+		}
+	}
+
+	update := &ConfigUpdate{
+		Changes:          changes,
+		AffectedChannels: []string{},
+		RestartRequired:  config.HasMode(changes, config.ApplyRestart),
+	}
+	if update.Changes == nil {
+		update.Changes = []config.Change{}
+	}
+	if config.HasMode(changes, config.ApplyChannels) {
+		for _, ch := range a.channelManager.GetAll() {
+			if ch.Status == channel.StatusActive || ch.Status == channel.StatusStarting {
+				update.AffectedChannels = append(update.AffectedChannels, ch.ID)
+			}
+		}
+	}
+
+	a.logConfigChanges(update, source)
+	runtime.EventsEmit(a.ctx, "config:updated", map[string]interface{}{
+		"config": cfg,
+		"update": update,
+		"source": source,
+	})
+	return update
+}
+
+// logConfigChanges registra cada campo modificado y lo que falta para aplicarlo
+func (a *App) logConfigChanges(update *ConfigUpdate, source string) {
+	if len(update.Changes) == 0 {
+		a.AddLog("INFO", fmt.Sprintf("Configuración guardada (%s) sin cambios", source), "")
+		return
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("Configuración actualizada (%s): %d cambios", source, len(update.Changes)), "")
+	var restartFields []string
+	for _, c := range update.Changes {
+		a.AddLog("DEBUG", fmt.Sprintf("  %s: %s → %s", c.Field, describeConfigValue(c.Field, c.Old), describeConfigValue(c.Field, c.New)), "")
+		if c.Apply == config.ApplyRestart {
+			restartFields = append(restartFields, c.Field)
+		}
+	}
+
+	if n := len(update.AffectedChannels); n > 0 {
+		a.AddLog("WARNING", fmt.Sprintf("%d canales activos siguen con los parámetros anteriores hasta reiniciarse", n), "")
+	}
+	if len(restartFields) > 0 {
+		a.AddLog("WARNING", fmt.Sprintf("Reinicie la aplicación para aplicar: %s", strings.Join(restartFields, ", ")), "")
+	}
+}
+
+// describeConfigValue valor para el log, sin exponer secretos
+func describeConfigValue(field string, value interface{}) string {
+	switch field {
+	case "mqttPassword", "wsAdminToken":
+		if value == "" {
+			return `""`
+		}
+		return "***"
+	case "webhooks":
+		if targets, ok := value.([]config.WebhookTarget); ok {
+			return fmt.Sprintf("%d destinos", len(targets))
+		}
+	}
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(value)
+}
+
+// trimLogs ajusta la capacidad del buffer de logs a maxLogLines, descartando
+// los más antiguos que no quepan
+func (a *App) trimLogs() {
+	a.logMutex.Lock()
+	a.logBuffer.resize(a.cfg().MaxLogLines)
+	a.logMutex.Unlock()
+}
+
+// watchConfigFile recarga config.json cuando se edita fuera de la aplicación.
+// Un archivo inválido no se aplica: se avisa en el log y se mantiene la
+// configuración actual.
+func (a *App) watchConfigFile(ctx context.Context) {
+	a.configMutex.Lock()
+	a.configStamp = statConfig()
+	a.configMutex.Unlock()
+
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.reloadConfigFile()
+		}
+	}
+}
+
+// reloadConfigFile aplica config.json si cambió desde la última lectura
+func (a *App) reloadConfigFile() {
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
+	stamp := statConfig()
+	if stamp == a.configStamp || stamp.modTime.IsZero() {
+		return
+	}
+	a.configStamp = stamp
+
+	cfg, err := config.Read()
+	if err != nil {
+		a.AddLog("WARNING", fmt.Sprintf("config.json modificado pero no se puede leer, se mantiene la configuración actual: %v", err), "")
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		a.AddLog("WARNING", fmt.Sprintf("config.json modificado con valores inválidos, se mantiene la configuración actual: %v", err), "")
+		return
+	}
+	if len(config.Diff(a.cfg(), cfg)) == 0 {
+		return
+	}
+
+	a.applyConfig(cfg, "archivo")
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/config"
+	"servidor-stream/internal/ffmpeg"
+	"servidor-stream/internal/ports"
+)
+
+// Estados de salud
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+)
+
+// ffmpegCheckTTL tiempo que se cachea la verificación de FFmpeg (lanza procesos)
+const ffmpegCheckTTL = 60 * time.Second
+
+// HealthCheck resultado de una verificación individual
+type HealthCheck struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	ChannelID string `json:"channelId,omitempty"`
+}
+
+// HealthReport resultado agregado de las verificaciones
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+	Uptime string        `json:"uptime"`
+	Time   string        `json:"time"`
+}
+
+// ffmpegHealth resultado cacheado de la verificación de FFmpeg
+type ffmpegHealth struct {
+	mutex      sync.Mutex
+	checkedAt  time.Time
+	path       string
+	installed  bool
+	version    string
+	srtSupport bool
+}
+
+// handleHealthLive indica si el proceso está vivo y atendiendo peticiones
+func (a *App) handleHealthLive(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, HealthReport{
+		Status: HealthHealthy,
+		Checks: []HealthCheck{},
+		Uptime: time.Since(a.startedAt).Round(time.Second).String(),
+		Time:   time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleHealthReady verifica que el servidor puede atender streams
+func (a *App) handleHealthReady(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, a.CheckHealth())
+}
+
+// CheckHealth ejecuta todas las verificaciones de readiness
+func (a *App) CheckHealth() HealthReport {
+	checks := make([]HealthCheck, 0)
+	checks = append(checks, a.checkFFmpeg()...)
+	checks = append(checks, checkWritable("config_file", config.GetConfigPath()))
+	checks = append(checks, checkWritable("channels_file", config.GetChannelsPath()))
+	checks = append(checks, a.checkChannels()...)
+
+	return HealthReport{
+		Status: aggregateHealth(checks),
+		Checks: checks,
+		Uptime: time.Since(a.startedAt).Round(time.Second).String(),
+		Time:   time.Now().Format(time.RFC3339),
+	}
+}
+
+// checkFFmpeg verifica que FFmpeg está instalado y soporta SRT (cacheado)
+func (a *App) checkFFmpeg() []HealthCheck {
+	cfg := a.cfg()
+	h := &a.ffmpegHealth
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.path != cfg.FFmpegPath || time.Since(h.checkedAt) > ffmpegCheckTTL {
+		h.path = cfg.FFmpegPath
+		h.installed, h.version = ffmpeg.CheckFFmpegInstalled(h.path)
+		h.srtSupport = h.installed && ffmpeg.HasSRTSupport(h.path)
+		h.checkedAt = time.Now()
+	}
+
+	if !h.installed {
+		return []HealthCheck{{
+			Name:    "ffmpeg",
+			Status:  HealthUnhealthy,
+			Message: fmt.Sprintf("FFmpeg no disponible en '%s'", h.path),
+		}}
+	}
+
+	checks := []HealthCheck{{Name: "ffmpeg", Status: HealthHealthy, Message: h.version}}
+	if h.srtSupport {
+		checks = append(checks, HealthCheck{Name: "ffmpeg_srt", Status: HealthHealthy})
+	} else {
+		checks = append(checks, HealthCheck{
+			Name:    "ffmpeg_srt",
+			Status:  HealthUnhealthy,
+			Message: "FFmpeg sin soporte SRT/MPEG-TS",
+		})
+	}
+	return checks
+}
+
+// checkChannels verifica cada canal: proceso vivo si está activo, puerto SRT libre si no
+func (a *App) checkChannels() []HealthCheck {
+	checks := make([]HealthCheck, 0)
+
+	for _, ch := range a.channelManager.GetAll() {
+		check := HealthCheck{
+			Name:      "channel:" + ch.Label,
+			Status:    HealthHealthy,
+			ChannelID: ch.ID,
+		}
+
+		switch ch.Status {
+		case channel.StatusActive, channel.StatusStarting:
+			if !a.ffmpegManager.IsRunning(ch.ID) {
+				check.Status = HealthDegraded
+				check.Message = "canal activo sin proceso FFmpeg en ejecución"
+			}
+		case channel.StatusError:
+			check.Status = HealthDegraded
+			check.Message = "canal en error"
+			if ch.ErrorMessage != "" {
+				check.Message += ": " + ch.ErrorMessage
+			}
+		default:
+			if a.ffmpegManager.IsRunning(ch.ID) {
+				break
+			}
+			if err := ports.CheckUDP(ch.SRTHost, ch.SRTPort); err != nil {
+				check.Status = HealthDegraded
+				check.Message = fmt.Sprintf("puerto SRT %d no disponible: %v", ch.SRTPort, err)
+			}
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// checkWritable verifica que un archivo (o su directorio, si no existe) es escribible
+func checkWritable(name, path string) HealthCheck {
+	check := HealthCheck{Name: name, Status: HealthHealthy, Message: path}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err == nil {
+		f.Close()
+	} else if !os.IsNotExist(err) {
+		check.Status = HealthDegraded
+		check.Message = fmt.Sprintf("%s no escribible: %v", path, err)
+		return check
+	}
+
+	// La escritura atómica crea un temporal junto al archivo: verificar el directorio
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".healthcheck-*")
+	if err != nil {
+		check.Status = HealthDegraded
+		check.Message = fmt.Sprintf("directorio de %s no escribible: %v", path, err)
+		return check
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	return check
+}
+
+// aggregateHealth calcula el estado global (el peor de las verificaciones)
+func aggregateHealth(checks []HealthCheck) string {
+	status := HealthHealthy
+	for _, c := range checks {
+		switch c.Status {
+		case HealthUnhealthy:
+			return HealthUnhealthy
+		case HealthDegraded:
+			status = HealthDegraded
+		}
+	}
+	return status
+}
+
+// writeHealth escribe el reporte (503 si no está sano)
+func writeHealth(w http.ResponseWriter, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == HealthUnhealthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
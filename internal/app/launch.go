@@ -0,0 +1,56 @@
+package app
+
+import "sync"
+
+// launchTracker cuenta los lanzamientos de FFmpeg en curso por canal. Entre la
+// transición a starting y el registro del proceso (reemplazo del anterior,
+// prueba del encoder de hardware) el canal no tiene proceso: monitorChannels no
+// debe darlo por fallido.
+type launchTracker struct {
+	mutex    sync.Mutex
+	inFlight map[string]int
+	started  map[string]uint64 // Lanzamientos iniciados por canal
+}
+
+// begin registra el inicio de un lanzamiento (llamar antes de la transición a starting)
+func (t *launchTracker) begin(channelID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.inFlight == nil {
+		t.inFlight = make(map[string]int)
+		t.started = make(map[string]uint64)
+	}
+	t.inFlight[channelID]++
+	t.started[channelID]++
+}
+
+// end registra el fin de un lanzamiento (con o sin éxito)
+func (t *launchTracker) end(channelID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.inFlight[channelID]--; t.inFlight[channelID] <= 0 {
+		delete(t.inFlight, channelID)
+	}
+}
+
+// state indica si hay un lanzamiento en curso y cuántos se iniciaron
+func (t *launchTracker) state(channelID string) (bool, uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.inFlight[channelID] > 0, t.started[channelID]
+}
+
+// launchFailed indica si un canal en starting se quedó sin proceso: no hay un
+// lanzamiento en curso, ni empezó otro mientras se comprobaba, y FFmpeg no está
+// corriendo (terminó antes de estar listo)
+func (a *App) launchFailed(channelID string) bool {
+	busy, started := a.launches.state(channelID)
+	if busy || a.ffmpegManager.IsRunning(channelID) {
+		return false
+	}
+	busy, again := a.launches.state(channelID)
+	return !busy && again == started
+}
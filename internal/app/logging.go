@@ -0,0 +1,170 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"servidor-stream/internal/config"
+	"servidor-stream/internal/logfile"
+)
+
+// logFileFields campos de configuración que reabren el log en disco
+var logFileFields = map[string]bool{
+	"logPath":        true,
+	"logToFile":      true,
+	"logFormat":      true,
+	"logMaxSizeMB":   true,
+	"logRotateHours": true,
+	"logMaxFiles":    true,
+	"logMaxAgeDays":  true,
+}
+
+// unsafeFileChars caracteres que no se usan en nombres de archivo de log
+var unsafeFileChars = regexp.MustCompile(`[^\p{L}\p{N}_-]+`)
+
+// fileLogEntry entrada del log en disco en formato JSON (una por línea)
+type fileLogEntry struct {
+	Time         string `json:"time"` // RFC 3339 con milisegundos
+	Level        string `json:"level"`
+	Subsystem    string `json:"subsystem"`
+	Message      string `json:"message"`
+	ChannelID    string `json:"channelId,omitempty"`
+	ChannelLabel string `json:"channelLabel,omitempty"`
+	ClientID     string `json:"clientId,omitempty"`
+}
+
+// logFileName nombre del log de la aplicación (una por instancia)
+func logFileName() string {
+	if name := config.Instance(); name != "" {
+		return "servidor-stream-" + name + ".log"
+	}
+	return "servidor-stream.log"
+}
+
+// logOptions rotación y retención configuradas
+func (a *App) logOptions() logfile.Options {
+	cfg := a.cfg()
+	return logfile.Options{
+		MaxSize:    int64(cfg.LogMaxSizeMB) << 20,
+		Interval:   time.Duration(cfg.LogRotateHours) * time.Hour,
+		MaxBackups: cfg.LogMaxFiles,
+		MaxAge:     time.Duration(cfg.LogMaxAgeDays) * 24 * time.Hour,
+	}
+}
+
+// openLogFile abre el log en disco según la configuración actual (al iniciar y
+// tras cambiar los campos de logs). Cierra el anterior.
+func (a *App) openLogFile() {
+	cfg := a.cfg()
+	var writer *logfile.Writer
+	var openErr error
+	if cfg.LogToFile {
+		path := filepath.Join(config.GetLogDir(cfg.LogPath), logFileName())
+		writer, openErr = logfile.Open(path, a.logOptions())
+	}
+
+	a.logFileMutex.Lock()
+	previous := a.logFile
+	a.logFile = writer
+	a.logFileFormat = cfg.LogFormat
+	a.logFileMutex.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	if openErr != nil {
+		a.AddLog("ERROR", fmt.Sprintf("No se puede abrir el log en disco: %v", openErr), "")
+	} else if writer != nil && (previous == nil || previous.Path() != writer.Path()) {
+		a.AddLog("INFO", fmt.Sprintf("Log en disco: %s", writer.Path()), "")
+	}
+}
+
+// closeLogFile cierra el log en disco
+func (a *App) closeLogFile() {
+	a.logFileMutex.Lock()
+	defer a.logFileMutex.Unlock()
+
+	if a.logFile != nil {
+		a.logFile.Close()
+		a.logFile = nil
+	}
+}
+
+// writeLogFile escribe una entrada en el log en disco (si está habilitado)
+func (a *App) writeLogFile(entry LogEntry, at time.Time) {
+	a.logFileMutex.Lock()
+	defer a.logFileMutex.Unlock()
+
+	if a.logFile == nil {
+		return
+	}
+
+	var line []byte
+	if a.logFileFormat == "json" {
+		data, err := json.Marshal(fileLogEntry{
+			Time:         at.Format("2006-01-02T15:04:05.000Z07:00"),
+			Level:        entry.Level,
+			Subsystem:    entry.Subsystem,
+			Message:      entry.Message,
+			ChannelID:    entry.ChannelID,
+			ChannelLabel: entry.ChannelLabel,
+			ClientID:     entry.ClientID,
+		})
+		if err != nil {
+			return
+		}
+		line = append(data, '\n')
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s [%s]", at.Format("2006-01-02 15:04:05.000"), entry.Level)
+		if entry.ChannelID != "" {
+			label := entry.ChannelLabel
+			if label == "" {
+				label = entry.ChannelID
+			}
+			fmt.Fprintf(&b, " [canal %s]", label)
+		}
+		if entry.ClientID != "" {
+			fmt.Fprintf(&b, " [cliente %s]", entry.ClientID)
+		}
+		fmt.Fprintf(&b, " %s\n", entry.Message)
+		line = []byte(b.String())
+	}
+
+	if _, err := a.logFile.Write(line); err != nil {
+		log.Printf("[Log] Error escribiendo %s: %v", a.logFile.Path(), err)
+	}
+}
+
+// openFFmpegLog destino del stderr de FFmpeg de un canal:
+// <logs>/ffmpeg/<etiqueta>-<id>.log, con la misma rotación que el log principal
+func (a *App) openFFmpegLog(channelID string) io.WriteCloser {
+	cfg := a.cfg()
+	if !cfg.FFmpegLogs {
+		return nil
+	}
+
+	name := channelID
+	if len(name) > 8 {
+		name = name[:8]
+	}
+	if ch, err := a.channelManager.Get(channelID); err == nil {
+		if label := strings.Trim(unsafeFileChars.ReplaceAllString(ch.Label, "_"), "_"); label != "" {
+			name = label + "-" + name
+		}
+	}
+
+	path := filepath.Join(config.GetLogDir(cfg.LogPath), "ffmpeg", name+".log")
+	writer, err := logfile.Open(path, a.logOptions())
+	if err != nil {
+		a.logTo(subsystemFFmpeg, "WARNING", fmt.Sprintf("No se puede guardar el log de FFmpeg: %v", err), channelID)
+		return nil
+	}
+	return writer
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"servidor-stream/internal/config"
+	"servidor-stream/internal/websocket"
+)
+
+// Subsistemas del log. Cada uno tiene su nivel mínimo (logLevel<Subsistema> en
+// la configuración, vacío = logLevel).
+const (
+	subsystemApp       = "app"
+	subsystemWebSocket = "websocket"
+	subsystemFFmpeg    = "ffmpeg"
+	subsystemChannel   = "channel"
+)
+
+// logLevels niveles de las entradas del log, de menor a mayor gravedad
+var logLevels = []string{"TRACE", "DEBUG", "INFO", "WARNING", "ERROR"}
+
+// defaultLogLevel nivel mínimo mientras no se cargó la configuración
+const defaultLogLevel = "INFO"
+
+// normalizeLogLevel nombre de un nivel como aparece en las entradas. Acepta
+// minúsculas y "warn" (configuración). Vacío si no es un nivel.
+func normalizeLogLevel(level string) string {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if level == "WARN" {
+		level = "WARNING"
+	}
+	if slices.Contains(logLevels, level) {
+		return level
+	}
+	return ""
+}
+
+// configLogLevel nombre de un nivel en la configuración (trace, ..., warn, error)
+func configLogLevel(level string) string {
+	if level = normalizeLogLevel(level); level == "WARNING" {
+		return "warn"
+	}
+	return strings.ToLower(level)
+}
+
+// logLevelRank gravedad de un nivel (los desconocidos cuentan como INFO)
+func logLevelRank(level string) int {
+	if i := slices.Index(logLevels, normalizeLogLevel(level)); i >= 0 {
+		return i
+	}
+	return slices.Index(logLevels, defaultLogLevel)
+}
+
+// LogLevels niveles de log vigentes
+type LogLevels struct {
+	Default    string            `json:"default"`    // logLevel
+	Subsystems map[string]string `json:"subsystems"` // Nivel efectivo de cada subsistema
+}
+
+// applyLogLevels actualiza los niveles mínimos con la configuración actual (al
+// iniciar y al cambiar la configuración)
+func (a *App) applyLogLevels() {
+	thresholds := make(map[string]int, len(config.LogSubsystems))
+	for _, subsystem := range config.LogSubsystems {
+		thresholds[subsystem] = logLevelRank(a.cfg().SubsystemLogLevel(subsystem))
+	}
+
+	a.logMutex.Lock()
+	a.logThresholds = thresholds
+	a.logMutex.Unlock()
+}
+
+// logEnabled indica si se registran las entradas de ese nivel en el
+// subsistema. Sirve para no formatear mensajes que se descartarían.
+func (a *App) logEnabled(subsystem, level string) bool {
+	a.logMutex.RLock()
+	threshold, ok := a.logThresholds[subsystem]
+	a.logMutex.RUnlock()
+
+	if !ok {
+		threshold = logLevelRank(defaultLogLevel)
+	}
+	return logLevelRank(level) >= threshold
+}
+
+// GetLogLevels niveles de log de cada subsistema
+func (a *App) GetLogLevels() LogLevels {
+	cfg := a.cfg()
+	levels := LogLevels{
+		Default:    cfg.LogLevel,
+		Subsystems: make(map[string]string, len(config.LogSubsystems)),
+	}
+	for _, subsystem := range config.LogSubsystems {
+		levels.Subsystems[subsystem] = cfg.SubsystemLogLevel(subsystem)
+	}
+	return levels
+}
+
+// SetLogLevel cambia el nivel de un subsistema (subsystem vacío = logLevel, el
+// de todos los que no tienen uno propio) y lo guarda en la configuración. Con
+// level vacío el subsistema vuelve a usar logLevel.
+func (a *App) SetLogLevel(subsystem, level string) (*LogLevels, error) {
+	levels, err := a.setLogLevel(subsystem, level, "ajustes")
+	a.auditUI("SetLogLevel", "", map[string]string{"subsystem": subsystem, "level": level}, err)
+	return levels, err
+}
+
+// setLogLevel aplica y guarda un nivel de log igual que un cambio desde Ajustes
+func (a *App) setLogLevel(subsystem, level, source string) (*LogLevels, error) {
+	if level != "" {
+		if level = configLogLevel(level); level == "" {
+			return nil, fmt.Errorf("nivel inválido (%s)", strings.Join(config.LogLevels, ", "))
+		}
+	}
+
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
+	cfg := *a.cfg()
+	field, name := &cfg.LogLevel, "logLevel"
+	if subsystem != "" {
+		if field, name = cfg.LogLevelField(subsystem), config.LogLevelFieldName(subsystem); field == nil {
+			return nil, fmt.Errorf("subsistema desconocido %q (%s)", subsystem, strings.Join(config.LogSubsystems, ", "))
+		}
+	} else if level == "" {
+		return nil, fmt.Errorf("logLevel no puede quedar vacío")
+	}
+	if origin, ok := config.Overridden()[name]; ok {
+		return nil, fmt.Errorf("%s está impuesto por %s", name, origin)
+	}
+	*field = level
+
+	if err := config.Save(&cfg); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error guardando configuración: %v", err), "")
+		return nil, err
+	}
+	a.configStamp = statConfig()
+	a.applyConfig(&cfg, source)
+
+	levels := a.GetLogLevels()
+	return &levels, nil
+}
+
+// handleGetLogLevelsRequest niveles de log actuales
+func (a *App) handleGetLogLevelsRequest() []byte {
+	return websocket.SuccessResponse("log_levels", a.GetLogLevels())
+}
+
+// handleSetLogLevelRequest cambia un nivel de log (parameters.subsystem y
+// parameters.level). Afecta a todos los clientes: requiere ser administrador.
+func (a *App) handleSetLogLevelRequest(clientID string, msg websocket.Message) []byte {
+	if err := a.authorizeAdmin(clientID); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	subsystem, _, err := msg.StringParam("subsystem")
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	level, _, err := msg.StringParam("level")
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+
+	levels, err := a.setLogLevel(subsystem, level, "cliente "+a.clientName(clientID))
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	return websocket.SuccessResponse("log_levels", levels)
+}
@@ -0,0 +1,325 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"servidor-stream/internal/config"
+	"servidor-stream/internal/websocket"
+)
+
+// Límites de las consultas de logs
+const (
+	defaultLogQueryLimit = 100
+	maxLogQueryLimit     = 1000
+)
+
+// LogQuery filtros de una consulta o suscripción al log
+type LogQuery struct {
+	Level     string    `json:"level,omitempty"`     // Nivel mínimo (vacío = todos)
+	Subsystem string    `json:"subsystem,omitempty"` // app, websocket, ffmpeg o channel
+	Channel   string    `json:"channel,omitempty"`   // ID o etiqueta del canal
+	ClientID  string    `json:"clientId,omitempty"`  // Cliente WebSocket que originó la entrada
+	Since     time.Time `json:"since,omitempty"`
+	Until     time.Time `json:"until,omitempty"`
+	Text      string    `json:"text,omitempty"`     // Texto contenido en el mensaje (sin distinguir mayúsculas)
+	AfterSeq  uint64    `json:"afterSeq,omitempty"` // Solo entradas posteriores a esta secuencia
+	Limit     int       `json:"limit,omitempty"`
+	Offset    int       `json:"offset,omitempty"` // Entradas a saltar desde la más reciente
+}
+
+// LogPage resultado de una consulta: las entradas de la página en orden
+// cronológico. Offset 0 es la página más reciente.
+type LogPage struct {
+	Entries []LogEntry `json:"entries"`
+	Total   int        `json:"total"`   // Entradas que cumplen los filtros
+	Offset  int        `json:"offset"`  // Offset aplicado
+	Limit   int        `json:"limit"`   // Límite aplicado
+	HasMore bool       `json:"hasMore"` // Hay entradas más antiguas (siguiente offset)
+	LastSeq uint64     `json:"lastSeq"` // Secuencia de la última entrada del buffer (para afterSeq)
+}
+
+// normalize valida los filtros y completa los valores por defecto
+func (q *LogQuery) normalize() error {
+	if q.Level != "" {
+		level := normalizeLogLevel(q.Level)
+		if level == "" {
+			return fmt.Errorf("level inválido %q (%s)", q.Level, strings.Join(logLevels, ", "))
+		}
+		q.Level = level
+	}
+	if q.Subsystem != "" && !slices.Contains(config.LogSubsystems, q.Subsystem) {
+		return fmt.Errorf("subsystem inválido %q (%s)", q.Subsystem, strings.Join(config.LogSubsystems, ", "))
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return fmt.Errorf("limit y offset no pueden ser negativos")
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultLogQueryLimit
+	}
+	if q.Limit > maxLogQueryLimit {
+		q.Limit = maxLogQueryLimit
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && q.Until.Before(q.Since) {
+		return fmt.Errorf("until es anterior a since")
+	}
+	return nil
+}
+
+// matches indica si una entrada cumple los filtros (salvo afterSeq y paginación)
+func (q *LogQuery) matches(entry LogEntry) bool {
+	if q.Level != "" && logLevelRank(entry.Level) < logLevelRank(q.Level) {
+		return false
+	}
+	if q.Subsystem != "" && entry.Subsystem != q.Subsystem {
+		return false
+	}
+	if q.Channel != "" && entry.ChannelID != q.Channel && !strings.EqualFold(entry.ChannelLabel, q.Channel) {
+		return false
+	}
+	if q.ClientID != "" && entry.ClientID != q.ClientID {
+		return false
+	}
+	if !q.Since.IsZero() && entry.at.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && entry.at.After(q.Until) {
+		return false
+	}
+	if q.Text != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(q.Text)) {
+		return false
+	}
+	return true
+}
+
+// query recorre el buffer desde la entrada más reciente. Las entradas están en
+// orden de secuencia y de tiempo, así que afterSeq y since cortan el recorrido.
+func (r *logRing) query(q LogQuery) LogPage {
+	page := LogPage{
+		Entries: []LogEntry{},
+		Offset:  q.Offset,
+		Limit:   q.Limit,
+		LastSeq: r.lastSeq,
+	}
+
+	for i := r.len() - 1; i >= 0; i-- {
+		entry := r.at(i)
+		if entry.Seq <= q.AfterSeq || (!q.Since.IsZero() && entry.at.Before(q.Since)) {
+			break
+		}
+		if !q.matches(entry) {
+			continue
+		}
+		if page.Total >= q.Offset && len(page.Entries) < q.Limit {
+			page.Entries = append(page.Entries, entry)
+		}
+		page.Total++
+	}
+
+	slices.Reverse(page.Entries)
+	page.HasMore = q.Offset+len(page.Entries) < page.Total
+	return page
+}
+
+// QueryLogs consulta el buffer de logs con filtros y paginación
+func (a *App) QueryLogs(q LogQuery) (*LogPage, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+
+	a.logMutex.RLock()
+	page := a.logBuffer.query(q)
+	a.logMutex.RUnlock()
+	return &page, nil
+}
+
+// parseLogTime acepta RFC 3339 o la fecha local de las entradas (2006-01-02 15:04:05)
+func parseLogTime(key, value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s debe ser una fecha RFC 3339 o 'AAAA-MM-DD hh:mm:ss'", key)
+}
+
+// logQueryFromValues lee los filtros de los parámetros de /api/logs
+func logQueryFromValues(get func(key string) string) (LogQuery, error) {
+	q := LogQuery{
+		Level:     get("level"),
+		Subsystem: get("subsystem"),
+		Channel:   get("channel"),
+		ClientID:  get("client"),
+		Text:      get("text"),
+	}
+
+	var err error
+	for key, field := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		if value := get(key); value != "" {
+			if *field, err = parseLogTime(key, value); err != nil {
+				return q, err
+			}
+		}
+	}
+	for key, field := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		if value := get(key); value != "" {
+			if *field, err = strconv.Atoi(value); err != nil {
+				return q, fmt.Errorf("%s debe ser un número entero", key)
+			}
+		}
+	}
+	if value := get("afterSeq"); value != "" {
+		if q.AfterSeq, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return q, fmt.Errorf("afterSeq debe ser un número entero")
+		}
+	}
+	return q, q.normalize()
+}
+
+// logQueryFromMessage lee los filtros de parameters de un mensaje WebSocket
+func logQueryFromMessage(msg websocket.Message) (LogQuery, error) {
+	var q LogQuery
+	for key, field := range map[string]*string{
+		"level":     &q.Level,
+		"subsystem": &q.Subsystem,
+		"channel":   &q.Channel,
+		"client":    &q.ClientID,
+		"text":      &q.Text,
+	} {
+		value, _, err := msg.StringParam(key)
+		if err != nil {
+			return q, err
+		}
+		*field = value
+	}
+	for key, field := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		value, _, err := msg.StringParam(key)
+		if err != nil {
+			return q, err
+		}
+		if value != "" {
+			if *field, err = parseLogTime(key, value); err != nil {
+				return q, err
+			}
+		}
+	}
+	for key, field := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		value, _, err := msg.IntParam(key)
+		if err != nil {
+			return q, err
+		}
+		*field = value
+	}
+	afterSeq, _, err := msg.IntParam("afterSeq")
+	if err != nil || afterSeq < 0 {
+		return q, fmt.Errorf("parameters.afterSeq debe ser un número entero positivo")
+	}
+	q.AfterSeq = uint64(afterSeq)
+	return q, q.normalize()
+}
+
+// handleLogsAPI endpoint REST de consulta de logs (GET /api/logs, requiere el
+// token de administración)
+func (a *App) handleLogsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !a.requireAdminToken(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]string{"error": "método no permitido"})
+		return
+	}
+
+	q, err := logQueryFromValues(r.URL.Query().Get)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	page, _ := a.QueryLogs(q)
+	json.NewEncoder(w).Encode(page)
+}
+
+// handleGetLogsRequest consulta el log (mismos filtros que /api/logs). Solo
+// para administradores.
+func (a *App) handleGetLogsRequest(clientID string, msg websocket.Message) []byte {
+	if err := a.authorizeReader(clientID); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	q, err := logQueryFromMessage(msg)
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	page, _ := a.QueryLogs(q)
+	return websocket.SuccessResponse("logs", page)
+}
+
+// handleSubscribeLogsRequest envía al cliente cada entrada nueva que cumpla los
+// filtros (acción "log") hasta unsubscribe_logs o la desconexión. Una nueva
+// suscripción reemplaza los filtros de la anterior. Solo para administradores.
+func (a *App) handleSubscribeLogsRequest(clientID string, msg websocket.Message) []byte {
+	if err := a.authorizeReader(clientID); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	q, err := logQueryFromMessage(msg)
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+
+	a.logMutex.Lock()
+	a.logSubscribers[clientID] = q
+	lastSeq := a.logBuffer.lastSeq
+	a.logMutex.Unlock()
+
+	return websocket.SuccessResponse("logs_subscribed", map[string]interface{}{
+		"filters": q,
+		"lastSeq": lastSeq, // Las entradas anteriores se consultan con get_logs
+	})
+}
+
+// handleUnsubscribeLogsRequest cancela la suscripción al log
+func (a *App) handleUnsubscribeLogsRequest(clientID string) []byte {
+	a.unsubscribeLogs(clientID)
+	return websocket.SuccessResponse("logs_unsubscribed", nil)
+}
+
+// unsubscribeLogs cancela la suscripción de un cliente (al desconectarse)
+func (a *App) unsubscribeLogs(clientID string) {
+	a.logMutex.Lock()
+	delete(a.logSubscribers, clientID)
+	a.logMutex.Unlock()
+}
+
+// logRecipients clientes suscritos a los que corresponde una entrada. Llamar
+// con logMutex tomado.
+func (a *App) logRecipients(entry LogEntry) []string {
+	var recipients []string
+	for clientID, q := range a.logSubscribers {
+		if entry.Seq > q.AfterSeq && q.matches(entry) {
+			recipients = append(recipients, clientID)
+		}
+	}
+	return recipients
+}
+
+// pushLog envía una entrada a los clientes suscritos. Un cliente lento pierde
+// entradas en lugar de bloquear el log; puede recuperarlas con get_logs.
+func (a *App) pushLog(entry LogEntry, recipients []string) {
+	if a.wsServer == nil || len(recipients) == 0 {
+		return
+	}
+	message := websocket.SuccessResponse("log", entry)
+	for _, clientID := range recipients {
+		a.wsServer.SendToClient(clientID, message)
+	}
+}
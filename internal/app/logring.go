@@ -0,0 +1,83 @@
+package app
+
+// defaultLogLines capacidad del buffer de logs si maxLogLines no es válido
+const defaultLogLines = 1000
+
+// logRing buffer circular de capacidad fija con las entradas más recientes del
+// log. Agregar una entrada no mueve las demás: al llenarse, la nueva reemplaza
+// a la más antigua. No es seguro para uso concurrente (lo protege logMutex).
+type logRing struct {
+	entries []LogEntry
+	start   int    // Índice de la entrada más antigua
+	count   int    // Entradas ocupadas
+	lastSeq uint64 // Número de secuencia de la última entrada agregada
+}
+
+// newLogRing crea un buffer con la capacidad indicada
+func newLogRing(capacity int) *logRing {
+	if capacity <= 0 {
+		capacity = defaultLogLines
+	}
+	return &logRing{entries: make([]LogEntry, capacity)}
+}
+
+// push agrega una entrada asignándole el siguiente número de secuencia
+func (r *logRing) push(entry LogEntry) LogEntry {
+	r.lastSeq++
+	entry.Seq = r.lastSeq
+
+	capacity := len(r.entries)
+	if r.count < capacity {
+		r.entries[(r.start+r.count)%capacity] = entry
+		r.count++
+	} else {
+		r.entries[r.start] = entry
+		r.start = (r.start + 1) % capacity
+	}
+	return entry
+}
+
+// at entrada i, contando desde la más antigua (0 <= i < len)
+func (r *logRing) at(i int) LogEntry {
+	return r.entries[(r.start+i)%len(r.entries)]
+}
+
+// len cantidad de entradas
+func (r *logRing) len() int {
+	return r.count
+}
+
+// snapshot copia de las entradas, de la más antigua a la más reciente
+func (r *logRing) snapshot() []LogEntry {
+	out := make([]LogEntry, r.count)
+	for i := range out {
+		out[i] = r.at(i)
+	}
+	return out
+}
+
+// resize cambia la capacidad conservando las entradas más recientes
+func (r *logRing) resize(capacity int) {
+	if capacity <= 0 {
+		capacity = defaultLogLines
+	}
+	if capacity == len(r.entries) {
+		return
+	}
+
+	kept := r.snapshot()
+	if len(kept) > capacity {
+		kept = kept[len(kept)-capacity:]
+	}
+	r.entries = make([]LogEntry, capacity)
+	copy(r.entries, kept)
+	r.start = 0
+	r.count = len(kept)
+}
+
+// clear descarta las entradas (la secuencia continúa)
+func (r *logRing) clear() {
+	clear(r.entries)
+	r.start = 0
+	r.count = 0
+}
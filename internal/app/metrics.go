@@ -0,0 +1,86 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/metrics"
+)
+
+// allStatuses estados posibles de un canal (para el gauge one-hot de estado)
+var allStatuses = []channel.Status{
+	channel.StatusInactive,
+	channel.StatusStarting,
+	channel.StatusActive,
+	channel.StatusStopping,
+	channel.StatusError,
+}
+
+// handleMetrics expone métricas en formato Prometheus en /metrics
+func (a *App) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	status := metrics.NewGauge("srtstream_channel_status", "Estado del canal (1 para el estado actual)")
+	running := metrics.NewGauge("srtstream_channel_running", "Proceso FFmpeg del canal en ejecución")
+	fps := metrics.NewGauge("srtstream_channel_fps", "Frames por segundo codificados")
+	bitrate := metrics.NewGauge("srtstream_channel_bitrate_kbps", "Bitrate de salida en kbit/s")
+	frames := metrics.NewCounter("srtstream_channel_frames_total", "Frames codificados por el proceso actual")
+	bytesOut := metrics.NewCounter("srtstream_channel_output_bytes_total", "Bytes de salida del proceso actual")
+	dropped := metrics.NewCounter("srtstream_channel_dropped_frames_total", "Frames descartados por el proceso actual")
+	dup := metrics.NewCounter("srtstream_channel_dup_frames_total", "Frames duplicados por el proceso actual")
+	restarts := metrics.NewCounter("srtstream_channel_restarts_total", "Reinicios automáticos del canal")
+	errorsTotal := metrics.NewCounter("srtstream_channel_errors_total", "Errores registrados en el canal")
+	srtConnections := metrics.NewGauge("srtstream_channel_srt_connections", "Receptores SRT conectados")
+	uptime := metrics.NewGauge("srtstream_channel_uptime_seconds", "Segundos desde el inicio del proceso FFmpeg")
+	channelsTotal := metrics.NewGauge("srtstream_channels", "Canales configurados")
+	channelsActive := metrics.NewGauge("srtstream_channels_active", "Canales activos")
+
+	channels := a.channelManager.GetAll()
+	channelsTotal.Add(float64(len(channels)))
+	channelsActive.Add(float64(a.channelManager.ActiveCount()))
+
+	for _, ch := range channels {
+		labels := []string{"channel_id", ch.ID, "label", ch.Label}
+
+		for _, s := range allStatuses {
+			status.Add(metrics.Bool(ch.Status == s), append(labels, "status", string(s))...)
+		}
+		errorsTotal.Add(float64(ch.Stats.ErrorCount), labels...)
+
+		info, err := a.ffmpegManager.GetProcessInfo(ch.ID)
+		if err != nil {
+			running.Add(0, labels...)
+			srtConnections.Add(0, labels...)
+			restarts.Add(0, labels...)
+			continue
+		}
+
+		running.Add(metrics.Bool(info.IsRunning), labels...)
+		fps.Add(info.Progress.FPS, labels...)
+		bitrate.Add(info.Progress.BitrateKbps(), labels...)
+		frames.Add(float64(info.Progress.Frame), labels...)
+		bytesOut.Add(float64(info.Progress.TotalSize), labels...)
+		dropped.Add(float64(info.Progress.DropFrames), labels...)
+		dup.Add(float64(info.Progress.DupFrames), labels...)
+		restarts.Add(float64(info.RestartCount), labels...)
+		srtConnections.Add(metrics.Bool(info.SRTConnected), labels...)
+		uptime.Add(time.Since(info.StartTime).Seconds(), labels...)
+	}
+
+	wsStats := a.wsServer.GetStats()
+	wsClients := metrics.NewGauge("srtstream_websocket_clients", "Clientes de control conectados (WebSocket y Socket.IO)")
+	wsClients.Add(float64(wsStats.Clients))
+	sioSessions := metrics.NewGauge("srtstream_socketio_sessions", "Sesiones Engine.IO abiertas")
+	sioSessions.Add(float64(wsStats.SocketIOSessions))
+	wsReceived := metrics.NewCounter("srtstream_websocket_messages_received_total", "Mensajes recibidos de clientes de control")
+	wsReceived.Add(float64(wsStats.MessagesReceived))
+	wsSent := metrics.NewCounter("srtstream_websocket_messages_sent_total", "Mensajes enviados a clientes de control")
+	wsSent.Add(float64(wsStats.MessagesSent))
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	metrics.Write(w, []*metrics.Family{
+		channelsTotal, channelsActive,
+		status, running, fps, bitrate, frames, bytesOut, dropped, dup,
+		restarts, errorsTotal, srtConnections, uptime,
+		wsClients, sioSessions, wsReceived, wsSent,
+	})
+}
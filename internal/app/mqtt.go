@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"servidor-stream/internal/mqttbridge"
+)
+
+// startMQTT conecta el bridge MQTT y arranca la publicación periódica
+func (a *App) startMQTT(ctx context.Context) {
+	cfg := a.cfg()
+	a.mqttBridge = mqttbridge.NewBridge(mqttbridge.Options{
+		Broker:      cfg.MQTTBroker,
+		ClientID:    cfg.MQTTClientID,
+		Username:    cfg.MQTTUsername,
+		Password:    cfg.MQTTPassword,
+		TopicPrefix: cfg.MQTTTopicPrefix,
+	}, a.handleWebSocketMessage)
+
+	if err := a.mqttBridge.Start(); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error conectando a broker MQTT %s: %v", cfg.MQTTBroker, err), "")
+	} else {
+		a.AddLog("INFO", fmt.Sprintf("Bridge MQTT habilitado: %s (prefijo %s)", cfg.MQTTBroker, cfg.MQTTTopicPrefix), "")
+	}
+
+	go a.publishMQTTLoop(ctx)
+}
+
+// publishMQTTLoop publica periódicamente estado, estadísticas y clientes
+func (a *App) publishMQTTLoop(ctx context.Context) {
+	interval := time.Duration(a.cfg().MQTTStatsInterval) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, ch := range a.channelManager.GetAll() {
+			a.publishMQTTChannel(ch.ID)
+			a.publishMQTTStats(ch.ID)
+		}
+		a.publishMQTTClients()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishMQTTChannel publica el estado retenido de un canal
+func (a *App) publishMQTTChannel(channelID string) {
+	if a.mqttBridge == nil {
+		return
+	}
+	ch, err := a.channelManager.Get(channelID)
+	if err != nil {
+		return
+	}
+	a.mqttBridge.PublishChannel(ch.ID, ch)
+}
+
+// publishMQTTStats publica las estadísticas retenidas de un canal
+func (a *App) publishMQTTStats(channelID string) {
+	if a.mqttBridge == nil {
+		return
+	}
+	ch, err := a.channelManager.Get(channelID)
+	if err != nil {
+		return
+	}
+
+	stats := map[string]interface{}{
+		"channelId": ch.ID,
+		"label":     ch.Label,
+		"status":    ch.Status,
+		"stats":     ch.Stats,
+		"running":   false,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	if info, err := a.ffmpegManager.GetProcessInfo(ch.ID); err == nil {
+		stats["running"] = info.IsRunning
+		stats["pid"] = info.PID
+		stats["uptime"] = time.Since(info.StartTime).Seconds()
+		stats["progress"] = info.Progress
+		stats["restartCount"] = info.RestartCount
+	}
+
+	a.mqttBridge.PublishStats(ch.ID, stats)
+}
+
+// publishMQTTClients publica la lista retenida de clientes conectados
+func (a *App) publishMQTTClients() {
+	if a.mqttBridge == nil || a.wsServer == nil {
+		return
+	}
+	a.mqttBridge.PublishClients(a.wsServer.GetClients())
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"servidor-stream/internal/osc"
+)
+
+// Direcciones OSC soportadas:
+//
+//	/channel/{label}/play [ruta]   reproduce un video (sin ruta: inicia el video del canal)
+//	/channel/{label}/stop          detiene el canal
+//	/channel/{label}/pattern       reproduce el patrón de prueba
+//	/channel/{label}/status        responde /channel/{label}/status <estado> <archivo>
+//	/status                        responde el estado de todos los canales
+//	/stop_all                      detiene todos los streams
+//
+// {label} acepta también el ID del canal. Los errores se responden al remitente
+// como /error <dirección> <mensaje>.
+
+// startOSC inicia el listener OSC con los destinos de feedback configurados
+func (a *App) startOSC(ctx context.Context) {
+	cfg := a.cfg()
+	a.oscServer = osc.NewServer(cfg.OSCPort, a.handleOSCMessage)
+	if err := a.oscServer.SetFeedbackTargets(cfg.OSCFeedbackTargets); err != nil {
+		a.AddLog("WARNING", err.Error(), "")
+	}
+
+	go func() {
+		if err := a.oscServer.Start(ctx); err != nil {
+			a.AddLog("ERROR", fmt.Sprintf("Error iniciando servidor OSC en puerto %d: %v", cfg.OSCPort, err), "")
+		}
+	}()
+
+	a.AddLog("INFO", fmt.Sprintf("Control OSC habilitado en puerto UDP %d", cfg.OSCPort), "")
+}
+
+// handleOSCMessage mapea mensajes OSC a operaciones de la aplicación
+func (a *App) handleOSCMessage(msg osc.Message) {
+	a.AddLog("DEBUG", fmt.Sprintf("OSC [%s] %s %v", msg.Source, msg.Address, msg.Args), "")
+
+	parts := strings.Split(strings.Trim(msg.Address, "/"), "/")
+
+	switch {
+	case msg.Address == "/status":
+		for _, ch := range a.channelManager.GetAll() {
+			a.oscServer.SendTo(msg.Source, "/channel/"+ch.Label+"/status", string(ch.Status), ch.CurrentFile)
+		}
+		return
+	case msg.Address == "/stop_all":
+		a.auditOSC(msg, "stop_all", nil, a.stopAllStreams())
+		return
+	case len(parts) < 3 || parts[0] != "channel":
+		a.oscError(msg, "dirección OSC desconocida")
+		return
+	}
+
+	// El label puede contener "/" - todo entre /channel/ y el comando
+	label := strings.Join(parts[1:len(parts)-1], "/")
+	command := parts[len(parts)-1]
+
+	ch := a.findChannel(label)
+	if ch == nil {
+		a.oscError(msg, fmt.Sprintf("canal '%s' no encontrado", label))
+		return
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("OSC [%s] %s en canal %s", msg.Source, command, ch.Label), ch.ID)
+
+	var err error
+	switch command {
+	case "play":
+		if path := msg.String(0); path != "" {
+			err = a.playVideoOnChannel(ch.ID, path)
+		} else {
+			err = a.manualStart(ch.ID)
+		}
+	case "stop":
+		err = a.stopChannel(ch.ID)
+	case "pattern":
+		err = a.playTestPattern(ch.ID)
+	case "status":
+		a.oscServer.SendTo(msg.Source, "/channel/"+ch.Label+"/status", string(ch.Status), ch.CurrentFile)
+	default:
+		err = fmt.Errorf("comando OSC desconocido: %s", command)
+	}
+	if command != "status" {
+		a.auditOSC(msg, command, ch, err)
+	}
+
+	if err != nil {
+		a.oscError(msg, err.Error())
+	}
+}
+
+// oscError responde un error al remitente de un mensaje OSC
+func (a *App) oscError(msg osc.Message, errorMessage string) {
+	a.AddLog("ERROR", fmt.Sprintf("OSC %s: %s", msg.Address, errorMessage), "")
+	if msg.Source != nil {
+		a.oscServer.SendTo(msg.Source, "/error", msg.Address, errorMessage)
+	}
+}
+
+// sendOSCStatus envía el feedback de estado de un canal a los destinos OSC
+func (a *App) sendOSCStatus(data map[string]interface{}) {
+	if a.oscServer == nil {
+		return
+	}
+
+	channelID, _ := data["channelId"].(string)
+	ch, err := a.channelManager.Get(channelID)
+	if err != nil {
+		return
+	}
+
+	currentFile, _ := data["currentFile"].(string)
+	if currentFile == "" {
+		currentFile = ch.CurrentFile
+	}
+
+	a.oscServer.Send("/channel/"+ch.Label+"/status", fmt.Sprint(data["status"]), currentFile)
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/ports"
+	"servidor-stream/internal/websocket"
+)
+
+// portRanges rangos de puertos SRT configurados (default si son inválidos)
+func (a *App) portRanges() []ports.Range {
+	ranges, err := ports.ParseRanges(a.cfg().SRTPortRanges)
+	if err != nil {
+		a.AddLog("WARNING", fmt.Sprintf("srtPortRanges inválido (%v), usando %s", err, ports.FormatRanges(ports.DefaultRanges)), "")
+		return ports.DefaultRanges
+	}
+	return ranges
+}
+
+// SetChannelSRTPort asigna manualmente el puerto SRT de un canal
+func (a *App) SetChannelSRTPort(channelID string, port int) error {
+	ch, err := a.setChannelSRTPort(channelID, port)
+	a.auditUI("SetChannelSRTPort", channelID, map[string]int{"port": port}, err)
+	if err != nil {
+		return err
+	}
+
+	a.notifyChannelUpdated(ch, "")
+	return nil
+}
+
+// setChannelSRTPort asigna el puerto sin notificar
+func (a *App) setChannelSRTPort(channelID string, port int) (*channel.Channel, error) {
+	if err := a.channelManager.SetSRTPort(channelID, port); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("No se pudo asignar el puerto SRT %d: %v", port, err), channelID)
+		return nil, err
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("Puerto SRT actualizado: %d", port), channelID)
+	return a.channelManager.Get(channelID)
+}
+
+// portErrorCode código de error WebSocket para un fallo de asignación de puerto
+func portErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ports.ErrInvalidPort):
+		return "invalid_port"
+	case errors.Is(err, ports.ErrPortTaken), errors.Is(err, ports.ErrPortUnavailable), errors.Is(err, ports.ErrNoFreePort):
+		return "port_in_use"
+	}
+	return "port_error"
+}
+
+// handleSetPortRequest cambia el puerto SRT de un canal (parameters.port)
+func (a *App) handleSetPortRequest(clientID string, msg websocket.Message) []byte {
+	ch := a.findChannel(msg.ChannelID)
+	if ch == nil {
+		return websocket.ErrorResponse("channel_not_found", fmt.Sprintf("Canal '%s' no encontrado", msg.ChannelID))
+	}
+	if err := a.authorizeChannel(clientID, ch); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	port, ok, err := msg.IntParam("port")
+	if !ok || err != nil {
+		return websocket.ErrorResponse("invalid_port", "Se requiere parameters.port (número entero)")
+	}
+
+	updated, err := a.setChannelSRTPort(ch.ID, port)
+	if err != nil {
+		return websocket.ErrorResponse(portErrorCode(err), err.Error())
+	}
+	a.notifyChannelUpdated(updated, clientID)
+
+	return websocket.SuccessResponse("port_updated", map[string]interface{}{
+		"channelId": ch.ID,
+		"srtPort":   port,
+	})
+}
@@ -0,0 +1,398 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/config"
+	"servidor-stream/internal/ports"
+	"servidor-stream/internal/preset"
+	"servidor-stream/internal/storage"
+	"servidor-stream/internal/websocket"
+)
+
+// PresetActivation resultado de activar un preset
+type PresetActivation struct {
+	Name      string        `json:"name"`
+	Created   []string      `json:"created"`   // Etiquetas de los canales creados
+	Updated   []string      `json:"updated"`   // Canales modificados (se detuvieron si estaban emitiendo)
+	Removed   []string      `json:"removed"`   // Canales que no están en el preset
+	Unchanged []string      `json:"unchanged"` // Canales que ya coincidían (siguen emitiendo)
+	Warnings  []string      `json:"warnings"`
+	Config    *ConfigUpdate `json:"config"`
+}
+
+// ListPresets retorna los presets guardados
+func (a *App) ListPresets() ([]preset.Summary, error) {
+	summaries, err := a.presets.List()
+	if err != nil {
+		a.AddLog("WARNING", fmt.Sprintf("Presets ilegibles: %v", err), "")
+	}
+	return summaries, nil
+}
+
+// SavePreset guarda los canales actuales y los parámetros de emisión como
+// preset (reemplaza el que tenga el mismo nombre). Los canales automáticos de
+// clientes no se incluyen.
+func (a *App) SavePreset(name, description string) (*preset.Summary, error) {
+	summary, err := a.savePreset(name, description)
+	a.auditUI("SavePreset", "", map[string]string{"name": name, "description": description}, err)
+	return summary, err
+}
+
+// savePreset guarda el preset sin auditar
+func (a *App) savePreset(name, description string) (*preset.Summary, error) {
+	p, err := a.snapshotPreset(strings.TrimSpace(name), strings.TrimSpace(description))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.presets.Save(p); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error guardando preset '%s': %v", p.Name, err), "")
+		return nil, err
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("Preset '%s' guardado con %d canales", p.Name, len(p.Channels)), "")
+	summary := p.Summary()
+	return &summary, nil
+}
+
+// DeletePreset elimina un preset guardado
+func (a *App) DeletePreset(name string) error {
+	err := a.presets.Delete(name)
+	a.auditUI("DeletePreset", "", map[string]string{"name": name}, err)
+	if err != nil {
+		return err
+	}
+	a.AddLog("INFO", fmt.Sprintf("Preset '%s' eliminado", name), "")
+	return nil
+}
+
+// ActivatePreset cambia al preset indicado (ver activatePreset)
+func (a *App) ActivatePreset(name string) (*PresetActivation, error) {
+	activation, err := a.activatePreset(name, "")
+	a.auditUI("ActivatePreset", "", map[string]string{"name": name}, err)
+	return activation, err
+}
+
+// ExportPreset guarda un preset en el archivo que elija el usuario (ruta vacía
+// si se cancela)
+func (a *App) ExportPreset(name string) (string, error) {
+	path, err := a.exportPreset(name)
+	if err != nil || path != "" { // Cancelar el diálogo no es una acción
+		a.auditUI("ExportPreset", "", map[string]string{"name": name, "path": path}, err)
+	}
+	return path, err
+}
+
+// exportPreset exporta el preset sin auditar
+func (a *App) exportPreset(name string) (string, error) {
+	p, err := a.presets.Get(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := preset.Encode(p)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Exportar preset",
+		DefaultFilename: p.Name + ".json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Presets (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if err := storage.WriteFileAtomic(path, data, 0644); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error exportando preset '%s': %v", p.Name, err), "")
+		return "", err
+	}
+	a.AddLog("INFO", fmt.Sprintf("Preset '%s' exportado a %s", p.Name, path), "")
+	return path, nil
+}
+
+// ImportPreset importa un preset exportado (nil si se cancela). Si ya existe
+// uno con el mismo nombre se guarda como <nombre>-2, <nombre>-3...
+func (a *App) ImportPreset() (*preset.Summary, error) {
+	summary, path, err := a.importPreset()
+	if err != nil || summary != nil { // Cancelar el diálogo no es una acción
+		params := map[string]string{"path": path}
+		if summary != nil {
+			params["name"] = summary.Name
+		}
+		a.auditUI("ImportPreset", "", params, err)
+	}
+	return summary, err
+}
+
+// importPreset importa el preset sin auditar (devuelve también el archivo)
+func (a *App) importPreset() (*preset.Summary, string, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Importar preset",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Presets (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return nil, path, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, path, err
+	}
+	p, err := preset.Decode(data)
+	if err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error importando preset %s: %v", path, err), "")
+		return nil, path, err
+	}
+
+	p.Name = a.presets.UniqueName(p.Name)
+	if err := a.presets.Save(p); err != nil {
+		return nil, path, err
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("Preset '%s' importado de %s", p.Name, path), "")
+	summary := p.Summary()
+	return &summary, path, nil
+}
+
+// snapshotPreset preset con los canales y la configuración actuales
+func (a *App) snapshotPreset(name, description string) (*preset.Preset, error) {
+	if err := preset.ValidateName(name); err != nil {
+		return nil, err
+	}
+	values, err := a.cfg().PresetValues()
+	if err != nil {
+		return nil, err
+	}
+
+	channels := a.channelManager.GetAll()
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].CreatedAt.Before(channels[j].CreatedAt)
+	})
+
+	p := &preset.Preset{
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+		Channels:    []channel.Spec{},
+		Config:      values,
+	}
+	for _, ch := range channels {
+		if ch.Ephemeral {
+			continue
+		}
+		if slices.ContainsFunc(p.Channels, func(s channel.Spec) bool { return s.Label == ch.Label }) {
+			return nil, fmt.Errorf("hay dos canales llamados '%s': renombre uno antes de guardar el preset", ch.Label)
+		}
+		p.Channels = append(p.Channels, ch.Spec())
+	}
+	return p, nil
+}
+
+// activatePreset deja los canales y los parámetros de emisión como en el
+// preset. Los canales se emparejan por etiqueta: los que ya coinciden siguen
+// emitiendo, los modificados se detienen y se ajustan, los que faltan se crean
+// y los que no están en el preset se eliminan (salvo los canales automáticos de
+// clientes). Si un puerto del preset no está disponible se asigna otro y se
+// avisa en Warnings. Las activaciones se ejecutan de una en una.
+func (a *App) activatePreset(name, originID string) (*PresetActivation, error) {
+	a.presetMutex.Lock()
+	defer a.presetMutex.Unlock()
+
+	p, err := a.presets.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validar la configuración resultante antes de tocar nada
+	cfg, err := a.cfg().WithPresetValues(p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("preset %s: %w", p.Name, err)
+	}
+	cfg.ActivePreset = p.Name
+	config.ApplyOverrides(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("preset %s: %w", p.Name, err)
+	}
+
+	result := &PresetActivation{
+		Name:      p.Name,
+		Created:   []string{},
+		Updated:   []string{},
+		Removed:   []string{},
+		Unchanged: []string{},
+		Warnings:  []string{},
+	}
+	warn := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		result.Warnings = append(result.Warnings, msg)
+		a.AddLog("WARNING", fmt.Sprintf("Preset '%s': %s", p.Name, msg), "")
+	}
+
+	wanted := map[string]bool{}
+	for _, spec := range p.Channels {
+		wanted[spec.Label] = true
+	}
+
+	// Eliminar primero los canales que sobran para liberar sus puertos
+	existing := map[string]channel.Channel{}
+	for _, ch := range a.channelManager.GetAll() {
+		if ch.Ephemeral {
+			continue
+		}
+		if _, dup := existing[ch.Label]; wanted[ch.Label] && !dup {
+			existing[ch.Label] = ch
+			continue
+		}
+		if err := a.removeChannel(ch.ID); err != nil {
+			warn("no se pudo eliminar %s: %v", ch.Label, err)
+			continue
+		}
+		a.notifyChannelRemoved(&ch, originID)
+		result.Removed = append(result.Removed, ch.Label)
+	}
+
+	// Crear o ajustar los del preset. Un puerto ocupado por otro canal del
+	// preset (intercambio de puertos) se reintenta al final.
+	var retry []channel.Spec
+	for _, spec := range p.Channels {
+		current, exists := existing[spec.Label]
+		if exists && current.Matches(spec) {
+			result.Unchanged = append(result.Unchanged, spec.Label)
+			continue
+		}
+
+		ch, err := a.applyPresetChannel(current.ID, exists, spec)
+		if isPortError(err) {
+			fallback := spec
+			fallback.SRTPort = 0
+			if ch, err = a.applyPresetChannel(current.ID, exists, fallback); err == nil {
+				retry = append(retry, spec)
+			}
+		}
+		if err != nil {
+			warn("canal %s: %v", spec.Label, err)
+			continue
+		}
+
+		if exists {
+			a.notifyChannelUpdated(ch, originID)
+			result.Updated = append(result.Updated, spec.Label)
+		} else {
+			a.notifyChannelAdded(ch, originID)
+			result.Created = append(result.Created, spec.Label)
+		}
+	}
+
+	for _, spec := range retry {
+		ch := a.channelManager.GetByLabel(spec.Label)
+		if ch == nil {
+			continue
+		}
+		if err := a.channelManager.SetSRTPort(ch.ID, spec.SRTPort); err != nil {
+			warn("canal %s: puerto %d no disponible (%v), usa el %d", spec.Label, spec.SRTPort, err, ch.SRTPort)
+			continue
+		}
+		a.notifyChannelUpdated(ch, originID)
+	}
+
+	// Parámetros de emisión: igual que al guardar desde Ajustes
+	a.configMutex.Lock()
+	if err := config.Save(cfg); err != nil {
+		a.AddLog("ERROR", fmt.Sprintf("Error guardando configuración: %v", err), "")
+		warn("configuración no guardada: %v", err)
+	}
+	a.configStamp = statConfig()
+	result.Config = a.applyConfig(cfg, "preset "+p.Name)
+	a.configMutex.Unlock()
+
+	a.AddLog("INFO", fmt.Sprintf("Preset '%s' activado: %d creados, %d modificados, %d eliminados, %d sin cambios",
+		p.Name, len(result.Created), len(result.Updated), len(result.Removed), len(result.Unchanged)), "")
+	runtime.EventsEmit(a.ctx, "preset:activated", result)
+	a.pushToClients("preset_activated", result, originID)
+
+	return result, nil
+}
+
+// applyPresetChannel crea el canal del preset o ajusta el existente (deteniéndolo
+// si está emitiendo)
+func (a *App) applyPresetChannel(channelID string, exists bool, spec channel.Spec) (*channel.Channel, error) {
+	if !exists {
+		ch, err := a.channelManager.AddSpec(spec)
+		if err == nil {
+			a.AddLog("INFO", fmt.Sprintf("Canal agregado: %s (%s)", ch.Label, ch.ID), ch.ID)
+		}
+		return ch, err
+	}
+
+	if ch, err := a.channelManager.Get(channelID); err == nil && ch.Status != channel.StatusInactive {
+		a.stopChannel(channelID)
+	}
+	ch, err := a.channelManager.ApplySpec(channelID, spec)
+	if err == nil {
+		a.AddLog("INFO", fmt.Sprintf("Canal ajustado al preset: %s", ch.Label), channelID)
+	}
+	return ch, err
+}
+
+// isPortError indica si el error se debe a un puerto no disponible
+func isPortError(err error) bool {
+	return errors.Is(err, ports.ErrPortTaken) || errors.Is(err, ports.ErrPortUnavailable)
+}
+
+// authorizeAdmin comprueba si un cliente remoto puede gestionar todos los
+// canales a la vez (presets, ver isChannelAdmin)
+func (a *App) authorizeAdmin(clientID string) error {
+	if !a.cfg().WSChannelManagement {
+		return errors.New("la gestión de canales por WebSocket está deshabilitada")
+	}
+	if a.isChannelAdmin(clientID) {
+		return nil
+	}
+	return fmt.Errorf("el cliente '%s' no es administrador", a.clientName(clientID))
+}
+
+// handleListPresetsRequest lista los presets guardados
+func (a *App) handleListPresetsRequest(clientID string) []byte {
+	summaries, _ := a.ListPresets()
+	return websocket.SuccessResponse("presets_list", map[string]interface{}{
+		"presets": summaries,
+		"active":  a.cfg().ActivePreset,
+	})
+}
+
+// handleActivatePresetRequest activa un preset (parameters.name)
+func (a *App) handleActivatePresetRequest(clientID string, msg websocket.Message) []byte {
+	if err := a.authorizeAdmin(clientID); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	name, _, err := msg.StringParam("name")
+	if err != nil || name == "" {
+		return websocket.ErrorResponse("invalid_parameters", "Se requiere parameters.name")
+	}
+
+	result, err := a.activatePreset(name, clientID)
+	if errors.Is(err, preset.ErrNotFound) {
+		return websocket.ErrorResponse("preset_not_found", err.Error())
+	}
+	if err != nil {
+		return websocket.ErrorResponse("preset_error", err.Error())
+	}
+	a.addClientLog("INFO", fmt.Sprintf("Preset '%s' activado por el cliente %s", result.Name, a.clientName(clientID)), "", clientID)
+
+	return websocket.SuccessResponse("preset_activated", result)
+}
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/websocket"
+)
+
+// clientName nombre del cliente WebSocket (el ID si ya no está conectado)
+func (a *App) clientName(clientID string) string {
+	if a.wsServer != nil {
+		if info, ok := a.wsServer.GetClient(clientID); ok {
+			return info.Name
+		}
+	}
+	return clientID
+}
+
+// isChannelAdmin indica si un cliente puede gestionar todos los canales: los
+// clientes WebSocket que presentaron al conectar el token configurado en
+// wsAdminToken. Sin token configurado no hay administradores remotos. Los
+// comandos MQTT (mqttbridge.ClientID) no vienen de un cliente conectado y nunca
+// lo son.
+func (a *App) isChannelAdmin(clientID string) bool {
+	return a.wsServer != nil && a.wsServer.HasToken(clientID, a.cfg().WSAdminToken)
+}
+
+// authorizeReader comprueba si un cliente remoto puede consultar datos internos
+// (log, auditoría): solo los administradores, aunque la gestión de canales por
+// WebSocket esté desactivada
+func (a *App) authorizeReader(clientID string) error {
+	if a.isChannelAdmin(clientID) {
+		return nil
+	}
+	return fmt.Errorf("el cliente '%s' no es administrador", a.clientName(clientID))
+}
+
+// requireAdminToken equivalente a authorizeReader para los endpoints REST: el
+// token de wsAdminToken va en "Authorization: Bearer <token>" o en ?token=. Si
+// falta o no coincide responde 401 y retorna false.
+func (a *App) requireAdminToken(w http.ResponseWriter, r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+
+	expected := a.cfg().WSAdminToken
+	if expected != "" && token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+		return true
+	}
+
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": "se requiere el token de administración (wsAdminToken)"})
+	return false
+}
+
+// authorizeChannel comprueba si un cliente remoto puede gestionar un canal
+// (ch == nil para crear uno nuevo). Los administradores gestionan todos y el
+// resto solo los suyos (por clientKey). El clientKey lo elige el cliente al
+// conectar: separa los canales de cada cliente, pero no es autenticación.
+func (a *App) authorizeChannel(clientID string, ch *channel.Channel) error {
+	if !a.cfg().WSChannelManagement {
+		return errors.New("la gestión de canales por WebSocket está deshabilitada")
+	}
+	if a.isChannelAdmin(clientID) {
+		return nil
+	}
+
+	name := a.clientName(clientID)
+	if ch == nil || (ch.Owner != "" && ch.Owner == a.clientKey(clientID)) {
+		return nil
+	}
+
+	return fmt.Errorf("el cliente '%s' no tiene permiso sobre el canal '%s'", name, ch.Label)
+}
+
+// labelTaken indica si otro canal ya usa la etiqueta (se usa para buscar canales)
+func (a *App) labelTaken(label, exceptID string) bool {
+	other := a.channelManager.GetByLabel(label)
+	return other != nil && other.ID != exceptID
+}
+
+// notifyChannelAdded avisa del canal nuevo al frontend y a los clientes
+// remotos, salvo al que originó el cambio (ya recibe la respuesta)
+func (a *App) notifyChannelAdded(ch *channel.Channel, originID string) {
+	runtime.EventsEmit(a.ctx, "channel:added", ch)
+	a.pushToClients("channel_created", ch, originID)
+}
+
+// notifyChannelUpdated avisa de un canal modificado
+func (a *App) notifyChannelUpdated(ch *channel.Channel, originID string) {
+	runtime.EventsEmit(a.ctx, "channel:updated", ch)
+	a.pushToClients("channel_updated", ch, originID)
+}
+
+// notifyChannelRemoved avisa de un canal eliminado
+func (a *App) notifyChannelRemoved(ch *channel.Channel, originID string) {
+	runtime.EventsEmit(a.ctx, "channel:removed", ch.ID)
+	a.pushToClients("channel_deleted", map[string]interface{}{
+		"channelId": ch.ID,
+		"label":     ch.Label,
+	}, originID)
+}
+
+// pushToClients envía una notificación a los clientes WebSocket
+func (a *App) pushToClients(action string, data interface{}, originID string) {
+	if a.wsServer == nil {
+		return
+	}
+	a.wsServer.BroadcastExcept(originID, websocket.SuccessResponse(action, data))
+}
+
+// channelErrorCode código de error WebSocket para un fallo al crear o editar un canal
+func channelErrorCode(err error, fallback string) string {
+	if errors.Is(err, channel.ErrStreamNameInUse) {
+		return "stream_name_conflict"
+	}
+	return fallback
+}
+
+// outputParams aplica los parámetros de salida presentes en el mensaje sobre base
+func outputParams(msg websocket.Message, base channel.OutputSettings) (channel.OutputSettings, error) {
+	output := base
+	for key, field := range map[string]*string{
+		"resolution":  &output.Resolution,
+		"scaleMode":   &output.ScaleMode,
+		"interlace":   &output.Interlace,
+		"pixelFormat": &output.PixelFormat,
+	} {
+		if value, ok, err := msg.StringParam(key); err != nil {
+			return output, err
+		} else if ok {
+			*field = value
+		}
+	}
+	for key, field := range map[string]*int{
+		"frameRate":     &output.FrameRate,
+		"audioChannels": &output.AudioChannels,
+	} {
+		if value, ok, err := msg.IntParam(key); err != nil {
+			return output, err
+		} else if ok {
+			*field = value
+		}
+	}
+
+	return output, output.Validate()
+}
+
+// handleCreateChannelRequest crea un canal con nombre (parameters.label). El
+// cliente queda como propietario.
+func (a *App) handleCreateChannelRequest(clientID string, msg websocket.Message) []byte {
+	if err := a.authorizeChannel(clientID, nil); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	label, _, err := msg.StringParam("label")
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	if label == "" {
+		return websocket.ErrorResponse("invalid_parameters", "Se requiere parameters.label")
+	}
+	streamName, _, err := msg.StringParam("srtStreamName")
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	port, hasPort, err := msg.IntParam("srtPort")
+	if err != nil {
+		return websocket.ErrorResponse("invalid_port", err.Error())
+	}
+
+	// Validar todo antes de crear nada
+	output, err := outputParams(msg, channel.DefaultOutput())
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	if a.labelTaken(label, "") {
+		return websocket.ErrorResponse("label_conflict", fmt.Sprintf("Ya existe un canal '%s'", label))
+	}
+
+	ch, err := a.addChannel(label, streamName)
+	if err != nil {
+		return websocket.ErrorResponse(channelErrorCode(err, "create_error"), err.Error())
+	}
+	a.channelManager.SetOwner(ch.ID, a.clientKey(clientID))
+
+	if hasPort {
+		if _, err := a.setChannelSRTPort(ch.ID, port); err != nil {
+			a.removeChannel(ch.ID)
+			return websocket.ErrorResponse(portErrorCode(err), err.Error())
+		}
+	}
+	created, err := a.updateChannelOutput(ch.ID, output)
+	if err != nil {
+		a.removeChannel(ch.ID)
+		return websocket.ErrorResponse("create_error", err.Error())
+	}
+	ch = created
+
+	a.addClientLog("INFO", fmt.Sprintf("Canal '%s' creado por el cliente %s", ch.Label, a.clientName(clientID)), ch.ID, clientID)
+	a.notifyChannelAdded(ch, clientID)
+
+	return websocket.SuccessResponse("channel_created", ch)
+}
+
+// handleUpdateChannelRequest edita un canal: nombre, stream y parámetros de salida.
+// Solo cambian los parámetros presentes en el mensaje.
+func (a *App) handleUpdateChannelRequest(clientID string, msg websocket.Message) []byte {
+	ch := a.findChannel(msg.ChannelID)
+	if ch == nil {
+		return websocket.ErrorResponse("channel_not_found", fmt.Sprintf("Canal '%s' no encontrado", msg.ChannelID))
+	}
+	if err := a.authorizeChannel(clientID, ch); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	label, _, err := msg.StringParam("label")
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	streamName, _, err := msg.StringParam("srtStreamName")
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+
+	// Parámetros de salida sobre los valores actuales del canal; validar todo
+	// antes de modificar nada
+	output, err := outputParams(msg, ch.Output())
+	if err != nil {
+		return websocket.ErrorResponse("invalid_parameters", err.Error())
+	}
+	if label != "" && a.labelTaken(label, ch.ID) {
+		return websocket.ErrorResponse("label_conflict", fmt.Sprintf("Ya existe un canal '%s'", label))
+	}
+
+	if label != "" || streamName != "" {
+		if _, err := a.updateChannel(ch.ID, label, streamName); err != nil {
+			return websocket.ErrorResponse(channelErrorCode(err, "update_error"), err.Error())
+		}
+	}
+	updated, err := a.updateChannelOutput(ch.ID, output)
+	if err != nil {
+		return websocket.ErrorResponse("update_error", err.Error())
+	}
+	a.notifyChannelUpdated(updated, clientID)
+
+	return websocket.SuccessResponse("channel_updated", updated)
+}
+
+// handleDeleteChannelRequest detiene y elimina un canal
+func (a *App) handleDeleteChannelRequest(clientID string, msg websocket.Message) []byte {
+	ch := a.findChannel(msg.ChannelID)
+	if ch == nil {
+		return websocket.ErrorResponse("channel_not_found", fmt.Sprintf("Canal '%s' no encontrado", msg.ChannelID))
+	}
+	if err := a.authorizeChannel(clientID, ch); err != nil {
+		return websocket.ErrorResponse("forbidden", err.Error())
+	}
+
+	if err := a.removeChannel(ch.ID); err != nil {
+		return websocket.ErrorResponse("delete_error", err.Error())
+	}
+	a.notifyChannelRemoved(ch, clientID)
+
+	return websocket.SuccessResponse("channel_deleted", map[string]interface{}{
+		"channelId": ch.ID,
+		"label":     ch.Label,
+	})
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/ffmpeg"
+	"servidor-stream/internal/restart"
+	"servidor-stream/internal/webhook"
+)
+
+// restartPolicy construye la política de reinicio desde la configuración
+func (a *App) restartPolicy() restart.Policy {
+	cfg := a.cfg()
+	policy := restart.DefaultPolicy()
+	policy.MaxAttempts = cfg.RestartMaxAttempts
+	if cfg.RestartInitialDelay > 0 {
+		policy.InitialDelay = time.Duration(cfg.RestartInitialDelay) * time.Second
+	}
+	if cfg.RestartMaxDelay > 0 {
+		policy.MaxDelay = time.Duration(cfg.RestartMaxDelay) * time.Second
+	}
+	policy.ResetWindow = time.Duration(cfg.RestartResetWindow) * time.Second
+	return policy
+}
+
+// scheduleRestart aplica la política de reinicio tras un fallo del canal
+func (a *App) scheduleRestart(channelID, reason string) {
+	delay, attempt, seq, ok := a.restarts.Failure(channelID)
+	a.syncRestartState(channelID)
+
+	if !ok {
+		a.openCircuit(channelID, attempt, reason)
+		return
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("Reinicio automático %d programado en %v", attempt, delay.Round(100*time.Millisecond)), channelID)
+	go a.attemptRestart(channelID, attempt, seq, delay, reason)
+}
+
+// openCircuit notifica que el canal agotó sus reintentos
+func (a *App) openCircuit(channelID string, attempts int, reason string) {
+	label := channelID
+	if ch, err := a.channelManager.Get(channelID); err == nil {
+		label = ch.Label
+	}
+
+	message := fmt.Sprintf("Canal %s: %d reinicios fallidos, reinicio automático suspendido. Inicie el canal manualmente para reanudar.", label, attempts)
+	a.AddLog("ERROR", message, channelID)
+
+	a.dispatchWebhook(webhook.EventCircuitOpen, ffmpeg.Event{
+		Type:      ffmpeg.EventError,
+		ChannelID: channelID,
+		Message:   message,
+		Data: map[string]interface{}{
+			"attempts":  attempts,
+			"lastError": reason,
+		},
+	})
+	a.emitChannelStatus(map[string]interface{}{
+		"channelId": channelID,
+		"status":    channel.StatusError,
+		"event":     "circuit_open",
+		"message":   message,
+		"restart":   a.restarts.State(channelID),
+	})
+}
+
+// attemptRestart espera el retardo de la política y reinicia el canal
+// Solo reinicia si el canal sigue en error, hay un archivo para reproducir y
+// el reinicio no fue cancelado por una acción manual
+func (a *App) attemptRestart(channelID string, attempt int, seq uint64, delay time.Duration, reason string) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-a.ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	if !a.restarts.Due(channelID, seq) {
+		return
+	}
+
+	ch, err := a.channelManager.Get(channelID)
+	if err != nil {
+		return
+	}
+
+	record := restart.Attempt{
+		Attempt: attempt,
+		DelayMs: delay.Milliseconds(),
+		Reason:  reason,
+	}
+
+	// No reiniciar si no está en error o si no hay archivo configurado
+	if ch.Status != channel.StatusError {
+		return
+	}
+
+	// Verificar que hay un archivo para reproducir
+	inputPath, _ := ch.Source()
+
+	if inputPath == "" {
+		a.AddLog("INFO", fmt.Sprintf("Canal %s en error pero sin archivo para reiniciar. Use 'Patrón' o configure un video.", ch.Label), channelID)
+		record.Result = restart.ResultSkipped
+		record.Error = "sin archivo para reproducir"
+		a.recordRestart(channelID, record)
+		return
+	}
+
+	// Verificar que el archivo existe antes de reintentar
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		a.AddLog("ERROR", fmt.Sprintf("Archivo no encontrado para reinicio: %s", inputPath), channelID)
+		record.Result = restart.ResultSkipped
+		record.Error = "archivo no encontrado: " + inputPath
+		a.recordRestart(channelID, record)
+		return
+	}
+
+	a.AddLog("INFO", fmt.Sprintf("Intentando reiniciar canal %s (intento %d)", ch.Label, attempt), channelID)
+	err = a.startChannel(channelID)
+
+	record.Result = restart.ResultOK
+	if err != nil {
+		record.Result = restart.ResultFailed
+		record.Error = err.Error()
+	}
+	a.recordRestart(channelID, record)
+
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	a.dispatchWebhook(webhook.EventRestartAttempted, ffmpeg.Event{
+		Type:      ffmpeg.EventWarning,
+		ChannelID: channelID,
+		Message:   fmt.Sprintf("Reinicio automático de %s", ch.Label),
+		Data: map[string]interface{}{
+			"inputPath": inputPath,
+			"result":    result,
+			"attempt":   attempt,
+			"delayMs":   delay.Milliseconds(),
+		},
+	})
+
+	// Un fallo al lanzar FFmpeg no pasa por onFFmpegEvent: aplicar la política aquí
+	if err != nil {
+		a.scheduleRestart(channelID, err.Error())
+	}
+}
+
+// recordRestart registra un intento en el historial y lo publica
+func (a *App) recordRestart(channelID string, record restart.Attempt) {
+	a.restarts.Record(channelID, record)
+	state := a.syncRestartState(channelID)
+	if state != nil && record.Result == restart.ResultOK {
+		a.ffmpegManager.SetRestartCount(channelID, state.Count)
+	}
+	runtime.EventsEmit(a.ctx, "channel:restart", map[string]interface{}{
+		"channelId": channelID,
+		"attempt":   record,
+		"restart":   state,
+	})
+}
+
+// resetRestart cierra el circuito y cancela reinicios pendientes (acción manual)
+func (a *App) resetRestart(channelID string) {
+	a.restarts.Reset(channelID)
+	a.syncRestartState(channelID)
+}
+
+// syncRestartState copia el estado de reinicio al canal (visible en status y UI)
+func (a *App) syncRestartState(channelID string) *restart.State {
+	state := a.restarts.State(channelID)
+	a.channelManager.SetRestartState(channelID, state)
+	return state
+}
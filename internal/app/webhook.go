@@ -0,0 +1,48 @@
+package app
+
+import (
+	"servidor-stream/internal/ffmpeg"
+	"servidor-stream/internal/webhook"
+)
+
+// webhookTargets convierte los destinos configurados al formato del dispatcher
+func (a *App) webhookTargets() []webhook.Target {
+	cfg := a.cfg()
+	targets := make([]webhook.Target, 0, len(cfg.Webhooks))
+	for _, t := range cfg.Webhooks {
+		targets = append(targets, webhook.Target{
+			URL:    t.URL,
+			Secret: t.Secret,
+			Events: t.Events,
+		})
+	}
+	return targets
+}
+
+// dispatchWebhook envía un webhook derivado de un evento FFmpeg
+func (a *App) dispatchWebhook(name string, event ffmpeg.Event) {
+	if a.webhooks == nil {
+		return
+	}
+
+	payload := webhook.Payload{
+		Event:     name,
+		ChannelID: event.ChannelID,
+		Message:   event.Message,
+		Data:      event.Data,
+	}
+	if payload.Data == nil {
+		payload.Data = map[string]interface{}{}
+	}
+	payload.Data["ffmpegEvent"] = string(event.Type)
+
+	if ch, err := a.channelManager.Get(event.ChannelID); err == nil {
+		payload.ChannelLabel = ch.Label
+		payload.Data["srtPort"] = ch.SRTPort
+		if ch.CurrentFile != "" {
+			payload.Data["currentFile"] = ch.CurrentFile
+		}
+	}
+
+	a.webhooks.Dispatch(payload)
+}
@@ -0,0 +1,271 @@
+// Package audit registra las acciones de control (interfaz, clientes
+// WebSocket, OSC) en archivos JSON lines de solo anexado, uno por mes, y
+// permite consultarlas por rango de tiempo, canal o cliente.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// monthFormat sufijo de los archivos (audit-<mes>.jsonl)
+const monthFormat = "2006-01"
+
+// Límites de las consultas
+const (
+	DefaultLimit = 500
+	MaxLimit     = 5000
+)
+
+// Orígenes de las acciones
+const (
+	SourceUI        = "ui"
+	SourceWebSocket = "websocket"
+	SourceOSC       = "osc"
+	SourceMQTT      = "mqtt"
+)
+
+// Entry acción registrada
+type Entry struct {
+	Time         time.Time       `json:"time"`
+	Source       string          `json:"source"` // ui, websocket, osc, mqtt
+	Action       string          `json:"action"`
+	ClientID     string          `json:"clientId,omitempty"`
+	ClientName   string          `json:"clientName,omitempty"`
+	RemoteAddr   string          `json:"remoteAddr,omitempty"`
+	ChannelID    string          `json:"channelId,omitempty"`
+	ChannelLabel string          `json:"channelLabel,omitempty"`
+	Params       json.RawMessage `json:"params,omitempty"`
+	Success      bool            `json:"success"`
+	ErrorCode    string          `json:"errorCode,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// Query filtros de una consulta (los vacíos no filtran)
+type Query struct {
+	Since   time.Time `json:"since,omitempty"`
+	Until   time.Time `json:"until,omitempty"`
+	Channel string    `json:"channel,omitempty"` // ID o etiqueta del canal
+	Client  string    `json:"client,omitempty"`  // ID o nombre del cliente
+	Source  string    `json:"source,omitempty"`
+	Action  string    `json:"action,omitempty"`
+	Limit   int       `json:"limit,omitempty"` // Entradas más recientes que se devuelven
+}
+
+// Page resultado de una consulta, en orden cronológico
+type Page struct {
+	Entries   []Entry `json:"entries"`
+	Total     int     `json:"total"`     // Entradas que cumplen los filtros
+	Truncated bool    `json:"truncated"` // Total supera el límite: faltan las más antiguas
+}
+
+// matches indica si una entrada cumple los filtros
+func (q *Query) matches(e *Entry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+	if q.Channel != "" && e.ChannelID != q.Channel && !strings.EqualFold(e.ChannelLabel, q.Channel) {
+		return false
+	}
+	if q.Client != "" && e.ClientID != q.Client && !strings.EqualFold(e.ClientName, q.Client) {
+		return false
+	}
+	if q.Source != "" && e.Source != q.Source {
+		return false
+	}
+	if q.Action != "" && e.Action != q.Action {
+		return false
+	}
+	return true
+}
+
+// Log registro de auditoría. Es seguro usarlo desde varias goroutines.
+type Log struct {
+	dir   string
+	mutex sync.Mutex
+	file  *os.File
+	month string // Mes del archivo abierto
+}
+
+// Open prepara el registro en dir (lo crea si no existe)
+func Open(dir string) (*Log, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Log{dir: dir}, nil
+}
+
+// Dir directorio de los archivos
+func (l *Log) Dir() string {
+	return l.dir
+}
+
+// path archivo de un mes
+func (l *Log) path(month string) string {
+	return filepath.Join(l.dir, "audit-"+month+".jsonl")
+}
+
+// Record agrega una entrada al archivo del mes (Time vacío = ahora). Cada
+// entrada se escribe con una sola escritura en modo append.
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	month := e.Time.Format(monthFormat)
+	if l.file == nil || l.month != month {
+		if l.file != nil {
+			l.file.Close()
+			l.file = nil
+		}
+		file, err := openAppend(l.path(month))
+		if err != nil {
+			return err
+		}
+		l.file, l.month = file, month
+	}
+
+	_, err = l.file.Write(data)
+	return err
+}
+
+// openAppend abre un archivo en modo append. Si la última línea quedó cortada
+// (cierre inesperado) la termina, para no mezclarla con la siguiente entrada.
+func openAppend(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			file.Write([]byte{'\n'})
+		}
+	}
+	return file, nil
+}
+
+// Query lee las entradas que cumplen los filtros. Solo se leen los archivos de
+// los meses del rango.
+func (l *Log) Query(q Query) (*Page, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+
+	files, err := l.files(q.Since, q.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Entries: []Entry{}}
+	for _, path := range files {
+		if err := scanFile(path, func(e *Entry) {
+			if !q.matches(e) {
+				return
+			}
+			page.Total++
+			page.Entries = append(page.Entries, *e)
+			if len(page.Entries) == 2*q.Limit {
+				// Conservar las más recientes sin acumular todo el rango
+				page.Entries = append(page.Entries[:0], page.Entries[q.Limit:]...)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if len(page.Entries) > q.Limit {
+		page.Entries = page.Entries[len(page.Entries)-q.Limit:]
+	}
+
+	// Las entradas se escriben en orden, salvo escrituras concurrentes del mismo instante
+	sort.SliceStable(page.Entries, func(i, j int) bool {
+		return page.Entries[i].Time.Before(page.Entries[j].Time)
+	})
+	page.Truncated = page.Total > len(page.Entries)
+	return page, nil
+}
+
+// files archivos de los meses que se solapan con [since, until], en orden
+func (l *Log) files(since, until time.Time) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(l.dir, "audit-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "audit-"), ".jsonl")
+		start, err := time.ParseInLocation(monthFormat, name, time.Local)
+		if err != nil {
+			continue
+		}
+		if !since.IsZero() && !start.AddDate(0, 1, 0).After(since) {
+			continue
+		}
+		if !until.IsZero() && start.After(until) {
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files) // AAAA-MM ordena cronológicamente
+	return files, nil
+}
+
+// scanFile recorre las entradas de un archivo. Las líneas ilegibles (ej: una
+// escritura cortada por un cierre inesperado) se saltan.
+func scanFile(path string, fn func(*Entry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		fn(&e)
+	}
+	return scanner.Err()
+}
+
+// Close cierra el archivo abierto (Record lo reabre si hace falta)
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -1,14 +1,17 @@
 package channel
 
 import (
-	"encoding/json"
 	"errors"
-	"os"
-	"path/filepath"
+	"fmt"
+	"log"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
+	"servidor-stream/internal/config"
+	"servidor-stream/internal/ports"
+	"servidor-stream/internal/restart"
 )
 
 // Status representa el estado de un canal
@@ -22,6 +25,51 @@ const (
 	StatusStopping Status = "stopping"
 )
 
+// ErrInvalidTransition transición de estado no permitida
+var ErrInvalidTransition = errors.New("transición de estado inválida")
+
+// ErrStreamNameInUse el nombre de stream SRT ya pertenece a otro canal
+var ErrStreamNameInUse = errors.New("el nombre de stream ya está en uso")
+
+// transitions transiciones válidas desde cada estado
+//
+//	inactive -> starting -> active -> stopping -> inactive
+//	cualquier estado -> error; error -> starting | inactive
+var transitions = map[Status][]Status{
+	StatusInactive: {StatusStarting, StatusStopping, StatusError},
+	StatusStarting: {StatusActive, StatusStopping, StatusInactive, StatusError},
+	StatusActive:   {StatusStarting, StatusStopping, StatusInactive, StatusError},
+	StatusStopping: {StatusInactive, StatusError},
+	StatusError:    {StatusStarting, StatusStopping, StatusInactive},
+}
+
+// CanTransition indica si se permite pasar de un estado a otro
+func CanTransition(from, to Status) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition cambio de estado de un canal
+type Transition struct {
+	ChannelID string    `json:"channelId"`
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	Reason    string    `json:"reason,omitempty"`
+	ErrorCode string    `json:"errorCode,omitempty"` // Solo en transiciones a error
+	Time      time.Time `json:"time"`
+}
+
+// Códigos de error propios del ciclo de vida del canal (los de FFmpeg
+// vienen de ffmpeg.ErrorCode)
+const (
+	ErrorCodeStartTimeout = "start_timeout"
+	ErrorCodeStopTimeout  = "stop_timeout"
+)
+
 // Channel representa un canal de video SRT
 type Channel struct {
 	ID            string    `json:"id"`
@@ -32,12 +80,41 @@ type Channel struct {
 	SRTHost       string    `json:"srtHost"`       // IP/Host para el stream SRT (default: 0.0.0.0)
 	Resolution    string    `json:"resolution"`    // Resolución de salida (ej: "1920x1080")
 	FrameRate     int       `json:"frameRate"`     // FPS de salida
+	ScaleMode     string    `json:"scaleMode"`     // letterbox, crop, stretch
+	Interlace     string    `json:"interlace"`     // progressive, tff, bff
+	PixelFormat   string    `json:"pixelFormat"`   // yuv420p, yuv422p, yuv444p, nv12
+	AudioChannels int       `json:"audioChannels"` // Canales de audio de salida
 	Status        Status    `json:"status"`
+	StatusSince   time.Time `json:"statusSince"` // Momento de la última transición
 	CurrentFile   string    `json:"currentFile"`
+	Loop          bool      `json:"loop,omitempty"` // CurrentFile se reproduce en bucle (patrón de prueba)
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
 	ErrorMessage  string    `json:"errorMessage,omitempty"`
+	ErrorCode     string    `json:"errorCode,omitempty"` // Categoría estable del último error
 	Stats         Stats     `json:"stats"`
+
+	Owner          string         `json:"owner,omitempty"`          // Cliente remoto que creó el canal (vacío = creado localmente)
+	Ephemeral      bool           `json:"ephemeral,omitempty"`      // Canal automático de un cliente (se elimina tras su desconexión)
+	DisconnectedAt *time.Time     `json:"disconnectedAt,omitempty"` // Desconexión del propietario de un canal efímero
+	Restart        *restart.State `json:"restart,omitempty"`        // Estado de la política de reinicio
+}
+
+// Source archivo que emite el canal y si va en bucle: el actual si hay uno,
+// si no VideoPath (una sola vez)
+func (c *Channel) Source() (string, bool) {
+	if c.CurrentFile != "" {
+		return c.CurrentFile, c.Loop
+	}
+	return c.VideoPath, false
+}
+
+// clone copia el canal para entregarlo fuera del Manager: el original solo se
+// lee y modifica con m.mutex tomado. Los punteros (DisconnectedAt, Restart) se
+// reemplazan, nunca se modifican en su sitio, y pueden compartirse.
+func (c *Channel) clone() *Channel {
+	channel := *c
+	return &channel
 }
 
 // Stats contiene estadísticas del canal
@@ -51,96 +128,116 @@ type Stats struct {
 
 // Manager gestiona los canales de video
 type Manager struct {
-	channels    map[string]*Channel
-	mutex       sync.RWMutex
-	persistPath string
+	channels       map[string]*Channel
+	mutex          sync.RWMutex
+	persistPath    string
+	loadErr        error // Problema al leer channels.json en el arranque
+	onTransition   func(Transition)
+	onPersistError func(error)
+	ports          *ports.Allocator
 }
 
 // NewManager crea un nuevo gestor de canales
 func NewManager() *Manager {
-	// Determinar ruta de persistencia junto al ejecutable (portable)
-	exePath, err := os.Executable()
-	if err != nil {
-		exePath = "."
-	}
-	exeDir := filepath.Dir(exePath)
-	persistPath := filepath.Join(exeDir, "channels.json")
-
 	m := &Manager{
 		channels:    make(map[string]*Channel),
-		persistPath: persistPath,
+		persistPath: config.GetChannelsPath(),
+		ports:       ports.NewAllocator(nil),
 	}
 
 	// Cargar canales guardados
-	m.loadFromDisk()
+	m.loadErr = m.loadFromDisk()
+	if m.loadErr != nil {
+		log.Printf("[Canales] Error cargando %s: %v", m.persistPath, m.loadErr)
+	}
 
 	return m
 }
 
-// getNextSRTPort calcula el siguiente puerto SRT disponible
-func (m *Manager) getNextSRTPort() int {
-	basePort := 9000
-	maxPort := basePort
-
-	for _, ch := range m.channels {
-		if ch.SRTPort >= maxPort {
-			maxPort = ch.SRTPort + 1
-		}
-	}
-
-	if maxPort < basePort {
-		return basePort
-	}
-	return maxPort
+// SetPortRanges establece los rangos de asignación automática de puertos SRT
+func (m *Manager) SetPortRanges(ranges []ports.Range) {
+	m.ports.SetRanges(ranges)
 }
 
 // Add agrega un nuevo canal (videoPath es opcional - Aximmetry lo envía dinámicamente)
 func (m *Manager) Add(label, videoPath, srtStreamName string) (*Channel, error) {
+	return m.AddSpec(Spec{Label: label, VideoPath: videoPath, SRTStreamName: srtStreamName})
+}
+
+// AddSpec crea un canal a partir de su definición. Sin puerto se asigna uno
+// libre de los rangos configurados; con puerto fijo falla si no está disponible.
+func (m *Manager) AddSpec(spec Spec) (*Channel, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	channel, err := m.addLocked(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persistir cambios a disco
+	m.saveToDisk()
+
+	return channel.clone(), nil
+}
+
+// addLocked crea el canal en memoria; requiere m.mutex tomado
+func (m *Manager) addLocked(spec Spec) (*Channel, error) {
 	// Validar parámetros
-	if label == "" {
+	if spec.Label == "" {
 		return nil, errors.New("la etiqueta no puede estar vacía")
 	}
 	// videoPath ya no es obligatorio - Aximmetry lo envía vía WebSocket
 
 	// Generar nombre de stream si no se proporciona
+	srtStreamName := spec.SRTStreamName
 	if srtStreamName == "" {
-		srtStreamName = "STREAM_" + label
+		srtStreamName = "STREAM_" + spec.Label
 	}
 
 	// Verificar que el nombre de stream no esté en uso
 	for _, ch := range m.channels {
 		if ch.SRTStreamName == srtStreamName {
-			return nil, errors.New("el nombre de stream ya está en uso")
+			return nil, ErrStreamNameInUse
 		}
 	}
 
-	// Asignar puerto SRT único
-	srtPort := m.getNextSRTPort()
+	srtHost := spec.SRTHost
+	if srtHost == "" {
+		srtHost = "0.0.0.0" // Por defecto escucha en todas las interfaces
+	}
+
+	// Asignar puerto SRT único (libre en el sistema)
+	id := uuid.New().String()
+	srtPort := spec.SRTPort
+	if srtPort == 0 {
+		port, err := m.ports.Allocate(id, srtHost)
+		if err != nil {
+			return nil, err
+		}
+		srtPort = port
+	} else if err := m.ports.Assign(id, srtHost, srtPort); err != nil {
+		return nil, m.portError(err, srtPort)
+	}
 
 	channel := &Channel{
-		ID:            uuid.New().String(),
-		Label:         label,
-		VideoPath:     videoPath,
+		ID:            id,
+		Label:         spec.Label,
+		VideoPath:     spec.VideoPath,
 		SRTStreamName: srtStreamName,
 		SRTPort:       srtPort,
-		SRTHost:       "0.0.0.0",   // Por defecto escucha en todas las interfaces
-		Resolution:    "1920x1080", // Valor por defecto
-		FrameRate:     30,          // Valor por defecto
+		SRTHost:       srtHost,
 		Status:        StatusInactive,
+		StatusSince:   time.Now(),
 		CurrentFile:   "", // Se llenará cuando Aximmetry solicite un video
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 		Stats:         Stats{},
 	}
+	channel.setOutput(spec.OutputSettings.withDefaults())
 
 	m.channels[channel.ID] = channel
 
-	// Persistir cambios a disco
-	m.saveToDisk()
-
 	return channel, nil
 }
 
@@ -154,6 +251,7 @@ func (m *Manager) Remove(channelID string) error {
 	}
 
 	delete(m.channels, channelID)
+	m.ports.Release(channelID)
 
 	// Persistir cambios a disco
 	m.saveToDisk()
@@ -161,7 +259,7 @@ func (m *Manager) Remove(channelID string) error {
 	return nil
 }
 
-// Get obtiene un canal por ID
+// Get obtiene un canal por ID (una copia)
 func (m *Manager) Get(channelID string) (*Channel, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
@@ -171,31 +269,31 @@ func (m *Manager) Get(channelID string) (*Channel, error) {
 		return nil, errors.New("canal no encontrado")
 	}
 
-	return channel, nil
+	return channel.clone(), nil
 }
 
-// GetBySRTName obtiene un canal por nombre SRT
+// GetBySRTName obtiene un canal por nombre SRT (una copia)
 func (m *Manager) GetBySRTName(srtName string) (*Channel, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	for _, ch := range m.channels {
 		if ch.SRTStreamName == srtName {
-			return ch, nil
+			return ch.clone(), nil
 		}
 	}
 
 	return nil, errors.New("canal no encontrado")
 }
 
-// GetByLabel obtiene un canal por su nombre/label
+// GetByLabel obtiene un canal por su nombre/label (una copia)
 func (m *Manager) GetByLabel(label string) *Channel {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	for _, ch := range m.channels {
 		if ch.Label == label {
-			return ch
+			return ch.clone()
 		}
 	}
 
@@ -244,7 +342,7 @@ func (m *Manager) Update(channelID, label, videoPath, srtStreamName string) (*Ch
 	if srtStreamName != channel.SRTStreamName {
 		for _, ch := range m.channels {
 			if ch.ID != channelID && ch.SRTStreamName == srtStreamName {
-				return nil, errors.New("el nombre de stream SRT ya está en uso")
+				return nil, ErrStreamNameInUse
 			}
 		}
 	}
@@ -264,11 +362,35 @@ func (m *Manager) Update(channelID, label, videoPath, srtStreamName string) (*Ch
 	// Persistir cambios a disco
 	m.saveToDisk()
 
-	return channel, nil
+	return channel.clone(), nil
 }
 
-// SetStatus establece el estado de un canal
-func (m *Manager) SetStatus(channelID string, status Status) error {
+// UpdateOutput reemplaza los parámetros de salida de un canal (resolución, fps,
+// escalado, barrido, formato de pixel, audio). Se aplican en el próximo inicio.
+func (m *Manager) UpdateOutput(channelID string, output OutputSettings) (*Channel, error) {
+	if err := output.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	channel, exists := m.channels[channelID]
+	if !exists {
+		return nil, errors.New("canal no encontrado")
+	}
+
+	channel.setOutput(output.withDefaults())
+	channel.UpdatedAt = time.Now()
+
+	// Persistir cambios a disco
+	m.saveToDisk()
+
+	return channel.clone(), nil
+}
+
+// SetOwner asigna el cliente propietario de un canal
+func (m *Manager) SetOwner(channelID, owner string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -277,14 +399,121 @@ func (m *Manager) SetStatus(channelID string, status Status) error {
 		return errors.New("canal no encontrado")
 	}
 
-	channel.Status = status
+	channel.Owner = owner
 	channel.UpdatedAt = time.Now()
 
+	// Persistir cambios a disco
+	m.saveToDisk()
+
 	return nil
 }
 
-// SetCurrentFile establece el archivo actual de un canal
-func (m *Manager) SetCurrentFile(channelID, filePath string) error {
+// SetTransitionHandler establece el callback llamado en cada transición de estado
+func (m *Manager) SetTransitionHandler(handler func(Transition)) {
+	m.mutex.Lock()
+	m.onTransition = handler
+	m.mutex.Unlock()
+}
+
+// SetStatus establece el estado de un canal (validando la transición)
+func (m *Manager) SetStatus(channelID string, status Status) error {
+	return m.Transition(channelID, status, "")
+}
+
+// Transition cambia el estado de un canal si la transición es válida.
+// Pasar al mismo estado no es un error ni emite evento.
+func (m *Manager) Transition(channelID string, to Status, reason string) error {
+	return m.transition(channelID, to, reason, "")
+}
+
+// Fail pasa el canal a error registrando la categoría del fallo
+func (m *Manager) Fail(channelID, code, reason string) error {
+	return m.transition(channelID, StatusError, reason, code)
+}
+
+func (m *Manager) transition(channelID string, to Status, reason, code string) error {
+	m.mutex.Lock()
+
+	channel, exists := m.channels[channelID]
+	if !exists {
+		m.mutex.Unlock()
+		return errors.New("canal no encontrado")
+	}
+
+	from := channel.Status
+	if from == to {
+		m.mutex.Unlock()
+		return nil
+	}
+	if !CanTransition(from, to) {
+		m.mutex.Unlock()
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	now := time.Now()
+	channel.Status = to
+	channel.StatusSince = now
+	channel.UpdatedAt = now
+
+	switch to {
+	case StatusError:
+		channel.ErrorMessage = reason
+		channel.ErrorCode = code
+		channel.Stats.LastError = reason
+		channel.Stats.ErrorCount++
+	case StatusStarting, StatusInactive:
+		channel.ErrorMessage = ""
+		channel.ErrorCode = ""
+	}
+
+	handler := m.onTransition
+	m.mutex.Unlock()
+
+	if handler != nil {
+		handler(Transition{
+			ChannelID: channelID,
+			From:      from,
+			To:        to,
+			Reason:    reason,
+			ErrorCode: code,
+			Time:      now,
+		})
+	}
+
+	return nil
+}
+
+// CheckTimeouts pasa a error los canales atascados en starting o stopping
+// más tiempo del permitido (0 = sin límite)
+func (m *Manager) CheckTimeouts(startTimeout, stopTimeout time.Duration) {
+	type expired struct {
+		id     string
+		code   string
+		reason string
+	}
+
+	m.mutex.RLock()
+	var stuck []expired
+	now := time.Now()
+	for _, ch := range m.channels {
+		elapsed := now.Sub(ch.StatusSince)
+		switch {
+		case ch.Status == StatusStarting && startTimeout > 0 && elapsed > startTimeout:
+			stuck = append(stuck, expired{ch.ID, ErrorCodeStartTimeout, fmt.Sprintf("timeout iniciando (%v sin frames ni listener SRT)", startTimeout)})
+		case ch.Status == StatusStopping && stopTimeout > 0 && elapsed > stopTimeout:
+			stuck = append(stuck, expired{ch.ID, ErrorCodeStopTimeout, fmt.Sprintf("timeout deteniendo (%v)", stopTimeout)})
+		}
+	}
+	m.mutex.RUnlock()
+
+	for _, e := range stuck {
+		m.Fail(e.id, e.code, e.reason)
+	}
+}
+
+// SetCurrentFile establece el archivo actual de un canal y si se reproduce en
+// bucle (los reinicios mantienen ambos)
+func (m *Manager) SetCurrentFile(channelID, filePath string, loop bool) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -294,6 +523,7 @@ func (m *Manager) SetCurrentFile(channelID, filePath string) error {
 	}
 
 	channel.CurrentFile = filePath
+	channel.Loop = loop
 	channel.UpdatedAt = time.Now()
 
 	return nil
@@ -318,6 +548,49 @@ func (m *Manager) SetSRTHost(channelID, host string) error {
 	return nil
 }
 
+// SetSRTPort asigna manualmente el puerto SRT de un canal. Falla si otro canal
+// usa el puerto, si está ocupado en el sistema o si el canal está emitiendo.
+func (m *Manager) SetSRTPort(channelID string, port int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	channel, exists := m.channels[channelID]
+	if !exists {
+		return errors.New("canal no encontrado")
+	}
+	if channel.SRTPort == port {
+		return nil
+	}
+	if channel.Status != StatusInactive && channel.Status != StatusError {
+		return errors.New("detenga el canal antes de cambiar el puerto")
+	}
+
+	if err := m.ports.Assign(channelID, channel.SRTHost, port); err != nil {
+		return m.portError(err, port)
+	}
+
+	channel.SRTPort = port
+	channel.UpdatedAt = time.Now()
+
+	// Persistir cambios
+	m.saveToDisk()
+
+	return nil
+}
+
+// portError añade a un error de asignación el canal que ya usa el puerto
+// (llamar con mutex tomado)
+func (m *Manager) portError(err error, port int) error {
+	if errors.Is(err, ports.ErrPortTaken) {
+		if owner, ok := m.ports.Owner(port); ok {
+			if other, exists := m.channels[owner]; exists {
+				return fmt.Errorf("%w (%s)", err, other.Label)
+			}
+		}
+	}
+	return err
+}
+
 // SetError establece un error en el canal
 func (m *Manager) SetError(channelID, errorMessage string) error {
 	m.mutex.Lock()
@@ -352,6 +625,21 @@ func (m *Manager) UpdateStats(channelID string, stats Stats) error {
 	return nil
 }
 
+// SetRestartState actualiza el estado de reinicio automático de un canal
+func (m *Manager) SetRestartState(channelID string, state *restart.State) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	channel, exists := m.channels[channelID]
+	if !exists {
+		return errors.New("canal no encontrado")
+	}
+
+	channel.Restart = state
+
+	return nil
+}
+
 // Count retorna el número total de canales
 func (m *Manager) Count() int {
 	m.mutex.RLock()
@@ -374,44 +662,3 @@ func (m *Manager) ActiveCount() int {
 
 	return count
 }
-
-// saveToDisk guarda los canales a disco
-func (m *Manager) saveToDisk() error {
-	channels := make([]*Channel, 0, len(m.channels))
-	for _, ch := range m.channels {
-		channels = append(channels, ch)
-	}
-
-	data, err := json.MarshalIndent(channels, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(m.persistPath, data, 0644)
-}
-
-// loadFromDisk carga los canales desde disco
-func (m *Manager) loadFromDisk() error {
-	data, err := os.ReadFile(m.persistPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No hay archivo, es normal en primera ejecución
-		}
-		return err
-	}
-
-	var channels []*Channel
-	if err := json.Unmarshal(data, &channels); err != nil {
-		return err
-	}
-
-	for _, ch := range channels {
-		// Resetear estado volátil al cargar
-		ch.Status = StatusInactive
-		ch.CurrentFile = ""
-		ch.ErrorMessage = ""
-		m.channels[ch.ID] = ch
-	}
-
-	return nil
-}
@@ -0,0 +1,65 @@
+package channel
+
+import (
+	"sync"
+	"testing"
+)
+
+// Get, GetByLabel, GetBySRTName, Add, Update y UpdateOutput entregan copias: el
+// llamador puede leerlas mientras otras goroutines cambian el estado del canal
+func TestReturnedChannelsAreCopies(t *testing.T) {
+	m := newTestManager(t)
+	added, err := m.Add("Plató", "", "SRT_plato")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookups := map[string]func() *Channel{
+		"Add": func() *Channel { return added },
+		"Get": func() *Channel {
+			ch, _ := m.Get(added.ID)
+			return ch
+		},
+		"GetByLabel": func() *Channel { return m.GetByLabel("Plató") },
+		"GetBySRTName": func() *Channel {
+			ch, _ := m.GetBySRTName("SRT_plato")
+			return ch
+		},
+		"Update": func() *Channel {
+			ch, _ := m.Update(added.ID, "Plató", "", "SRT_plato")
+			return ch
+		},
+		"UpdateOutput": func() *Channel {
+			ch, _ := m.UpdateOutput(added.ID, OutputSettings{})
+			return ch
+		},
+	}
+	for name, lookup := range lookups {
+		t.Run(name, func(t *testing.T) {
+			ch := lookup()
+			if ch == nil {
+				t.Fatal("canal no encontrado")
+			}
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					m.Transition(added.ID, StatusStarting, "")
+					m.Transition(added.ID, StatusInactive, "")
+				}
+			}()
+			for i := 0; i < 50; i++ {
+				_ = ch.Status
+				_ = ch.StatusSince
+			}
+			wg.Wait()
+
+			ch.Label = "modificado"
+			if current, _ := m.Get(added.ID); current.Label != "Plató" {
+				t.Errorf("modificar la copia cambió el canal: %q", current.Label)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package channel
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientLabelPrefix prefijo de la etiqueta de los canales creados automáticamente
+// para un cliente (play_video sin channelId)
+const ClientLabelPrefix = "Client_"
+
+// EnsureEphemeral retorna el canal automático de un cliente o, si no tiene, lo
+// crea con la primera definición cuyo nombre de stream esté libre. La búsqueda y
+// la creación ocurren bajo el mismo lock: dos peticiones simultáneas del mismo
+// cliente obtienen un único canal. created indica si el canal es nuevo.
+func (m *Manager) EnsureEphemeral(owner string, specs ...Spec) (channel Channel, created bool, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, ch := range m.channels {
+		if ch.Ephemeral && ch.Owner == owner {
+			return *ch, false, nil
+		}
+	}
+
+	err = errors.New("sin definición para el canal automático")
+	for _, spec := range specs {
+		var ch *Channel
+		ch, err = m.addLocked(spec)
+		if errors.Is(err, ErrStreamNameInUse) {
+			continue
+		}
+		if err != nil {
+			return Channel{}, false, err
+		}
+
+		ch.Owner = owner
+		ch.Ephemeral = true
+
+		// Persistir cambios a disco
+		m.saveToDisk()
+
+		return *ch, true, nil
+	}
+
+	return Channel{}, false, err
+}
+
+// ClaimEphemeral cancela la expiración de los canales automáticos de un cliente
+// que vuelve a conectarse. Retorna los canales recuperados.
+func (m *Manager) ClaimEphemeral(owner string) []Channel {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	claimed := make([]Channel, 0)
+	for _, ch := range m.channels {
+		if ch.Ephemeral && ch.Owner == owner && ch.DisconnectedAt != nil {
+			ch.DisconnectedAt = nil
+			claimed = append(claimed, *ch)
+		}
+	}
+	if len(claimed) > 0 {
+		m.saveToDisk()
+	}
+
+	return claimed
+}
+
+// ReleaseEphemeral inicia el periodo de gracia de los canales automáticos de un
+// cliente que se ha desconectado. Retorna los canales afectados.
+func (m *Manager) ReleaseEphemeral(owner string, at time.Time) []Channel {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	released := make([]Channel, 0)
+	for _, ch := range m.channels {
+		if ch.Ephemeral && ch.Owner == owner && ch.DisconnectedAt == nil {
+			t := at
+			ch.DisconnectedAt = &t
+			released = append(released, *ch)
+		}
+	}
+	if len(released) > 0 {
+		m.saveToDisk()
+	}
+
+	return released
+}
+
+// ExpiredEphemeral retorna los canales automáticos cuyo cliente lleva
+// desconectado al menos grace
+func (m *Manager) ExpiredEphemeral(grace time.Duration, now time.Time) []Channel {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	expired := make([]Channel, 0)
+	for _, ch := range m.channels {
+		if ch.Ephemeral && ch.DisconnectedAt != nil && now.Sub(*ch.DisconnectedAt) >= grace {
+			expired = append(expired, *ch)
+		}
+	}
+
+	return expired
+}
+
+// adoptLegacyClientChannel marca como efímeros los canales automáticos guardados
+// por versiones anteriores (etiqueta Client_<id de conexión>, sin propietario
+// recuperable). Retorna true si el canal cambió.
+func adoptLegacyClientChannel(ch *Channel) bool {
+	if ch.Ephemeral || !strings.HasPrefix(ch.Label, ClientLabelPrefix) {
+		return false
+	}
+	clientID := strings.TrimPrefix(ch.Label, ClientLabelPrefix)
+	if _, err := uuid.Parse(clientID); err != nil {
+		return false // Etiqueta elegida por el usuario, no un canal automático
+	}
+	if ch.Owner == "" {
+		ch.Owner = clientID
+	}
+	ch.Ephemeral = true
+	return true
+}
@@ -0,0 +1,84 @@
+package channel
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"servidor-stream/internal/config"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	if _, err := config.InitDataDir(t.TempDir(), ""); err != nil {
+		t.Fatal(err)
+	}
+	return NewManager()
+}
+
+// Peticiones simultáneas del mismo cliente obtienen un único canal automático
+func TestEnsureEphemeralConcurrent(t *testing.T) {
+	m := newTestManager(t)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	ids := make(map[string]bool)
+	createdCount := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch, created, err := m.EnsureEphemeral("cliente", Spec{Label: "Client_cliente", SRTStreamName: "SRT_cliente"})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			ids[ch.ID] = true
+			if created {
+				createdCount++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(ids) != 1 || createdCount != 1 || m.Count() != 1 {
+		t.Errorf("canales = %d (creados %d, total %d), se esperaba uno", len(ids), createdCount, m.Count())
+	}
+}
+
+// Con el nombre de stream ocupado se usa la siguiente definición
+func TestEnsureEphemeralStreamNameFallback(t *testing.T) {
+	m := newTestManager(t)
+	if _, err := m.Add("Otro", "", "SRT_a"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		owner   string
+		specs   []Spec
+		stream  string
+		wantErr error
+	}{
+		{"primera libre", "x", []Spec{{Label: "Client_x", SRTStreamName: "SRT_x"}}, "SRT_x", nil},
+		{"segunda", "y", []Spec{{Label: "Client_y", SRTStreamName: "SRT_a"}, {Label: "Client_y", SRTStreamName: "SRT_y"}}, "SRT_y", nil},
+		{"todas ocupadas", "z", []Spec{{Label: "Client_z", SRTStreamName: "SRT_a"}}, "", ErrStreamNameInUse},
+		{"existente", "x", []Spec{{Label: "Client_x", SRTStreamName: "SRT_otro"}}, "SRT_x", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch, _, err := m.EnsureEphemeral(tt.owner, tt.specs...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ch.SRTStreamName != tt.stream || !ch.Ephemeral || ch.Owner != tt.owner {
+				t.Errorf("canal = %s (efímero %v, dueño %q), se esperaba %s de %q", ch.SRTStreamName, ch.Ephemeral, ch.Owner, tt.stream, tt.owner)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package channel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Modos de escalado cuando la entrada no tiene la relación de aspecto de salida
+const (
+	ScaleLetterbox = "letterbox" // Encajar con bandas negras
+	ScaleCrop      = "crop"      // Llenar recortando los bordes
+	ScaleStretch   = "stretch"   // Deformar hasta la resolución de salida
+)
+
+// Modos de barrido
+const (
+	InterlaceProgressive = "progressive"
+	InterlaceTFF         = "tff" // Entrelazado, campo superior primero
+	InterlaceBFF         = "bff" // Entrelazado, campo inferior primero
+)
+
+// ErrInvalidOutput parámetros de salida no válidos
+var ErrInvalidOutput = errors.New("parámetros de salida inválidos")
+
+var (
+	scaleModes     = []string{ScaleLetterbox, ScaleCrop, ScaleStretch}
+	interlaceModes = []string{InterlaceProgressive, InterlaceTFF, InterlaceBFF}
+	pixelFormats   = []string{"yuv420p", "yuv422p", "yuv444p", "nv12"}
+	audioChannels  = []int{1, 2, 6, 8}
+)
+
+// OutputSettings parámetros de salida editables de un canal
+type OutputSettings struct {
+	Resolution    string `json:"resolution"`    // "1920x1080"
+	FrameRate     int    `json:"frameRate"`     // 1-120
+	ScaleMode     string `json:"scaleMode"`     // letterbox, crop, stretch
+	Interlace     string `json:"interlace"`     // progressive, tff, bff
+	PixelFormat   string `json:"pixelFormat"`   // yuv420p, yuv422p, yuv444p, nv12
+	AudioChannels int    `json:"audioChannels"` // 1, 2, 6 (5.1), 8 (7.1)
+}
+
+// DefaultOutput parámetros de salida de un canal nuevo
+func DefaultOutput() OutputSettings {
+	return OutputSettings{
+		Resolution:    "1920x1080",
+		FrameRate:     30,
+		ScaleMode:     ScaleStretch,
+		Interlace:     InterlaceProgressive,
+		PixelFormat:   "yuv420p",
+		AudioChannels: 2,
+	}
+}
+
+// withDefaults completa los campos vacíos (canales guardados antes de existir)
+func (o OutputSettings) withDefaults() OutputSettings {
+	def := DefaultOutput()
+	if o.Resolution == "" {
+		o.Resolution = def.Resolution
+	}
+	if o.FrameRate == 0 {
+		o.FrameRate = def.FrameRate
+	}
+	if o.ScaleMode == "" {
+		o.ScaleMode = def.ScaleMode
+	}
+	if o.Interlace == "" {
+		o.Interlace = def.Interlace
+	}
+	if o.PixelFormat == "" {
+		o.PixelFormat = def.PixelFormat
+	}
+	if o.AudioChannels == 0 {
+		o.AudioChannels = def.AudioChannels
+	}
+	return o
+}
+
+// Size retorna ancho y alto de la resolución
+func (o OutputSettings) Size() (width, height int, err error) {
+	var rest string
+	n, _ := fmt.Sscanf(o.Resolution+" ", "%dx%d%s", &width, &height, &rest)
+	if n != 2 {
+		return 0, 0, fmt.Errorf("%w: resolución %q (formato ANCHOxALTO)", ErrInvalidOutput, o.Resolution)
+	}
+	return width, height, nil
+}
+
+// Validate verifica los parámetros (los campos vacíos toman el valor por defecto)
+func (o OutputSettings) Validate() error {
+	o = o.withDefaults()
+
+	width, height, err := o.Size()
+	if err != nil {
+		return err
+	}
+	if width < 16 || width > 7680 || height < 16 || height > 4320 {
+		return fmt.Errorf("%w: resolución %s fuera de rango (16x16 - 7680x4320)", ErrInvalidOutput, o.Resolution)
+	}
+	if width%2 != 0 || height%2 != 0 {
+		return fmt.Errorf("%w: la resolución debe tener ancho y alto pares", ErrInvalidOutput)
+	}
+	if o.FrameRate < 1 || o.FrameRate > 120 {
+		return fmt.Errorf("%w: frame rate %d fuera de rango (1-120)", ErrInvalidOutput, o.FrameRate)
+	}
+	if !contains(scaleModes, o.ScaleMode) {
+		return fmt.Errorf("%w: modo de escalado %q (letterbox, crop, stretch)", ErrInvalidOutput, o.ScaleMode)
+	}
+	if !contains(interlaceModes, o.Interlace) {
+		return fmt.Errorf("%w: barrido %q (progressive, tff, bff)", ErrInvalidOutput, o.Interlace)
+	}
+	if !contains(pixelFormats, o.PixelFormat) {
+		return fmt.Errorf("%w: formato de pixel %q (yuv420p, yuv422p, yuv444p, nv12)", ErrInvalidOutput, o.PixelFormat)
+	}
+	if !contains(audioChannels, o.AudioChannels) {
+		return fmt.Errorf("%w: canales de audio %d (1, 2, 6, 8)", ErrInvalidOutput, o.AudioChannels)
+	}
+	return nil
+}
+
+// Output retorna los parámetros de salida del canal
+func (c *Channel) Output() OutputSettings {
+	return OutputSettings{
+		Resolution:    c.Resolution,
+		FrameRate:     c.FrameRate,
+		ScaleMode:     c.ScaleMode,
+		Interlace:     c.Interlace,
+		PixelFormat:   c.PixelFormat,
+		AudioChannels: c.AudioChannels,
+	}.withDefaults()
+}
+
+func (c *Channel) setOutput(o OutputSettings) {
+	c.Resolution = o.Resolution
+	c.FrameRate = o.FrameRate
+	c.ScaleMode = o.ScaleMode
+	c.Interlace = o.Interlace
+	c.PixelFormat = o.PixelFormat
+	c.AudioChannels = o.AudioChannels
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package channel
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"sort"
+	"time"
+
+	"servidor-stream/internal/storage"
+)
+
+// channelsVersion versión actual del esquema de channels.json
+const channelsVersion = 1
+
+// channelsSchema versiones y migraciones de channels.json
+var channelsSchema = storage.Schema{
+	Version: channelsVersion,
+	Migrations: []storage.Migration{
+		// 0 -> 1: la lista de canales pasa a un objeto con versión
+		func(doc []byte) ([]byte, error) {
+			var channels []json.RawMessage
+			if err := json.Unmarshal(doc, &channels); err != nil {
+				return nil, err
+			}
+			return json.Marshal(map[string]interface{}{"version": 1, "channels": channels})
+		},
+	},
+	Backups: storage.DefaultBackups,
+}
+
+// channelsFile contenido de channels.json
+type channelsFile struct {
+	Version  int        `json:"version"`
+	Channels []*Channel `json:"channels"`
+}
+
+// LoadError describe el problema encontrado al leer channels.json en el arranque
+// (nil si se cargó sin incidencias o no existía)
+func (m *Manager) LoadError() error {
+	return m.loadErr
+}
+
+// SetPersistErrorHandler establece el callback llamado cuando no se pueden
+// guardar los canales en disco
+func (m *Manager) SetPersistErrorHandler(handler func(error)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onPersistError = handler
+}
+
+// saveToDisk guarda los canales a disco (escritura atómica con copia de la
+// versión anterior). Los errores se notifican al handler de persistencia.
+func (m *Manager) saveToDisk() error {
+	channels := make([]*Channel, 0, len(m.channels))
+	for _, ch := range m.channels {
+		channels = append(channels, ch)
+	}
+	// Orden estable: sin cambios reales el archivo no cambia ni genera copias
+	sort.Slice(channels, func(i, j int) bool {
+		if !channels[i].CreatedAt.Equal(channels[j].CreatedAt) {
+			return channels[i].CreatedAt.Before(channels[j].CreatedAt)
+		}
+		return channels[i].ID < channels[j].ID
+	})
+
+	data, err := json.MarshalIndent(channelsFile{Version: channelsVersion, Channels: channels}, "", "  ")
+	if err == nil {
+		err = channelsSchema.Save(m.persistPath, data)
+	}
+	if err != nil {
+		log.Printf("[Canales] Error guardando %s: %v", m.persistPath, err)
+		if m.onPersistError != nil {
+			go m.onPersistError(err) // Fuera del mutex del gestor
+		}
+	}
+
+	return err
+}
+
+// loadFromDisk carga los canales desde disco, migrando formatos anteriores. Si
+// el archivo está dañado se restaura la copia más reciente que se pueda leer.
+func (m *Manager) loadFromDisk() error {
+	var file channelsFile
+	result, err := channelsSchema.Load(m.persistPath, func(doc []byte) error {
+		file = channelsFile{}
+		return json.Unmarshal(doc, &file)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // No hay archivo, es normal en primera ejecución
+	}
+	if err != nil {
+		return err
+	}
+	channels := file.Channels
+
+	for _, ch := range channels {
+		// Resetear estado volátil al cargar
+		ch.Status = StatusInactive
+		ch.StatusSince = time.Now()
+		ch.CurrentFile = ""
+		ch.Loop = false
+		ch.ErrorMessage = ""
+		ch.ErrorCode = ""
+		ch.Restart = nil
+		ch.setOutput(ch.Output()) // Completar parámetros añadidos en versiones posteriores
+		if adoptLegacyClientChannel(ch) {
+			log.Printf("[Canales] Canal automático %s marcado como efímero", ch.Label)
+		}
+		if ch.Ephemeral && ch.DisconnectedAt == nil {
+			// Ningún cliente conectado todavía: empieza el periodo de gracia
+			now := time.Now()
+			ch.DisconnectedAt = &now
+		}
+		m.channels[ch.ID] = ch
+	}
+
+	// Reescribir en el formato actual tras migrar o restaurar una copia
+	save := result.FromVersion < channelsVersion || result.RestoredFrom != ""
+	if result.FromVersion < channelsVersion {
+		log.Printf("[Canales] %s migrado de la versión %d a la %d", m.persistPath, result.FromVersion, channelsVersion)
+	}
+
+	// Reservar los puertos guardados; los duplicados reciben uno nuevo
+	for _, ch := range channels {
+		if err := m.ports.Reserve(ch.ID, ch.SRTPort); err == nil {
+			continue
+		}
+		port, err := m.ports.Allocate(ch.ID, ch.SRTHost)
+		if err != nil {
+			return err
+		}
+		log.Printf("[Canales] Puerto SRT %d de %s en conflicto, reasignado a %d", ch.SRTPort, ch.Label, port)
+		ch.SRTPort = port
+		save = true
+	}
+	if save {
+		m.saveToDisk()
+	}
+
+	return result.Err()
+}
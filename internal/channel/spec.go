@@ -0,0 +1,111 @@
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Spec definición portable de un canal (presets): lo que el operador configura,
+// sin ID, estado ni estadísticas
+type Spec struct {
+	Label         string `json:"label"`
+	SRTStreamName string `json:"srtStreamName"`
+	SRTPort       int    `json:"srtPort,omitempty"` // 0 = asignar uno libre
+	SRTHost       string `json:"srtHost,omitempty"` // Vacío = 0.0.0.0
+	VideoPath     string `json:"videoPath,omitempty"`
+	OutputSettings
+}
+
+// Spec retorna la definición del canal
+func (c *Channel) Spec() Spec {
+	return Spec{
+		Label:          c.Label,
+		SRTStreamName:  c.SRTStreamName,
+		SRTPort:        c.SRTPort,
+		SRTHost:        c.SRTHost,
+		VideoPath:      c.VideoPath,
+		OutputSettings: c.Output(),
+	}
+}
+
+// Matches indica si el canal ya cumple la definición (sin puerto = cualquiera)
+func (c *Channel) Matches(spec Spec) bool {
+	current := c.Spec()
+	spec = spec.normalized()
+	if spec.SRTPort == 0 {
+		spec.SRTPort = current.SRTPort
+	}
+	return current == spec
+}
+
+// Validate verifica la definición (los campos vacíos toman su valor por defecto)
+func (s Spec) Validate() error {
+	if s.Label == "" {
+		return errors.New("la etiqueta no puede estar vacía")
+	}
+	if s.SRTPort < 0 || s.SRTPort > 65535 {
+		return fmt.Errorf("canal %s: puerto %d fuera de rango (1-65535)", s.Label, s.SRTPort)
+	}
+	if err := s.OutputSettings.withDefaults().Validate(); err != nil {
+		return fmt.Errorf("canal %s: %w", s.Label, err)
+	}
+	return nil
+}
+
+// normalized completa los valores por defecto
+func (s Spec) normalized() Spec {
+	if s.SRTStreamName == "" {
+		s.SRTStreamName = "STREAM_" + s.Label
+	}
+	if s.SRTHost == "" {
+		s.SRTHost = "0.0.0.0"
+	}
+	s.OutputSettings = s.OutputSettings.withDefaults()
+	return s
+}
+
+// ApplySpec ajusta un canal existente a una definición (sin puerto se conserva
+// el actual). El canal debe estar detenido si cambia el puerto.
+func (m *Manager) ApplySpec(channelID string, spec Spec) (*Channel, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	spec = spec.normalized()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	channel, exists := m.channels[channelID]
+	if !exists {
+		return nil, errors.New("canal no encontrado")
+	}
+
+	for _, ch := range m.channels {
+		if ch.ID != channelID && ch.SRTStreamName == spec.SRTStreamName {
+			return nil, ErrStreamNameInUse
+		}
+	}
+
+	if spec.SRTPort != 0 && spec.SRTPort != channel.SRTPort {
+		if channel.Status != StatusInactive && channel.Status != StatusError {
+			return nil, errors.New("detenga el canal antes de cambiar el puerto")
+		}
+		if err := m.ports.Assign(channelID, spec.SRTHost, spec.SRTPort); err != nil {
+			return nil, m.portError(err, spec.SRTPort)
+		}
+		channel.SRTPort = spec.SRTPort
+	}
+
+	channel.Label = spec.Label
+	channel.SRTStreamName = spec.SRTStreamName
+	channel.SRTHost = spec.SRTHost
+	channel.VideoPath = spec.VideoPath
+	channel.setOutput(spec.OutputSettings)
+	channel.UpdatedAt = time.Now()
+
+	// Persistir cambios a disco
+	m.saveToDisk()
+
+	return channel.clone(), nil
+}
@@ -2,17 +2,61 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+
+	"servidor-stream/internal/storage"
 )
 
+// WebhookTarget destino de webhooks salientes
+type WebhookTarget struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // Clave para la firma HMAC-SHA256
+	Events []string `json:"events,omitempty"` // Eventos a enviar (vacío = todos)
+}
+
+// Version versión actual del esquema de config.json
+const Version = 1
+
+// schema versiones y migraciones de config.json. Los config.json anteriores al
+// campo version se leen como versión 1 (storage.Version): los campos nuevos
+// toman su default.
+var schema = storage.Schema{
+	Version: Version,
+	Backups: storage.DefaultBackups,
+}
+
 // Config configuración de la aplicación
 type Config struct {
+	Version int `json:"version"` // Versión del esquema (ver migraciones en schema)
+
 	// Servidor
 	WebSocketPort int    `json:"webSocketPort"`
 	FFmpegPath    string `json:"ffmpegPath"`
 	AutoRestart   bool   `json:"autoRestart"`
 
+	// Gestión de canales por WebSocket (create/update/delete_channel, set_port)
+	WSChannelManagement bool   `json:"wsChannelManagement"`
+	WSAdminToken        string `json:"wsAdminToken"` // Token que da permiso sobre todos los canales (vacío = ningún cliente remoto es administrador)
+
+	// Canales automáticos de clientes (play_video sin channelId)
+	ClientChannelGracePeriod int `json:"clientChannelGracePeriod"` // Segundos tras la desconexión antes de eliminarlos
+
+	// Política de reinicio automático
+	RestartMaxAttempts  int `json:"restartMaxAttempts"`  // Intentos consecutivos antes de abrir el circuito (0 = sin límite)
+	RestartInitialDelay int `json:"restartInitialDelay"` // Retardo del primer intento en segundos
+	RestartMaxDelay     int `json:"restartMaxDelay"`     // Retardo máximo en segundos (backoff exponencial)
+	RestartResetWindow  int `json:"restartResetWindow"`  // Segundos sin fallos para reiniciar el contador
+
+	// Timeouts de transición de estado (segundos, 0 = sin límite)
+	StartTimeout int `json:"startTimeout"` // Máximo en "starting" sin frames ni listener SRT
+	StopTimeout  int `json:"stopTimeout"`  // Máximo en "stopping"
+
+	// Detención ordenada de FFmpeg ("q"/SIGINT y espera antes de matar el proceso)
+	StopGracePeriod int `json:"stopGracePeriod"` // ms (0 = kill inmediato)
+
 	// Video por defecto
 	DefaultVideoBitrate string `json:"defaultVideoBitrate"`
 	DefaultAudioBitrate string `json:"defaultAudioBitrate"`
@@ -22,12 +66,29 @@ type Config struct {
 	TestPatternPath string `json:"testPatternPath"` // Ruta al video patrón para pruebas
 
 	// SRT
-	SRTPrefix string `json:"srtPrefix"`
-	SRTGroup  string `json:"srtGroup"`
+	SRTPrefix     string `json:"srtPrefix"`
+	SRTGroup      string `json:"srtGroup"`
+	SRTPortRanges string `json:"srtPortRanges"` // Rangos para asignar puertos a canales nuevos (ej: "9000-9099,9200-9299")
 
 	// Rutas
 	DefaultVideoPath string `json:"defaultVideoPath"`
-	LogPath          string `json:"logPath"`
+	LogPath          string `json:"logPath"` // Directorio de logs (vacío = <datos>/logs)
+
+	// Niveles de log: trace, debug, info, warn, error. Un subsistema vacío usa logLevel
+	LogLevel          string `json:"logLevel"`
+	LogLevelApp       string `json:"logLevelApp"`
+	LogLevelWebSocket string `json:"logLevelWebSocket"` // Conexiones y mensajes de clientes
+	LogLevelFFmpeg    string `json:"logLevelFFmpeg"`    // Procesos FFmpeg
+	LogLevelChannel   string `json:"logLevelChannel"`   // Operaciones y estados de canales
+
+	// Logs en disco
+	LogToFile      bool   `json:"logToFile"`
+	LogFormat      string `json:"logFormat"`      // text, json (una entrada JSON por línea)
+	LogMaxSizeMB   int    `json:"logMaxSizeMB"`   // Rotar al superar este tamaño (0 = sin límite)
+	LogRotateHours int    `json:"logRotateHours"` // Rotar cada N horas desde medianoche (0 = solo por tamaño)
+	LogMaxFiles    int    `json:"logMaxFiles"`    // Archivos rotados que se conservan por log (0 = todos)
+	LogMaxAgeDays  int    `json:"logMaxAgeDays"`  // Borrar archivos rotados más antiguos (0 = nunca)
+	FFmpegLogs     bool   `json:"ffmpegLogs"`     // Guardar el stderr de FFmpeg de cada canal en <logs>/ffmpeg
 
 	// UI
 	Theme       string `json:"theme"`
@@ -56,6 +117,29 @@ type Config struct {
 	SRTSendBuffer   int `json:"srtSendBuffer"`   // Buffer de envío en bytes
 	SRTOverheadBW   int `json:"srtOverheadBW"`   // Overhead bandwidth %
 	SRTPeerIdleTime int `json:"srtPeerIdleTime"` // Timeout de peer idle en ms
+
+	// === Integraciones ===
+
+	// OSC (UDP)
+	OSCEnabled         bool     `json:"oscEnabled"`
+	OSCPort            int      `json:"oscPort"`            // Puerto UDP de escucha
+	OSCFeedbackTargets []string `json:"oscFeedbackTargets"` // Destinos "host:puerto" para feedback de estado
+
+	// MQTT
+	MQTTEnabled       bool   `json:"mqttEnabled"`
+	MQTTBroker        string `json:"mqttBroker"`        // ej: tcp://localhost:1883
+	MQTTClientID      string `json:"mqttClientId"`      // ID del cliente MQTT
+	MQTTUsername      string `json:"mqttUsername"`      // Usuario (opcional)
+	MQTTPassword      string `json:"mqttPassword"`      // Contraseña (opcional)
+	MQTTTopicPrefix   string `json:"mqttTopicPrefix"`   // Prefijo de topics
+	MQTTStatsInterval int    `json:"mqttStatsInterval"` // Intervalo de publicación de estadísticas en segundos
+
+	// Webhooks
+	Webhooks          []WebhookTarget `json:"webhooks"`
+	WebhookMaxRetries int             `json:"webhookMaxRetries"` // Reintentos con backoff exponencial
+
+	// Presets de canales
+	ActivePreset string `json:"activePreset"` // Último preset activado (vacío = ninguno)
 }
 
 // GetExecutablePath retorna la ruta del ejecutable
@@ -123,17 +207,36 @@ func Default() *Config {
 	testPatternPath := GetLocalTestPatternPath()
 
 	return &Config{
+		Version:             Version,
 		WebSocketPort:       8765,
 		FFmpegPath:          ffmpegPath,
 		AutoRestart:         true,
+		WSChannelManagement: false,
+		WSAdminToken:        "",
+		RestartMaxAttempts:  5,
+		RestartInitialDelay: 2,
+		RestartMaxDelay:     60,
+		RestartResetWindow:  300,
+		StartTimeout:        30,
+		StopTimeout:         10,
+		StopGracePeriod:     3000,
 		DefaultVideoBitrate: "5M",
 		DefaultAudioBitrate: "192k",
 		DefaultFrameRate:    25,
 		TestPatternPath:     testPatternPath,
 		SRTPrefix:           "SRT_SERVER_",
 		SRTGroup:            "",
+		SRTPortRanges:       "9000-9999",
 		DefaultVideoPath:    "",
 		LogPath:             "",
+		LogLevel:            "info",
+		LogToFile:           true,
+		LogFormat:           "text",
+		LogMaxSizeMB:        10,
+		LogRotateHours:      24,
+		LogMaxFiles:         10,
+		LogMaxAgeDays:       14,
+		FFmpegLogs:          true,
 		Theme:               "dark",
 		Language:            "es",
 		MaxLogLines:         1000,
@@ -155,91 +258,104 @@ func Default() *Config {
 		SRTSendBuffer:   2097152, // 2MB - reducido para baja latencia
 		SRTOverheadBW:   25,      // 25% overhead
 		SRTPeerIdleTime: 5000,    // 5 segundos
+		// OSC deshabilitado por defecto
+		OSCEnabled:         false,
+		OSCPort:            8000,
+		OSCFeedbackTargets: []string{},
+		// MQTT deshabilitado por defecto
+		MQTTEnabled:       false,
+		MQTTBroker:        "tcp://localhost:1883",
+		MQTTClientID:      "servidor-stream",
+		MQTTTopicPrefix:   "servidor-stream",
+		MQTTStatsInterval: 10,
+		// Webhooks
+		Webhooks:          []WebhookTarget{},
+		WebhookMaxRetries: 5,
+		// Canales automáticos de clientes: 5 minutos para reconectar
+		ClientChannelGracePeriod: 300,
 	}
 }
 
-// GetConfigPath retorna la ruta del archivo de configuración (junto al ejecutable)
-func GetConfigPath() string {
-	exePath, err := os.Executable()
-	if err != nil {
-		// Fallback al directorio actual
-		return "config.json"
-	}
-	exeDir := filepath.Dir(exePath)
-	return filepath.Join(exeDir, "config.json")
-}
-
-// Load carga la configuración desde archivo
+// Load carga la configuración desde archivo, migrando versiones anteriores, y
+// aplica los valores impuestos por entorno y flags (ver SetOverrides).
+// Siempre retorna una configuración utilizable: si config.json está dañado se
+// usa la copia de seguridad más reciente que se pueda leer (o los valores por
+// defecto) y el error describe lo ocurrido.
 func Load() (*Config, error) {
 	configPath := GetConfigPath()
 
-	// Leer archivo
-	data, err := os.ReadFile(configPath)
+	// Parsear JSON sobre los valores por defecto (campos nuevos conservan su default)
+	cfg := Default()
+	result, err := schema.Load(configPath, func(doc []byte) error {
+		*cfg = *Default()
+		return json.Unmarshal(doc, cfg)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		// Crear configuración por defecto
+		rememberFile(cfg)
+		err := Save(cfg)
+		ApplyOverrides(cfg)
+		return cfg, err
+	}
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Crear configuración por defecto
-			cfg := Default()
-			Save(cfg)
-			return cfg, nil
+		cfg = Default()
+		ApplyOverrides(cfg)
+		return cfg, err
+	}
+	rememberFile(cfg)
+
+	// Reescribir en el formato actual tras migrar o restaurar una copia
+	if result.FromVersion < Version || result.RestoredFrom != "" {
+		cfg.Version = Version
+		if err := Save(cfg); err != nil {
+			ApplyOverrides(cfg)
+			return cfg, err
 		}
+	}
+
+	// Entorno y flags tienen prioridad sobre el archivo
+	ApplyOverrides(cfg)
+	return cfg, result.Err()
+}
+
+// Read lee config.json tal como está en disco, sin migrarlo en el archivo ni
+// recurrir a las copias (para recargar ediciones externas). Los campos ausentes
+// toman su valor por defecto; entorno y flags se aplican encima.
+func Read() (*Config, error) {
+	data, err := os.ReadFile(GetConfigPath())
+	if err != nil {
 		return nil, err
 	}
 
-	// Parsear JSON
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	cfg := Default()
+	if _, err := schema.Decode(data, func(doc []byte) error {
+		return json.Unmarshal(doc, cfg)
+	}); err != nil {
 		return nil, err
 	}
+	cfg.Version = Version
+	rememberFile(cfg)
 
-	return &cfg, nil
+	ApplyOverrides(cfg)
+	return cfg, nil
 }
 
-// Save guarda la configuración a archivo
+// Save guarda la configuración a archivo (escritura atómica con copia de la
+// versión anterior). Los campos impuestos por entorno o flags conservan el
+// valor que tenían en el archivo.
 func Save(cfg *Config) error {
-	configPath := GetConfigPath()
-
-	// Crear directorio si no existe
-	os.MkdirAll(filepath.Dir(configPath), 0755)
+	cfg.Version = Version
+	file := withoutOverrides(cfg)
 
 	// Serializar a JSON
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.MarshalIndent(file, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	// Escribir archivo
-	return os.WriteFile(configPath, data, 0644)
-}
-
-// GetChannelsPath retorna la ruta del archivo de canales (junto al ejecutable)
-func GetChannelsPath() string {
-	exePath, err := os.Executable()
-	if err != nil {
-		// Fallback al directorio actual
-		return "channels.json"
-	}
-	exeDir := filepath.Dir(exePath)
-	return filepath.Join(exeDir, "channels.json")
-}
-
-// SaveChannels guarda la configuración de canales
-func SaveChannels(channels interface{}) error {
-	channelsPath := GetChannelsPath()
-
-	// Crear directorio si no existe
-	os.MkdirAll(filepath.Dir(channelsPath), 0755)
-
-	data, err := json.MarshalIndent(channels, "", "  ")
-	if err != nil {
+	if err := schema.Save(GetConfigPath(), data); err != nil {
 		return err
 	}
-
-	return os.WriteFile(channelsPath, data, 0644)
-}
-
-// LoadChannels carga la configuración de canales
-func LoadChannels() ([]byte, error) {
-	channelsPath := GetChannelsPath()
-
-	return os.ReadFile(channelsPath)
+	rememberFile(file)
+	return nil
 }
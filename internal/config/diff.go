@@ -0,0 +1,103 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ApplyMode cómo se aplica el cambio de un campo
+type ApplyMode string
+
+const (
+	ApplyHot      ApplyMode = "hot"      // Se aplica al momento
+	ApplyChannels ApplyMode = "channels" // Afecta a los streams nuevos; los activos deben reiniciarse
+	ApplyRestart  ApplyMode = "restart"  // Requiere reiniciar la aplicación
+)
+
+// fieldModes campos que no se aplican en caliente (el resto son ApplyHot)
+var fieldModes = map[string]ApplyMode{
+	// Parámetros con los que se lanza FFmpeg
+	"ffmpegPath":          ApplyChannels,
+	"defaultVideoBitrate": ApplyChannels,
+	"defaultAudioBitrate": ApplyChannels,
+	"defaultFrameRate":    ApplyChannels,
+	"videoEncoder":        ApplyChannels,
+	"encoderPreset":       ApplyChannels,
+	"encoderProfile":      ApplyChannels,
+	"encoderTune":         ApplyChannels,
+	"gopSize":             ApplyChannels,
+	"bFrames":             ApplyChannels,
+	"bitrateMode":         ApplyChannels,
+	"maxBitrate":          ApplyChannels,
+	"bufferSize":          ApplyChannels,
+	"crf":                 ApplyChannels,
+	"srtLatency":          ApplyChannels,
+	"srtRecvBuffer":       ApplyChannels,
+	"srtSendBuffer":       ApplyChannels,
+	"srtOverheadBW":       ApplyChannels,
+	"srtPeerIdleTime":     ApplyChannels,
+
+	// Servicios que se inician una sola vez al arrancar
+	"webSocketPort":     ApplyRestart,
+	"oscEnabled":        ApplyRestart,
+	"oscPort":           ApplyRestart,
+	"mqttEnabled":       ApplyRestart,
+	"mqttBroker":        ApplyRestart,
+	"mqttClientId":      ApplyRestart,
+	"mqttUsername":      ApplyRestart,
+	"mqttPassword":      ApplyRestart,
+	"mqttTopicPrefix":   ApplyRestart,
+	"mqttStatsInterval": ApplyRestart,
+	"webhookMaxRetries": ApplyRestart,
+}
+
+// Change campo modificado entre dos configuraciones
+type Change struct {
+	Field string      `json:"field"` // Nombre JSON del campo
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+	Apply ApplyMode   `json:"apply"`
+}
+
+// Diff lista los campos que cambian de old a new, en el orden de Config
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if field == "" || field == "-" || field == "version" {
+			continue
+		}
+
+		a, b := ov.Field(i), nv.Field(i)
+		if equalValues(a, b) {
+			continue
+		}
+
+		mode, ok := fieldModes[field]
+		if !ok {
+			mode = ApplyHot
+		}
+		changes = append(changes, Change{Field: field, Old: a.Interface(), New: b.Interface(), Apply: mode})
+	}
+	return changes
+}
+
+// equalValues compara dos campos (una lista vacía equivale a nil)
+func equalValues(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// HasMode indica si algún cambio se aplica con el modo indicado
+func HasMode(changes []Change, mode ApplyMode) bool {
+	for _, c := range changes {
+		if c.Apply == mode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package config
+
+// LogLevels niveles de log aceptados, de menor a mayor gravedad
+var LogLevels = []string{"trace", "debug", "info", "warn", "error"}
+
+// LogSubsystems subsistemas con nivel de log propio
+var LogSubsystems = []string{"app", "websocket", "ffmpeg", "channel"}
+
+// LogLevelField campo con el nivel de log de un subsistema, para leerlo o
+// cambiarlo (nil si el subsistema no existe)
+func (c *Config) LogLevelField(subsystem string) *string {
+	switch subsystem {
+	case "app":
+		return &c.LogLevelApp
+	case "websocket":
+		return &c.LogLevelWebSocket
+	case "ffmpeg":
+		return &c.LogLevelFFmpeg
+	case "channel":
+		return &c.LogLevelChannel
+	}
+	return nil
+}
+
+// LogLevelFieldName nombre JSON del campo de nivel de un subsistema
+func LogLevelFieldName(subsystem string) string {
+	switch subsystem {
+	case "app":
+		return "logLevelApp"
+	case "websocket":
+		return "logLevelWebSocket"
+	case "ffmpeg":
+		return "logLevelFFmpeg"
+	case "channel":
+		return "logLevelChannel"
+	}
+	return ""
+}
+
+// SubsystemLogLevel nivel efectivo de un subsistema: el suyo o, si está
+// vacío, logLevel
+func (c *Config) SubsystemLogLevel(subsystem string) string {
+	if field := c.LogLevelField(subsystem); field != nil && *field != "" {
+		return *field
+	}
+	return c.LogLevel
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// EnvPrefix prefijo de las variables de entorno que imponen campos de Config
+// (ej: webSocketPort -> SRTSTREAM_WEB_SOCKET_PORT)
+const EnvPrefix = "SRTSTREAM_"
+
+// override valor impuesto a un campo desde fuera de config.json
+type override struct {
+	value  string
+	source string // Variable de entorno o flag que lo impuso
+}
+
+var (
+	overridesMutex sync.RWMutex
+	overrides      = map[string]override{} // Campo JSON -> valor impuesto
+	fileConfig     *Config                 // Último contenido conocido de config.json (sin overrides)
+)
+
+// configField campo de Config que se puede imponer
+type configField struct {
+	name  string // Nombre JSON
+	index int
+	kind  reflect.Type
+}
+
+// configFields campos de Config en orden de declaración (sin version)
+func configFields() []configField {
+	t := reflect.TypeOf(Config{})
+	fields := make([]configField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == "version" {
+			continue
+		}
+		fields = append(fields, configField{name: name, index: i, kind: t.Field(i).Type})
+	}
+	return fields
+}
+
+// EnvName variable de entorno de un campo (webSocketPort -> SRTSTREAM_WEB_SOCKET_PORT)
+func EnvName(field string) string {
+	return EnvPrefix + strings.ToUpper(splitWords(field, "_"))
+}
+
+// FlagName flag de un campo (webSocketPort -> web-socket-port)
+func FlagName(field string) string {
+	return strings.ToLower(splitWords(field, "-"))
+}
+
+// keptWords palabras con mayúsculas internas que no se separan
+// (logLevelFFmpeg -> LOG_LEVEL_FFMPEG, no LOG_LEVEL_F_FMPEG)
+var keptWords = []string{"FFmpeg"}
+
+// splitWords separa con sep las palabras de un nombre camelCase
+// (srtOverheadBW -> srt_Overhead_BW)
+func splitWords(name, sep string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		if word := keptWordAt(runes[i:]); word != "" {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			b.WriteString(word)
+			i += len([]rune(word)) - 1
+			continue
+		}
+
+		r := runes[i]
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteString(sep)
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// keptWordAt retorna la palabra de keptWords con la que empieza rest ("" si ninguna)
+func keptWordAt(rest []rune) string {
+	for _, word := range keptWords {
+		if strings.HasPrefix(string(rest), word) {
+			return word
+		}
+	}
+	return ""
+}
+
+// fieldFlag flag que guarda el texto recibido para un campo
+type fieldFlag struct {
+	field  configField
+	values map[string]string
+}
+
+func (f *fieldFlag) String() string { return "" }
+
+func (f *fieldFlag) Set(value string) error {
+	f.values[f.field.name] = value
+	return nil
+}
+
+// IsBoolFlag permite --auto-restart sin valor
+func (f *fieldFlag) IsBoolFlag() bool { return f.field.kind.Kind() == reflect.Bool }
+
+// RegisterFlags registra un flag por cada campo de Config. El mapa retornado
+// (campo -> texto) se completa al parsear los argumentos y se pasa a SetOverrides.
+func RegisterFlags(flags *flag.FlagSet) map[string]string {
+	values := map[string]string{}
+	for _, field := range configFields() {
+		usage := fmt.Sprintf("config %s (también %s)", field.name, EnvName(field.name))
+		flags.Var(&fieldFlag{field: field, values: values}, FlagName(field.name), usage)
+	}
+	return values
+}
+
+// SetOverrides fija los campos impuestos por variables de entorno SRTSTREAM_* y
+// por flags (los flags tienen prioridad). Orden de precedencia final:
+// flags > entorno > config.json > Default(). Retorna un ValidationError si
+// algún valor no se puede convertir al tipo del campo.
+func SetOverrides(flagValues map[string]string) error {
+	set := map[string]override{}
+	for _, field := range configFields() {
+		if value, ok := os.LookupEnv(EnvName(field.name)); ok {
+			set[field.name] = override{value: value, source: EnvName(field.name)}
+		}
+		if value, ok := flagValues[field.name]; ok {
+			set[field.name] = override{value: value, source: "--" + FlagName(field.name)}
+		}
+	}
+
+	// Comprobar que cada valor se puede convertir antes de aceptarlos
+	var errs ValidationError
+	probe := reflect.ValueOf(Default()).Elem()
+	for _, field := range configFields() {
+		if o, ok := set[field.name]; ok {
+			if err := setField(probe.Field(field.index), o.value); err != nil {
+				errs = append(errs, FieldError{Field: field.name, Message: fmt.Sprintf("%s: %v", o.source, err)})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	overridesMutex.Lock()
+	overrides = set
+	overridesMutex.Unlock()
+	return nil
+}
+
+// Overridden campos impuestos desde fuera de config.json (campo -> variable o flag)
+func Overridden() map[string]string {
+	overridesMutex.RLock()
+	defer overridesMutex.RUnlock()
+
+	sources := make(map[string]string, len(overrides))
+	for field, o := range overrides {
+		sources[field] = o.source
+	}
+	return sources
+}
+
+// ApplyOverrides impone sobre cfg los valores de entorno y flags
+func ApplyOverrides(cfg *Config) {
+	overridesMutex.RLock()
+	defer overridesMutex.RUnlock()
+
+	v := reflect.ValueOf(cfg).Elem()
+	for _, field := range configFields() {
+		if o, ok := overrides[field.name]; ok {
+			setField(v.Field(field.index), o.value) // Ya verificado en SetOverrides
+		}
+	}
+}
+
+// rememberFile guarda el contenido de config.json antes de aplicar overrides
+func rememberFile(cfg *Config) {
+	snapshot := *cfg
+	overridesMutex.Lock()
+	fileConfig = &snapshot
+	overridesMutex.Unlock()
+}
+
+// withoutOverrides copia cfg dejando en los campos impuestos el valor que tenía
+// config.json, para que guardar no persista valores de entorno ni flags
+func withoutOverrides(cfg *Config) *Config {
+	out := *cfg
+
+	overridesMutex.RLock()
+	defer overridesMutex.RUnlock()
+	if len(overrides) == 0 {
+		return &out
+	}
+
+	base := fileConfig
+	if base == nil {
+		base = Default()
+	}
+	ov, bv := reflect.ValueOf(&out).Elem(), reflect.ValueOf(base).Elem()
+	for _, field := range configFields() {
+		if _, ok := overrides[field.name]; ok {
+			ov.Field(field.index).Set(bv.Field(field.index))
+		}
+	}
+	return &out
+}
+
+// setField convierte raw al tipo del campo: números, true/false, listas
+// separadas por comas (o JSON) y JSON para listas de objetos (webhooks)
+func setField(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("número entero inválido %q", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("booleano inválido %q (true/false)", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		target := reflect.New(v.Type())
+		if v.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(raw, "[") {
+			items := []string{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			v.Set(reflect.ValueOf(items))
+			return nil
+		}
+		if err := json.Unmarshal([]byte(raw), target.Interface()); err != nil {
+			return fmt.Errorf("se esperaba una lista JSON: %v", err)
+		}
+		v.Set(target.Elem())
+	default:
+		return fmt.Errorf("tipo %s no soportado", v.Type())
+	}
+	return nil
+}
+
+// Effective configuración efectiva sin modificar el disco: config.json (o los
+// valores por defecto si no existe) con entorno y flags aplicados
+func Effective() (*Config, error) {
+	cfg, err := Read()
+	if errors.Is(err, fs.ErrNotExist) {
+		cfg = Default()
+		ApplyOverrides(cfg)
+		return cfg, nil
+	}
+	return cfg, err
+}
+
+// Print escribe la configuración como JSON, con los secretos ocultos
+func Print(w io.Writer, cfg *Config) error {
+	masked := *cfg
+	if masked.MQTTPassword != "" {
+		masked.MQTTPassword = "***"
+	}
+	if masked.WSAdminToken != "" {
+		masked.WSAdminToken = "***"
+	}
+	masked.Webhooks = make([]WebhookTarget, len(cfg.Webhooks))
+	for i, target := range cfg.Webhooks {
+		if target.Secret != "" {
+			target.Secret = "***"
+		}
+		masked.Webhooks[i] = target
+	}
+
+	data, err := json.MarshalIndent(&masked, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"flag"
+	"testing"
+)
+
+// overrideNames variable de entorno y flag esperados para cada campo de Config
+var overrideNames = []struct {
+	field string
+	env   string
+	flag  string
+}{
+	{"webSocketPort", "SRTSTREAM_WEB_SOCKET_PORT", "web-socket-port"},
+	{"ffmpegPath", "SRTSTREAM_FFMPEG_PATH", "ffmpeg-path"},
+	{"autoRestart", "SRTSTREAM_AUTO_RESTART", "auto-restart"},
+	{"wsChannelManagement", "SRTSTREAM_WS_CHANNEL_MANAGEMENT", "ws-channel-management"},
+	{"wsAdminToken", "SRTSTREAM_WS_ADMIN_TOKEN", "ws-admin-token"},
+	{"clientChannelGracePeriod", "SRTSTREAM_CLIENT_CHANNEL_GRACE_PERIOD", "client-channel-grace-period"},
+	{"restartMaxAttempts", "SRTSTREAM_RESTART_MAX_ATTEMPTS", "restart-max-attempts"},
+	{"restartInitialDelay", "SRTSTREAM_RESTART_INITIAL_DELAY", "restart-initial-delay"},
+	{"restartMaxDelay", "SRTSTREAM_RESTART_MAX_DELAY", "restart-max-delay"},
+	{"restartResetWindow", "SRTSTREAM_RESTART_RESET_WINDOW", "restart-reset-window"},
+	{"startTimeout", "SRTSTREAM_START_TIMEOUT", "start-timeout"},
+	{"stopTimeout", "SRTSTREAM_STOP_TIMEOUT", "stop-timeout"},
+	{"stopGracePeriod", "SRTSTREAM_STOP_GRACE_PERIOD", "stop-grace-period"},
+	{"defaultVideoBitrate", "SRTSTREAM_DEFAULT_VIDEO_BITRATE", "default-video-bitrate"},
+	{"defaultAudioBitrate", "SRTSTREAM_DEFAULT_AUDIO_BITRATE", "default-audio-bitrate"},
+	{"defaultFrameRate", "SRTSTREAM_DEFAULT_FRAME_RATE", "default-frame-rate"},
+	{"testPatternPath", "SRTSTREAM_TEST_PATTERN_PATH", "test-pattern-path"},
+	{"srtPrefix", "SRTSTREAM_SRT_PREFIX", "srt-prefix"},
+	{"srtGroup", "SRTSTREAM_SRT_GROUP", "srt-group"},
+	{"srtPortRanges", "SRTSTREAM_SRT_PORT_RANGES", "srt-port-ranges"},
+	{"defaultVideoPath", "SRTSTREAM_DEFAULT_VIDEO_PATH", "default-video-path"},
+	{"logPath", "SRTSTREAM_LOG_PATH", "log-path"},
+	{"logLevel", "SRTSTREAM_LOG_LEVEL", "log-level"},
+	{"logLevelApp", "SRTSTREAM_LOG_LEVEL_APP", "log-level-app"},
+	{"logLevelWebSocket", "SRTSTREAM_LOG_LEVEL_WEB_SOCKET", "log-level-web-socket"},
+	{"logLevelFFmpeg", "SRTSTREAM_LOG_LEVEL_FFMPEG", "log-level-ffmpeg"},
+	{"logLevelChannel", "SRTSTREAM_LOG_LEVEL_CHANNEL", "log-level-channel"},
+	{"logToFile", "SRTSTREAM_LOG_TO_FILE", "log-to-file"},
+	{"logFormat", "SRTSTREAM_LOG_FORMAT", "log-format"},
+	{"logMaxSizeMB", "SRTSTREAM_LOG_MAX_SIZE_MB", "log-max-size-mb"},
+	{"logRotateHours", "SRTSTREAM_LOG_ROTATE_HOURS", "log-rotate-hours"},
+	{"logMaxFiles", "SRTSTREAM_LOG_MAX_FILES", "log-max-files"},
+	{"logMaxAgeDays", "SRTSTREAM_LOG_MAX_AGE_DAYS", "log-max-age-days"},
+	{"ffmpegLogs", "SRTSTREAM_FFMPEG_LOGS", "ffmpeg-logs"},
+	{"theme", "SRTSTREAM_THEME", "theme"},
+	{"language", "SRTSTREAM_LANGUAGE", "language"},
+	{"maxLogLines", "SRTSTREAM_MAX_LOG_LINES", "max-log-lines"},
+	{"videoEncoder", "SRTSTREAM_VIDEO_ENCODER", "video-encoder"},
+	{"encoderPreset", "SRTSTREAM_ENCODER_PRESET", "encoder-preset"},
+	{"encoderProfile", "SRTSTREAM_ENCODER_PROFILE", "encoder-profile"},
+	{"encoderTune", "SRTSTREAM_ENCODER_TUNE", "encoder-tune"},
+	{"gopSize", "SRTSTREAM_GOP_SIZE", "gop-size"},
+	{"bFrames", "SRTSTREAM_B_FRAMES", "b-frames"},
+	{"bitrateMode", "SRTSTREAM_BITRATE_MODE", "bitrate-mode"},
+	{"maxBitrate", "SRTSTREAM_MAX_BITRATE", "max-bitrate"},
+	{"bufferSize", "SRTSTREAM_BUFFER_SIZE", "buffer-size"},
+	{"crf", "SRTSTREAM_CRF", "crf"},
+	{"srtLatency", "SRTSTREAM_SRT_LATENCY", "srt-latency"},
+	{"srtRecvBuffer", "SRTSTREAM_SRT_RECV_BUFFER", "srt-recv-buffer"},
+	{"srtSendBuffer", "SRTSTREAM_SRT_SEND_BUFFER", "srt-send-buffer"},
+	{"srtOverheadBW", "SRTSTREAM_SRT_OVERHEAD_BW", "srt-overhead-bw"},
+	{"srtPeerIdleTime", "SRTSTREAM_SRT_PEER_IDLE_TIME", "srt-peer-idle-time"},
+	{"oscEnabled", "SRTSTREAM_OSC_ENABLED", "osc-enabled"},
+	{"oscPort", "SRTSTREAM_OSC_PORT", "osc-port"},
+	{"oscFeedbackTargets", "SRTSTREAM_OSC_FEEDBACK_TARGETS", "osc-feedback-targets"},
+	{"mqttEnabled", "SRTSTREAM_MQTT_ENABLED", "mqtt-enabled"},
+	{"mqttBroker", "SRTSTREAM_MQTT_BROKER", "mqtt-broker"},
+	{"mqttClientId", "SRTSTREAM_MQTT_CLIENT_ID", "mqtt-client-id"},
+	{"mqttUsername", "SRTSTREAM_MQTT_USERNAME", "mqtt-username"},
+	{"mqttPassword", "SRTSTREAM_MQTT_PASSWORD", "mqtt-password"},
+	{"mqttTopicPrefix", "SRTSTREAM_MQTT_TOPIC_PREFIX", "mqtt-topic-prefix"},
+	{"mqttStatsInterval", "SRTSTREAM_MQTT_STATS_INTERVAL", "mqtt-stats-interval"},
+	{"webhooks", "SRTSTREAM_WEBHOOKS", "webhooks"},
+	{"webhookMaxRetries", "SRTSTREAM_WEBHOOK_MAX_RETRIES", "webhook-max-retries"},
+	{"activePreset", "SRTSTREAM_ACTIVE_PRESET", "active-preset"},
+}
+
+func TestOverrideNames(t *testing.T) {
+	expected := make(map[string]bool, len(overrideNames))
+	envs := make(map[string]string)
+	flags := make(map[string]string)
+	for _, tt := range overrideNames {
+		expected[tt.field] = true
+		if got := EnvName(tt.field); got != tt.env {
+			t.Errorf("EnvName(%q) = %q, se esperaba %q", tt.field, got, tt.env)
+		}
+		if got := FlagName(tt.field); got != tt.flag {
+			t.Errorf("FlagName(%q) = %q, se esperaba %q", tt.field, got, tt.flag)
+		}
+		if other, ok := envs[tt.env]; ok {
+			t.Errorf("%s repetida en %s y %s", tt.env, other, tt.field)
+		}
+		if other, ok := flags[tt.flag]; ok {
+			t.Errorf("--%s repetido en %s y %s", tt.flag, other, tt.field)
+		}
+		envs[tt.env], flags[tt.flag] = tt.field, tt.field
+	}
+
+	// Un campo nuevo debe añadirse a la tabla
+	for _, field := range configFields() {
+		if !expected[field.name] {
+			t.Errorf("falta %q en overrideNames", field.name)
+		}
+		delete(expected, field.name)
+	}
+	for field := range expected {
+		t.Errorf("%q no es un campo de Config", field)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := map[string]string{
+		"webSocketPort":  "web_Socket_Port",
+		"srtOverheadBW":  "srt_Overhead_BW",
+		"logMaxSizeMB":   "log_Max_Size_MB",
+		"logLevelFFmpeg": "log_Level_FFmpeg",
+		"FFmpegPath":     "FFmpeg_Path",
+		"HTTPServer":     "HTTP_Server",
+		"bFrames":        "b_Frames",
+		"crf":            "crf",
+	}
+	for name, want := range tests {
+		if got := splitWords(name, "_"); got != want {
+			t.Errorf("splitWords(%q) = %q, se esperaba %q", name, got, want)
+		}
+	}
+}
+
+func TestOverridesFromEnvAndFlags(t *testing.T) {
+	t.Cleanup(func() { SetOverrides(nil) })
+	t.Setenv("SRTSTREAM_LOG_LEVEL_FFMPEG", "debug")
+	t.Setenv("SRTSTREAM_SRT_OVERHEAD_BW", "40")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	values := RegisterFlags(flags)
+	if err := flags.Parse([]string{"--srt-overhead-bw=50", "--auto-restart=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetOverrides(values); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	ApplyOverrides(cfg)
+	if cfg.LogLevelFFmpeg != "debug" || cfg.SRTOverheadBW != 50 || cfg.AutoRestart {
+		t.Errorf("logLevelFFmpeg=%q srtOverheadBW=%d autoRestart=%v", cfg.LogLevelFFmpeg, cfg.SRTOverheadBW, cfg.AutoRestart)
+	}
+
+	want := map[string]string{
+		"logLevelFFmpeg": "SRTSTREAM_LOG_LEVEL_FFMPEG",
+		"srtOverheadBW":  "--srt-overhead-bw", // El flag tiene prioridad sobre el entorno
+		"autoRestart":    "--auto-restart",
+	}
+	got := Overridden()
+	for field, source := range want {
+		if got[field] != source {
+			t.Errorf("Overridden()[%q] = %q, se esperaba %q", field, got[field], source)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("Overridden() = %v", got)
+	}
+}
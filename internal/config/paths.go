@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// Variables de entorno equivalentes a --data-dir e --instance
+const (
+	EnvDataDir  = "SRTSTREAM_DATA_DIR"
+	EnvInstance = "SRTSTREAM_INSTANCE"
+)
+
+// appDirName carpeta de la aplicación dentro del directorio de configuración del usuario
+const appDirName = "servidor-stream"
+
+// instanceNamePattern nombres de instancia válidos (se usan como nombre de carpeta)
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+var (
+	pathsMutex sync.RWMutex
+	dataDir    string // Directorio de datos resuelto (vacío = sin inicializar)
+	instance   string // Nombre de la instancia (vacío = instancia por defecto)
+)
+
+// InitDataDir resuelve y crea el directorio de datos de la instancia:
+//
+//  1. dir (--data-dir) o la variable SRTSTREAM_DATA_DIR
+//  2. junto al ejecutable si ya hay un config.json allí y se puede escribir
+//     (instalaciones portables anteriores)
+//  3. el directorio de configuración del usuario (%AppData%, ~/.config, ...)
+//
+// Con una instancia con nombre (name, --instance o SRTSTREAM_INSTANCE) los
+// archivos van en <directorio>/instances/<nombre>, de forma que varias
+// instancias con distintos puertos y canales pueden convivir en un equipo.
+func InitDataDir(dir, name string) (string, error) {
+	if name == "" {
+		name = os.Getenv(EnvInstance)
+	}
+	if name != "" && !instanceNamePattern.MatchString(name) {
+		return "", fmt.Errorf("nombre de instancia inválido %q (letras, números, - y _)", name)
+	}
+
+	if dir == "" {
+		dir = os.Getenv(EnvDataDir)
+	}
+	if dir == "" {
+		dir = defaultDataDir()
+	}
+	if name != "" {
+		dir = filepath.Join(dir, "instances", name)
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("no se pudo crear el directorio de datos %s: %w", dir, err)
+	}
+
+	pathsMutex.Lock()
+	dataDir = dir
+	instance = name
+	pathsMutex.Unlock()
+
+	return dir, nil
+}
+
+// defaultDataDir directorio de datos cuando no se indica ninguno
+func defaultDataDir() string {
+	exeDir := GetExecutablePath()
+	if exeDir != "" {
+		if _, err := os.Stat(filepath.Join(exeDir, "config.json")); err == nil {
+			if isWritableDir(exeDir) {
+				return exeDir // Instalación portable
+			}
+		}
+	}
+
+	userDir, err := os.UserConfigDir()
+	if err != nil {
+		if exeDir != "" {
+			return exeDir
+		}
+		return "."
+	}
+	dir := filepath.Join(userDir, appDirName)
+
+	// Instalación anterior en una carpeta de solo lectura: copiar sus archivos
+	if exeDir != "" {
+		seedDataDir(dir, exeDir)
+	}
+	return dir
+}
+
+// seedDataDir copia config.json y channels.json de una instalación anterior
+// junto al ejecutable si el directorio de datos aún no los tiene
+func seedDataDir(dir, legacyDir string) {
+	for _, name := range []string{"config.json", "channels.json"} {
+		dst := filepath.Join(dir, name)
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		src, err := os.Open(filepath.Join(legacyDir, name))
+		if err != nil {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err == nil {
+			if out, err := os.Create(dst); err == nil {
+				_, err = io.Copy(out, src)
+				out.Close()
+				if err == nil {
+					log.Printf("[Config] %s copiado de %s a %s", name, legacyDir, dir)
+				}
+			}
+		}
+		src.Close()
+	}
+}
+
+// isWritableDir indica si se pueden crear archivos en dir
+func isWritableDir(dir string) bool {
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// DataDir retorna el directorio de datos (lo resuelve con los valores por
+// defecto si no se llamó a InitDataDir)
+func DataDir() string {
+	pathsMutex.RLock()
+	dir := dataDir
+	pathsMutex.RUnlock()
+	if dir != "" {
+		return dir
+	}
+
+	dir, err := InitDataDir("", "")
+	if err != nil {
+		log.Printf("[Config] %v", err)
+		return "."
+	}
+	return dir
+}
+
+// Instance retorna el nombre de la instancia (vacío = instancia por defecto)
+func Instance() string {
+	pathsMutex.RLock()
+	defer pathsMutex.RUnlock()
+	return instance
+}
+
+// GetConfigPath retorna la ruta del archivo de configuración
+func GetConfigPath() string {
+	return filepath.Join(DataDir(), "config.json")
+}
+
+// GetChannelsPath retorna la ruta del archivo de canales
+func GetChannelsPath() string {
+	return filepath.Join(DataDir(), "channels.json")
+}
+
+// GetPresetsDir retorna el directorio de presets de canales
+func GetPresetsDir() string {
+	return filepath.Join(DataDir(), "presets")
+}
+
+// GetAuditDir retorna el directorio del registro de auditoría
+func GetAuditDir() string {
+	return filepath.Join(DataDir(), "audit")
+}
+
+// GetLogDir retorna el directorio de logs: logPath o <datos>/logs
+func GetLogDir(logPath string) string {
+	if logPath != "" {
+		return logPath
+	}
+	return filepath.Join(DataDir(), "logs")
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// PresetFields campos de config.json que guardan los presets: parámetros de
+// emisión. Quedan fuera las rutas locales (ffmpegPath, patrón) y los servicios,
+// de modo que un preset importado de otro equipo no los cambia.
+var PresetFields = []string{
+	"defaultVideoBitrate",
+	"defaultAudioBitrate",
+	"defaultFrameRate",
+	"videoEncoder",
+	"encoderPreset",
+	"encoderProfile",
+	"encoderTune",
+	"gopSize",
+	"bFrames",
+	"bitrateMode",
+	"maxBitrate",
+	"bufferSize",
+	"crf",
+	"srtLatency",
+	"srtRecvBuffer",
+	"srtSendBuffer",
+	"srtOverheadBW",
+	"srtPeerIdleTime",
+	"srtPrefix",
+	"srtPortRanges",
+}
+
+// PresetValues valores actuales de los campos de preset
+func (c *Config) PresetValues() (map[string]json.RawMessage, error) {
+	all, err := c.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]json.RawMessage, len(PresetFields))
+	for _, field := range PresetFields {
+		if value, ok := all[field]; ok {
+			values[field] = value
+		}
+	}
+	return values, nil
+}
+
+// WithPresetValues copia de la configuración con los campos de preset de
+// values reemplazados (el resto de claves se ignora)
+func (c *Config) WithPresetValues(values map[string]json.RawMessage) (*Config, error) {
+	all, err := c.fields()
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range values {
+		if slices.Contains(PresetFields, field) {
+			all[field] = value
+		}
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return nil, err
+	}
+	out := &Config{}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// fields campos de la configuración en JSON
+func (c *Config) fields() (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	return all, json.Unmarshal(data, &all)
+}
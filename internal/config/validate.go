@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"servidor-stream/internal/ports"
+)
+
+// Valores aceptados en los campos de encoding ("" = valor por defecto del encoder)
+var (
+	videoEncoders   = []string{"libx264", "h264_nvenc", "h264_qsv", "h264_amf"}
+	encoderPresets  = []string{"", "ultrafast", "superfast", "veryfast", "faster", "fast", "medium", "slow", "slower", "veryslow"}
+	encoderProfiles = []string{"", "baseline", "main", "high"}
+	encoderTunes    = []string{"", "zerolatency", "film", "animation", "grain", "stillimage", "fastdecode"}
+	bitrateModes    = []string{"", "cbr", "vbr"}
+	themes          = []string{"dark", "light"}
+	logFormats      = []string{"text", "json"}
+	mqttSchemes     = []string{"tcp", "ssl", "tls", "mqtt", "mqtts", "ws", "wss"}
+)
+
+// bitratePattern bitrate en formato FFmpeg: número con sufijo k, M o G opcional (ej: 5M, 192k, 2.5M)
+var bitratePattern = regexp.MustCompile(`^\d+(\.\d+)?[kKmMgG]?$`)
+
+// minAdminTokenLength longitud mínima de wsAdminToken (se envía en la URL o en
+// el auth de Socket.IO y no debe poder adivinarse)
+const minAdminTokenLength = 16
+
+// FieldError error de validación de un campo (Field es el nombre JSON)
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError errores de validación de una configuración
+type ValidationError []FieldError
+
+func (v ValidationError) Error() string {
+	parts := make([]string, len(v))
+	for i, fe := range v {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "configuración inválida: " + strings.Join(parts, "; ")
+}
+
+// validator acumula errores por campo
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) port(field string, port int) {
+	if port < 1 || port > 65535 {
+		v.add(field, "puerto %d fuera de rango (1-65535)", port)
+	}
+}
+
+func (v *validator) between(field string, value, min, max int) {
+	if value < min || value > max {
+		v.add(field, "%d fuera de rango (%d-%d)", value, min, max)
+	}
+}
+
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.add(field, "no puede ser negativo (%d)", value)
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed []string) {
+	if slices.Contains(allowed, value) {
+		return
+	}
+	named := make([]string, 0, len(allowed))
+	for _, a := range allowed {
+		if a != "" {
+			named = append(named, a)
+		}
+	}
+	v.add(field, "valor %q no soportado (%s)", value, strings.Join(named, ", "))
+}
+
+func (v *validator) bitrate(field, value string, required bool) {
+	if value == "" {
+		if required {
+			v.add(field, "es obligatorio")
+		}
+		return
+	}
+	if !bitratePattern.MatchString(value) {
+		v.add(field, "bitrate %q inválido (ej: 5M, 192k, 2500k)", value)
+	}
+}
+
+// Validate verifica todos los campos y retorna un ValidationError con los
+// errores encontrados (nil si la configuración es válida)
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	// Servidor
+	v.port("webSocketPort", c.WebSocketPort)
+	if strings.TrimSpace(c.FFmpegPath) == "" {
+		v.add("ffmpegPath", "es obligatorio")
+	}
+	if c.WSAdminToken != "" && len(c.WSAdminToken) < minAdminTokenLength {
+		v.add("wsAdminToken", "debe tener al menos %d caracteres", minAdminTokenLength)
+	}
+	v.nonNegative("clientChannelGracePeriod", c.ClientChannelGracePeriod)
+
+	// Reinicio, timeouts y detención
+	v.nonNegative("restartMaxAttempts", c.RestartMaxAttempts)
+	v.nonNegative("restartInitialDelay", c.RestartInitialDelay)
+	v.nonNegative("restartMaxDelay", c.RestartMaxDelay)
+	if c.RestartMaxDelay < c.RestartInitialDelay {
+		v.add("restartMaxDelay", "debe ser mayor o igual que restartInitialDelay (%d)", c.RestartInitialDelay)
+	}
+	v.nonNegative("restartResetWindow", c.RestartResetWindow)
+	v.nonNegative("startTimeout", c.StartTimeout)
+	v.nonNegative("stopTimeout", c.StopTimeout)
+	v.nonNegative("stopGracePeriod", c.StopGracePeriod)
+
+	// Video por defecto
+	v.bitrate("defaultVideoBitrate", c.DefaultVideoBitrate, true)
+	v.bitrate("defaultAudioBitrate", c.DefaultAudioBitrate, true)
+	v.between("defaultFrameRate", c.DefaultFrameRate, 1, 120)
+
+	// SRT
+	if _, err := ports.ParseRanges(c.SRTPortRanges); err != nil {
+		v.add("srtPortRanges", "%v", err)
+	}
+
+	// Niveles de log
+	v.oneOf("logLevel", c.LogLevel, LogLevels)
+	for _, subsystem := range LogSubsystems {
+		if level := *c.LogLevelField(subsystem); level != "" {
+			v.oneOf(LogLevelFieldName(subsystem), level, LogLevels)
+		}
+	}
+
+	// Logs en disco
+	v.oneOf("logFormat", c.LogFormat, logFormats)
+	v.nonNegative("logMaxSizeMB", c.LogMaxSizeMB)
+	v.nonNegative("logRotateHours", c.LogRotateHours)
+	v.nonNegative("logMaxFiles", c.LogMaxFiles)
+	v.nonNegative("logMaxAgeDays", c.LogMaxAgeDays)
+
+	// UI
+	v.oneOf("theme", c.Theme, themes)
+	v.nonNegative("maxLogLines", c.MaxLogLines)
+
+	// Encoding
+	v.oneOf("videoEncoder", c.VideoEncoder, videoEncoders)
+	v.oneOf("encoderPreset", c.EncoderPreset, encoderPresets)
+	v.oneOf("encoderProfile", c.EncoderProfile, encoderProfiles)
+	v.oneOf("encoderTune", c.EncoderTune, encoderTunes)
+	v.nonNegative("gopSize", c.GopSize)
+	v.between("bFrames", c.BFrames, 0, 16)
+
+	// Bitrate
+	v.oneOf("bitrateMode", c.BitrateMode, bitrateModes)
+	v.bitrate("maxBitrate", c.MaxBitrate, false)
+	v.bitrate("bufferSize", c.BufferSize, false)
+	v.between("crf", c.CRF, 0, 51)
+
+	// SRT avanzado
+	v.nonNegative("srtLatency", c.SRTLatency)
+	v.nonNegative("srtRecvBuffer", c.SRTRecvBuffer)
+	v.nonNegative("srtSendBuffer", c.SRTSendBuffer)
+	v.between("srtOverheadBW", c.SRTOverheadBW, 5, 100)
+	v.nonNegative("srtPeerIdleTime", c.SRTPeerIdleTime)
+
+	// OSC
+	v.port("oscPort", c.OSCPort)
+	for i, target := range c.OSCFeedbackTargets {
+		if strings.TrimSpace(target) == "" {
+			continue // Se ignoran al aplicar
+		}
+		if err := checkHostPort(target); err != nil {
+			v.add(fmt.Sprintf("oscFeedbackTargets[%d]", i), "%v", err)
+		}
+	}
+
+	// MQTT
+	if c.MQTTEnabled {
+		if err := checkURL(c.MQTTBroker, mqttSchemes); err != nil {
+			v.add("mqttBroker", "%v", err)
+		}
+		if c.MQTTStatsInterval < 1 {
+			v.add("mqttStatsInterval", "debe ser al menos 1 segundo (%d)", c.MQTTStatsInterval)
+		}
+	}
+
+	// Webhooks
+	for i, target := range c.Webhooks {
+		if err := checkURL(target.URL, []string{"http", "https"}); err != nil {
+			v.add(fmt.Sprintf("webhooks[%d].url", i), "%v", err)
+		}
+	}
+	v.nonNegative("webhookMaxRetries", c.WebhookMaxRetries)
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+// checkHostPort verifica un destino "host:puerto"
+func checkHostPort(target string) error {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(target))
+	if err != nil {
+		return fmt.Errorf("destino %q inválido (formato host:puerto)", target)
+	}
+	if host == "" {
+		return fmt.Errorf("destino %q sin host", target)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("puerto %q fuera de rango (1-65535)", port)
+	}
+	return nil
+}
+
+// checkURL verifica una URL absoluta con uno de los esquemas permitidos
+func checkURL(raw string, schemes []string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("URL %q inválida", raw)
+	}
+	if !slices.Contains(schemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("esquema %q no soportado (%s)", u.Scheme, strings.Join(schemes, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package ffmpeg
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorCode categoría estable de un error de FFmpeg (se envía a los clientes)
+type ErrorCode string
+
+const (
+	ErrorInputNotFound   ErrorCode = "input_not_found"     // El archivo de entrada no existe
+	ErrorPermission      ErrorCode = "permission_denied"   // Sin permiso para leer la entrada o abrir el puerto
+	ErrorDecode          ErrorCode = "decode_error"        // Entrada corrupta o formato no soportado
+	ErrorEncoderInit     ErrorCode = "encoder_init_failed" // El encoder no pudo inicializarse
+	ErrorPortInUse       ErrorCode = "port_in_use"         // El puerto SRT ya está ocupado
+	ErrorSRTDisconnected ErrorCode = "srt_disconnected"    // El receptor SRT cerró la conexión
+	ErrorSRTTimeout      ErrorCode = "srt_timeout"         // Timeout de conexión SRT
+	ErrorUnknown         ErrorCode = "ffmpeg_error"        // Fallo sin categoría conocida
+)
+
+// Retryable indica si tiene sentido reintentar automáticamente: un archivo
+// inexistente o un encoder que no inicializa fallarán igual en el reintento
+func (c ErrorCode) Retryable() bool {
+	switch c {
+	case ErrorInputNotFound, ErrorPermission, ErrorEncoderInit:
+		return false
+	}
+	return true
+}
+
+// errorPatterns fragmentos de stderr (en minúsculas) por categoría, en orden de
+// prioridad: el timeout SRT se evalúa antes que la desconexión genérica y los
+// permisos antes que el bind ("bind failed: Permission denied" en puertos < 1024).
+// Los mensajes genéricos llevan context: la línea debe contener además alguno de
+// esos fragmentos (ej: un "I/O error" solo es una desconexión SRT si se refiere a
+// la salida; leyendo la entrada es otra cosa). "Error muxing a packet" no se
+// clasifica: la desconexión ya se detecta por la salida con EIO (ClassifyExit).
+var errorPatterns = []struct {
+	code     ErrorCode
+	patterns []string
+	context  []string
+}{
+	{code: ErrorPermission, patterns: []string{
+		"permission denied",
+		"access is denied",
+	}},
+	{code: ErrorInputNotFound, patterns: []string{
+		"no such file or directory",
+		"the system cannot find the file",
+		"the system cannot find the path",
+	}},
+	{code: ErrorPortInUse, patterns: []string{
+		"address already in use",
+		"only one usage of each socket address",
+		"bind failed",
+	}},
+	{code: ErrorEncoderInit, patterns: []string{
+		"error while opening encoder",
+		"error initializing output stream",
+		"unknown encoder",
+		"no nvenc capable devices",
+		"cannot load nvcuda",
+		"openencodesessionex failed",
+		"could not open encoder",
+	}},
+	{code: ErrorEncoderInit, patterns: []string{
+		"failed to initialise",
+	}, context: []string{
+		"nvenc", "_qsv @", "_amf @", "_vaapi @", "[avhwdevicecontext @", "encoder",
+	}},
+	{code: ErrorSRTTimeout, patterns: []string{
+		"connection timed out",
+		"connection setup failure",
+	}},
+	{code: ErrorSRTDisconnected, patterns: []string{
+		"connection was broken",
+		"connection lost",
+	}},
+	{code: ErrorSRTDisconnected, patterns: []string{
+		"i/o error",
+	}, context: []string{
+		"srt://", "[srt @", "av_interleaved_write_frame",
+	}},
+	{code: ErrorDecode, patterns: []string{
+		"invalid data found when processing input",
+		"error while decoding",
+		"decode_slice_header error",
+		"moov atom not found",
+		"could not find codec parameters",
+	}},
+}
+
+// ClassifyLine retorna la categoría de una línea de stderr, o "" si la línea
+// no corresponde a un error conocido
+func ClassifyLine(line string) ErrorCode {
+	lower := strings.ToLower(line)
+	for _, group := range errorPatterns {
+		if len(group.context) > 0 && !containsAny(lower, group.context) {
+			continue
+		}
+		if containsAny(lower, group.patterns) {
+			return group.code
+		}
+	}
+	return ""
+}
+
+// containsAny indica si s contiene alguno de los fragmentos
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyExit retorna la categoría de la salida de un proceso. last es la
+// última categoría vista en stderr, más específica que el código de salida salvo
+// cuando éste indica EIO sobre el socket SRT (un error de decodificación
+// anterior no explica la salida).
+func ClassifyExit(err error, last ErrorCode) ErrorCode {
+	if err == nil {
+		return last
+	}
+
+	if isEIO(err) && last != ErrorSRTTimeout {
+		return ErrorSRTDisconnected
+	}
+	if last != "" {
+		return last
+	}
+	return ErrorUnknown
+}
+
+// isEIO detecta la salida por EIO (-5, 0xfffffffb en Windows): escritura
+// fallida sobre el socket SRT
+func isEIO(err error) bool {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code == -5 || uint32(code) == 0xfffffffb || code == 251 {
+			return true
+		}
+	}
+	return strings.Contains(err.Error(), "0xfffffffb")
+}
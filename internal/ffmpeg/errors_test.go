@@ -0,0 +1,94 @@
+package ffmpeg_test
+
+import (
+	"errors"
+	"testing"
+
+	"servidor-stream/internal/ffmpeg"
+	"servidor-stream/internal/ffmpeg/ffmpegtest"
+)
+
+func TestClassifyLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want ffmpeg.ErrorCode
+	}{
+		{"C:\\Videos\\intro.mp4: No such file or directory", ffmpeg.ErrorInputNotFound},
+		{"intro.mp4: The system cannot find the file specified.", ffmpeg.ErrorInputNotFound},
+		{"/mnt/videos/intro.mp4: Permission denied", ffmpeg.ErrorPermission},
+		{"C:\\Videos\\intro.mp4: Access is denied.", ffmpeg.ErrorPermission},
+		{"[srt @ 0x55] bind failed: Permission denied", ffmpeg.ErrorPermission},
+		{"[srt @ 0x55] bind failed: Address already in use", ffmpeg.ErrorPortInUse},
+		{"Only one usage of each socket address is normally permitted", ffmpeg.ErrorPortInUse},
+		{"[h264_nvenc @ 0x55] No NVENC capable devices found", ffmpeg.ErrorEncoderInit},
+		{"Unknown encoder 'h264_foo'", ffmpeg.ErrorEncoderInit},
+		{"Error while opening encoder for output stream #0:0", ffmpeg.ErrorEncoderInit},
+		{"[srt @ 0x55] Connection setup failure: connection timed out", ffmpeg.ErrorSRTTimeout},
+		{"av_interleaved_write_frame(): I/O error", ffmpeg.ErrorSRTDisconnected},
+		{"Error writing trailer of srt://0.0.0.0:9000?mode=listener: I/O error", ffmpeg.ErrorSRTDisconnected},
+		{"[h264_nvenc @ 0x55] Failed to initialise CUDA context", ffmpeg.ErrorEncoderInit},
+		{"[srt @ 0x55] Connection was broken", ffmpeg.ErrorSRTDisconnected},
+		{"in.mp4: Invalid data found when processing input", ffmpeg.ErrorDecode},
+		{"[mov,mp4,m4a,3gp,3g2,mj2 @ 0x55] moov atom not found", ffmpeg.ErrorDecode},
+		{"frame=  250 fps= 25 q=-1.0 size=    1024kB time=00:00:10.00 bitrate= 838.9kbits/s speed=1x", ""},
+		{"Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'in.mp4':", ""},
+		{"Past duration 0.999992 too large", ""},
+		// Mensajes genéricos fuera del contexto de la salida SRT o del encoder
+		{"Error during demuxing: I/O error", ""},
+		{"[in#0/mov,mp4,m4a,3gp,3g2,mj2 @ 0x55] Error reading input: I/O error", ""},
+		{"\\\\nas\\videos\\intro.mp4: I/O error", ""},
+		{"[out#0/mpegts @ 0x55] Error muxing a packet", ""},
+		{"[mpegts @ 0x55] Application provided invalid, non monotonically increasing dts to muxer", ""},
+		{"[Parsed_subtitles_0 @ 0x55] Failed to initialise font provider", ""},
+		{"[libass] Failed to initialise fontconfig", ""},
+	}
+	for _, tt := range tests {
+		if got := ffmpeg.ClassifyLine(tt.line); got != tt.want {
+			t.Errorf("ClassifyLine(%q) = %q, se esperaba %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyExit(t *testing.T) {
+	eio := &ffmpegtest.ExitError{Code: -5}
+	tests := []struct {
+		name string
+		err  error
+		last ffmpeg.ErrorCode
+		want ffmpeg.ErrorCode
+	}{
+		{"salida limpia", nil, "", ""},
+		{"salida limpia tras error de stderr", nil, ffmpeg.ErrorDecode, ffmpeg.ErrorDecode},
+		{"sin categoría", &ffmpegtest.ExitError{Code: 1}, "", ffmpeg.ErrorUnknown},
+		{"categoría de stderr", &ffmpegtest.ExitError{Code: 1}, ffmpeg.ErrorPortInUse, ffmpeg.ErrorPortInUse},
+		{"EIO", eio, "", ffmpeg.ErrorSRTDisconnected},
+		{"EIO manda sobre decodificación", eio, ffmpeg.ErrorDecode, ffmpeg.ErrorSRTDisconnected},
+		{"EIO tras timeout SRT", eio, ffmpeg.ErrorSRTTimeout, ffmpeg.ErrorSRTTimeout},
+		{"EIO en Windows", &ffmpegtest.ExitError{Code: 251}, "", ffmpeg.ErrorSRTDisconnected},
+		{"EIO en el mensaje", errors.New("exit status 0xfffffffb"), "", ffmpeg.ErrorSRTDisconnected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ffmpeg.ClassifyExit(tt.err, tt.last); got != tt.want {
+				t.Errorf("ClassifyExit(%v, %q) = %q, se esperaba %q", tt.err, tt.last, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorCodeRetryable(t *testing.T) {
+	for code, want := range map[ffmpeg.ErrorCode]bool{
+		ffmpeg.ErrorInputNotFound:   false,
+		ffmpeg.ErrorPermission:      false,
+		ffmpeg.ErrorEncoderInit:     false,
+		ffmpeg.ErrorPortInUse:       true,
+		ffmpeg.ErrorSRTDisconnected: true,
+		ffmpeg.ErrorSRTTimeout:      true,
+		ffmpeg.ErrorDecode:          true,
+		ffmpeg.ErrorUnknown:         true,
+	} {
+		if got := code.Retryable(); got != want {
+			t.Errorf("%s.Retryable() = %v, se esperaba %v", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package ffmpeg
+
+// BuildFFmpegArgs expone buildFFmpegArgs a las pruebas de ffmpeg_test (el
+// paquete externo evita el ciclo de importación con ffmpegtest)
+func (m *Manager) BuildFFmpegArgs(config StreamConfig) []string {
+	return m.buildFFmpegArgs(config)
+}
+
+// Supervisors retorna cuántos canales tienen un supervisor activo
+func (m *Manager) Supervisors() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return len(m.supervisors)
+}
@@ -0,0 +1,248 @@
+// Package ffmpegtest provee un FFmpeg simulado para probar ffmpeg.Manager sin
+// el ejecutable real: cada proceso escribe líneas de stderr configurables y
+// termina con el código de salida indicado.
+//
+//	runner := ffmpegtest.NewRunner(ffmpegtest.Script{
+//		Lines: []string{"Input #0, mov,mp4 from 'in.mp4':", "frame=  25 fps=25 ..."},
+//		Hold:  true,
+//	})
+//	manager.SetRunner(runner)
+package ffmpegtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"servidor-stream/internal/ffmpeg"
+)
+
+// Script comportamiento de un proceso simulado
+type Script struct {
+	Lines      []string      // Líneas escritas en stderr, en orden
+	LineDelay  time.Duration // Espera antes de cada línea
+	ExitCode   int           // Código de salida al terminar las líneas (si no Hold)
+	Hold       bool          // Seguir corriendo tras las líneas hasta "q", interrupt o kill
+	IgnoreQuit bool          // Ignorar "q" e interrupt (solo termina con kill)
+	StartErr   error         // Error al lanzar (ej: ejecutable no encontrado)
+}
+
+// ExitError error de salida con código distinto de cero
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	if e.Code == -1 {
+		return "signal: killed"
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode código de salida del proceso (-1 si fue matado)
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// Runner implementa ffmpeg.Runner con procesos simulados
+type Runner struct {
+	mutex      sync.Mutex
+	script     func(args []string) Script
+	calls      [][]string
+	probes     [][]string
+	nextPid    int
+	live       int    // Procesos lanzados que aún no terminaron
+	OutputData []byte // Salida de Output (test de encoder)
+	OutputErr  error  // Error de Output; nil = encoder disponible
+	// OutputFunc reemplaza OutputData/OutputErr (ej: una prueba de encoder lenta)
+	OutputFunc func(ctx context.Context, args []string) ([]byte, error)
+}
+
+// NewRunner crea un runner que usa el mismo script para todos los procesos
+func NewRunner(script Script) *Runner {
+	return NewRunnerFunc(func([]string) Script { return script })
+}
+
+// NewRunnerFunc crea un runner que elige el script según los argumentos
+func NewRunnerFunc(script func(args []string) Script) *Runner {
+	return &Runner{script: script, nextPid: 1000}
+}
+
+// Calls retorna los argumentos de cada proceso lanzado con Start
+func (r *Runner) Calls() [][]string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	calls := make([][]string, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+// Probes retorna los argumentos de cada ejecución de Output
+func (r *Runner) Probes() [][]string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	probes := make([][]string, len(r.probes))
+	copy(probes, r.probes)
+	return probes
+}
+
+// Running retorna cuántos procesos lanzados siguen vivos
+func (r *Runner) Running() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.live
+}
+
+// Output implementa ffmpeg.Runner
+func (r *Runner) Output(ctx context.Context, path string, args []string) ([]byte, error) {
+	r.mutex.Lock()
+	r.probes = append(r.probes, append([]string(nil), args...))
+	r.mutex.Unlock()
+	if r.OutputFunc != nil {
+		return r.OutputFunc(ctx, args)
+	}
+	return r.OutputData, r.OutputErr
+}
+
+// Start implementa ffmpeg.Runner
+func (r *Runner) Start(ctx context.Context, path string, args []string) (ffmpeg.Process, error) {
+	r.mutex.Lock()
+	r.calls = append(r.calls, append([]string(nil), args...))
+	r.nextPid++
+	pid := r.nextPid
+	r.mutex.Unlock()
+
+	script := r.script(args)
+	if script.StartErr != nil {
+		return nil, script.StartErr
+	}
+
+	r.mutex.Lock()
+	r.live++
+	r.mutex.Unlock()
+
+	stderrReader, stderrWriter := io.Pipe()
+	p := &process{
+		runner: r,
+		pid:    pid,
+		script: script,
+		stderr: stderrReader,
+		quit:   make(chan struct{}),
+		kill:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	p.stdin = &stdin{process: p}
+
+	go p.run(ctx, stderrWriter)
+	return p, nil
+}
+
+// process proceso simulado
+type process struct {
+	runner   *Runner
+	pid      int
+	script   Script
+	stdin    *stdin
+	stderr   *io.PipeReader
+	quit     chan struct{}
+	kill     chan struct{}
+	done     chan struct{}
+	quitOnce sync.Once
+	killOnce sync.Once
+	err      error
+}
+
+func (p *process) run(ctx context.Context, stderr *io.PipeWriter) {
+	defer close(p.done)
+	defer p.exited() // Antes de close(done): tras Wait, Running ya no lo cuenta
+	defer stderr.Close()
+
+	for _, line := range p.script.Lines {
+		if p.script.LineDelay > 0 {
+			timer := time.NewTimer(p.script.LineDelay)
+			select {
+			case <-timer.C:
+			case <-p.quit:
+				timer.Stop()
+				return
+			case <-p.kill:
+				timer.Stop()
+				p.err = &ExitError{Code: -1}
+				return
+			case <-ctx.Done():
+				timer.Stop()
+				p.err = &ExitError{Code: -1}
+				return
+			}
+		}
+		stderr.Write([]byte(line + "\n"))
+	}
+
+	if !p.script.Hold {
+		if p.script.ExitCode != 0 {
+			p.err = &ExitError{Code: p.script.ExitCode}
+		}
+		return
+	}
+
+	select {
+	case <-p.quit:
+	case <-p.kill:
+		p.err = &ExitError{Code: -1}
+	case <-ctx.Done():
+		p.err = &ExitError{Code: -1}
+	}
+}
+
+func (p *process) exited() {
+	p.runner.mutex.Lock()
+	p.runner.live--
+	p.runner.mutex.Unlock()
+}
+
+func (p *process) requestQuit() {
+	if p.script.IgnoreQuit {
+		return
+	}
+	p.quitOnce.Do(func() { close(p.quit) })
+}
+
+func (p *process) Pid() int              { return p.pid }
+func (p *process) Stdin() io.WriteCloser { return p.stdin }
+func (p *process) Stderr() io.Reader     { return p.stderr }
+
+func (p *process) Wait() error {
+	<-p.done
+	return p.err
+}
+
+func (p *process) Interrupt() error {
+	p.requestQuit()
+	return nil
+}
+
+func (p *process) Kill() error {
+	select {
+	case <-p.done:
+		return errors.New("os: process already finished")
+	default:
+	}
+	p.killOnce.Do(func() { close(p.kill) })
+	return nil
+}
+
+// stdin interpreta el comando interactivo "q" de FFmpeg
+type stdin struct {
+	process *process
+}
+
+func (s *stdin) Write(b []byte) (int, error) {
+	if strings.Contains(string(b), "q") {
+		s.process.requestQuit()
+	}
+	return len(b), nil
+}
+
+func (s *stdin) Close() error { return nil }
@@ -11,7 +11,6 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 )
 
@@ -24,14 +23,21 @@ const (
 	EventError    EventType = "error"
 	EventProgress EventType = "progress"
 	EventWarning  EventType = "warning"
+	EventReady    EventType = "ready" // Primeros frames codificados o listener SRT listo
 )
 
+// listenerReadyDelay tiempo que el proceso debe seguir vivo tras abrir la entrada
+// para considerar listo el listener SRT (en modo listener no hay frames hasta que
+// se conecta un receptor)
+const listenerReadyDelay = 1500 * time.Millisecond
+
 // Event representa un evento del proceso FFmpeg
 type Event struct {
-	Type      EventType
-	ChannelID string
-	Message   string
-	Data      map[string]interface{}
+	Type       EventType
+	ChannelID  string
+	Message    string
+	Data       map[string]interface{}
+	Generation uint64 // Proceso que originó el evento (0 = no asociado a un proceso)
 }
 
 // StreamConfig configuración para un stream SRT
@@ -47,6 +53,11 @@ type StreamConfig struct {
 	Width         int
 	Height        int
 	Loop          bool
+	// Salida
+	ScaleMode     string // letterbox, crop, stretch (default)
+	Interlace     string // progressive (default), tff, bff
+	PixelFormat   string // yuv420p (default), yuv422p, yuv444p, nv12
+	AudioChannels int    // default 2
 	// Configuración avanzada de encoding
 	VideoEncoder   string // libx264, h264_nvenc, h264_qsv, h264_amf
 	EncoderPreset  string // ultrafast, veryfast, fast, medium
@@ -74,7 +85,10 @@ type ProcessInfo struct {
 	IsRunning    bool
 	Progress     Progress
 	LastError    string
+	ErrorCode    ErrorCode // Categoría del último error reconocido en stderr
 	RestartCount int
+	SRTConnected bool   // Hay un receptor SRT conectado
+	Generation   uint64 // Identificador del proceso (crece con cada inicio)
 }
 
 // Progress progreso del proceso FFmpeg
@@ -90,23 +104,43 @@ type Progress struct {
 }
 
 // Manager gestor de procesos FFmpeg
+//
+// Las operaciones sobre un canal (iniciar, reemplazar, detener) se serializan
+// en su supervisor; cada proceso tiene una generación y los eventos de
+// procesos ya reemplazados se descartan.
 type Manager struct {
 	ffmpegPath   string
 	processes    map[string]*ffmpegProcess
+	supervisors  map[string]*supervisor
+	generation   uint64 // Última generación asignada (protegida por mutex)
 	mutex        sync.RWMutex
 	eventHandler func(Event)
+	stopGrace    time.Duration // Espera tras pedir la detención antes de matar el proceso
+	runner       Runner
+	openLog      func(channelID string) io.WriteCloser // Destino del stderr de cada proceso (nil = no guardar)
 }
 
+// DefaultStopGrace espera por defecto para que FFmpeg cierre el muxer
+const DefaultStopGrace = 3 * time.Second
+
+// killWait espera máxima tras Kill para que el sistema libere el proceso
+const killWait = 2 * time.Second
+
 type ffmpegProcess struct {
+	generation   uint64
 	config       StreamConfig
-	cmd          *exec.Cmd
+	process      Process
 	cancel       context.CancelFunc
 	startTime    time.Time
 	progress     Progress
 	lastError    string
+	errorCode    ErrorCode // Última categoría de error vista en stderr
 	restartCount int
-	stderr       io.ReadCloser
-	stopped      bool // Marcado como detenido intencionalmente
+	done         chan struct{}  // Se cierra cuando el proceso termina
+	stopped      bool           // Marcado como detenido intencionalmente
+	srtConnected bool           // Receptor SRT conectado (modo listener: uno a la vez)
+	ready        bool           // Ya se emitió EventReady
+	stderrLog    io.WriteCloser // Copia del stderr (nil si no se guarda)
 }
 
 // NewManager crea un nuevo gestor de procesos FFmpeg
@@ -118,10 +152,47 @@ func NewManager(ffmpegPath string, eventHandler func(Event)) *Manager {
 	return &Manager{
 		ffmpegPath:   ffmpegPath,
 		processes:    make(map[string]*ffmpegProcess),
+		supervisors:  make(map[string]*supervisor),
 		eventHandler: eventHandler,
+		stopGrace:    DefaultStopGrace,
+		runner:       ExecRunner{},
 	}
 }
 
+// SetRunner reemplaza el lanzador de procesos (ej: un FFmpeg simulado)
+func (m *Manager) SetRunner(runner Runner) {
+	m.mutex.Lock()
+	m.runner = runner
+	m.mutex.Unlock()
+}
+
+// SetStopGrace establece la espera de detención ordenada (0 = kill inmediato)
+func (m *Manager) SetStopGrace(grace time.Duration) {
+	m.mutex.Lock()
+	m.stopGrace = grace
+	m.mutex.Unlock()
+}
+
+// SetStderrLog establece dónde se guarda el stderr de cada proceso: open se
+// llama al lanzar el proceso y el destino se cierra cuando termina (open puede
+// retornar nil para no guardarlo; open == nil desactiva la copia)
+func (m *Manager) SetStderrLog(open func(channelID string) io.WriteCloser) {
+	m.mutex.Lock()
+	m.openLog = open
+	m.mutex.Unlock()
+}
+
+// SetFFmpegPath cambia el ejecutable de FFmpeg (se usa en los procesos que se
+// inicien después; los activos siguen con el anterior)
+func (m *Manager) SetFFmpegPath(ffmpegPath string) {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	m.mutex.Lock()
+	m.ffmpegPath = ffmpegPath
+	m.mutex.Unlock()
+}
+
 // Start inicia un proceso FFmpeg para streaming SRT
 func (m *Manager) Start(config StreamConfig) error {
 	return m.startInternal(config, false)
@@ -134,25 +205,64 @@ func (m *Manager) StartWithFallback(config StreamConfig) error {
 
 // startInternal implementación interna de Start con opción de fallback
 func (m *Manager) startInternal(config StreamConfig, enableFallback bool) error {
-	m.mutex.Lock()
+	var events []Event
+	err := m.supervise(config.ChannelID, func() error {
+		var err error
+		events, err = m.launch(config, enableFallback)
+		return err
+	})
 
-	// Si ya existe un proceso, eliminarlo rápidamente (no es error, solo reemplazar)
-	if proc, exists := m.processes[config.ChannelID]; exists {
-		if proc.cmd != nil && proc.cmd.Process != nil {
-			log.Printf("[FFmpeg] Reemplazando proceso existente para canal %s", config.ChannelID)
-			proc.stopped = true
-			proc.cmd.Process.Kill()
-			delete(m.processes, config.ChannelID)
-			// Breve pausa para liberar puerto
-			time.Sleep(50 * time.Millisecond)
+	// Los eventos se emiten fuera del supervisor: el handler puede volver a
+	// llamar al manager sin bloquear el canal. Si otra petición ya reemplazó
+	// el proceso, su evento de inicio se descarta.
+	for _, event := range events {
+		if event.Generation != 0 && !m.isCurrent(event.ChannelID, event.Generation) {
+			continue
 		}
+		m.emitEvent(event)
 	}
+	return err
+}
 
+// isCurrent indica si la generación es la del proceso vigente del canal
+func (m *Manager) isCurrent(channelID string, generation uint64) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	proc, exists := m.processes[channelID]
+	return exists && proc.generation == generation
+}
+
+// launch reemplaza el proceso actual del canal (si existe) e inicia uno nuevo.
+// Se ejecuta en el supervisor del canal; retorna los eventos a emitir.
+func (m *Manager) launch(config StreamConfig, enableFallback bool) ([]Event, error) {
+	var events []Event
+
+	// Si ya existe un proceso, detenerlo antes de lanzar el nuevo (no es error, solo reemplazar)
+	m.mutex.Lock()
+	old, exists := m.processes[config.ChannelID]
+	if exists {
+		old.stopped = true
+	}
+	grace := m.stopGrace
 	m.mutex.Unlock()
 
+	if exists {
+		log.Printf("[FFmpeg] Reemplazando proceso existente para canal %s (generación %d)", config.ChannelID, old.generation)
+		old.terminate(context.Background(), grace)
+		if old.cancel != nil {
+			old.cancel()
+		}
+		m.mutex.Lock()
+		if m.processes[config.ChannelID] == old {
+			delete(m.processes, config.ChannelID)
+		}
+		m.mutex.Unlock()
+	}
+
 	// Verificar que el archivo de entrada existe
 	if _, err := os.Stat(config.InputPath); os.IsNotExist(err) {
-		return fmt.Errorf("archivo de entrada no encontrado: %s", config.InputPath)
+		return events, fmt.Errorf("archivo de entrada no encontrado: %s", config.InputPath)
 	}
 
 	// Si se usa encoder de hardware y hay fallback habilitado, verificar primero
@@ -164,7 +274,7 @@ func (m *Manager) startInternal(config StreamConfig, enableFallback bool) error
 		if !m.testHardwareEncoder(config.InputPath, originalEncoder) {
 			// Fallback a libx264
 			config.VideoEncoder = "libx264"
-			m.emitEvent(Event{
+			events = append(events, Event{
 				Type:      EventWarning,
 				ChannelID: config.ChannelID,
 				Message:   fmt.Sprintf("Encoder %s no disponible (driver incompatible). Usando libx264 como fallback.", originalEncoder),
@@ -181,43 +291,38 @@ func (m *Manager) startInternal(config StreamConfig, enableFallback bool) error
 	// Construir argumentos de FFmpeg
 	args := m.buildFFmpegArgs(config)
 
+	m.mutex.RLock()
+	runner, ffmpegPath, openLog := m.runner, m.ffmpegPath, m.openLog
+	m.mutex.RUnlock()
+
 	// Log del comando completo para debug
-	log.Printf("[FFmpeg] Comando: %s %s", m.ffmpegPath, strings.Join(args, " "))
+	log.Printf("[FFmpeg] Comando: %s %s", ffmpegPath, strings.Join(args, " "))
 
 	// Crear contexto con cancelación
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Crear comando
-	cmd := exec.CommandContext(ctx, m.ffmpegPath, args...)
-
-	// Ocultar ventana de consola en Windows
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow:    true,
-		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
-	}
-
-	// Capturar stderr para progreso
-	stderr, err := cmd.StderrPipe()
+	// Lanzar proceso
+	process, err := runner.Start(ctx, ffmpegPath, args)
 	if err != nil {
 		cancel()
-		return fmt.Errorf("error creando pipe stderr: %v", err)
-	}
-
-	// Iniciar proceso
-	if err := cmd.Start(); err != nil {
-		cancel()
-		return fmt.Errorf("error iniciando FFmpeg: %v", err)
+		return events, fmt.Errorf("error iniciando FFmpeg: %v", err)
 	}
 
 	proc := &ffmpegProcess{
 		config:    config,
-		cmd:       cmd,
+		process:   process,
 		cancel:    cancel,
 		startTime: time.Now(),
-		stderr:    stderr,
+		done:      make(chan struct{}),
+	}
+	if openLog != nil {
+		proc.stderrLog = openLog(config.ChannelID)
+		proc.logLine(fmt.Sprintf("=== Inicio PID=%d: %s %s", process.Pid(), ffmpegPath, strings.Join(args, " ")))
 	}
 
 	m.mutex.Lock()
+	m.generation++
+	proc.generation = m.generation
 	m.processes[config.ChannelID] = proc
 	m.mutex.Unlock()
 
@@ -236,18 +341,19 @@ func (m *Manager) startInternal(config StreamConfig, enableFallback bool) error
 	}
 
 	// Log del comando FFmpeg completo para debugging (solo primeros 500 caracteres)
-	cmdString := fmt.Sprintf("%s %v", m.ffmpegPath, strings.Join(args, " "))
+	cmdString := fmt.Sprintf("%s %v", ffmpegPath, strings.Join(args, " "))
 	if len(cmdString) > 500 {
 		cmdString = cmdString[:500] + "..."
 	}
 	log.Printf("[FFmpeg %s] Comando: %s", config.ChannelID, cmdString)
 
-	m.emitEvent(Event{
-		Type:      EventStarted,
-		ChannelID: config.ChannelID,
-		Message:   fmt.Sprintf("FFmpeg iniciado: PID=%d, Puerto=%d, Encoder=%s, Resolución=%dx%d@%dfps", cmd.Process.Pid, srtPort, encoderUsed, config.Width, config.Height, config.FrameRate),
+	events = append(events, Event{
+		Type:       EventStarted,
+		ChannelID:  config.ChannelID,
+		Generation: proc.generation,
+		Message:    fmt.Sprintf("FFmpeg iniciado: PID=%d, Puerto=%d, Encoder=%s, Resolución=%dx%d@%dfps", process.Pid(), srtPort, encoderUsed, config.Width, config.Height, config.FrameRate),
 		Data: map[string]interface{}{
-			"pid":        cmd.Process.Pid,
+			"pid":        process.Pid(),
 			"streamName": config.SRTStreamName,
 			"inputPath":  config.InputPath,
 			"srtPort":    srtPort,
@@ -256,10 +362,11 @@ func (m *Manager) startInternal(config StreamConfig, enableFallback bool) error
 			"resolution": fmt.Sprintf("%dx%d", config.Width, config.Height),
 			"frameRate":  config.FrameRate,
 			"bitrate":    config.VideoBitrate,
+			"generation": proc.generation,
 		},
 	})
 
-	return nil
+	return events, nil
 }
 
 // testHardwareEncoder prueba si un encoder de hardware está disponible y funcional
@@ -278,13 +385,11 @@ func (m *Manager) testHardwareEncoder(inputPath string, encoder string) bool {
 		"-",
 	}
 
-	cmd := exec.CommandContext(ctx, m.ffmpegPath, args...)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow:    true,
-		CreationFlags: 0x08000000,
-	}
+	m.mutex.RLock()
+	runner, ffmpegPath := m.runner, m.ffmpegPath
+	m.mutex.RUnlock()
 
-	output, err := cmd.CombinedOutput()
+	output, err := runner.Output(ctx, ffmpegPath, args)
 	if err != nil {
 		log.Printf("[FFmpeg] Test encoder %s falló: %v - %s", encoder, err, string(output))
 		return false
@@ -294,50 +399,108 @@ func (m *Manager) testHardwareEncoder(inputPath string, encoder string) bool {
 	return true
 }
 
-// Stop detiene un proceso FFmpeg
+// Stop detiene un proceso FFmpeg de forma ordenada
 func (m *Manager) Stop(channelID string) error {
-	m.mutex.Lock()
-	proc, exists := m.processes[channelID]
-	if exists {
-		// Marcar como detenido intencionalmente para que monitorProcess no emita eventos
-		proc.stopped = true
-	}
-	m.mutex.Unlock()
+	return m.stop(context.Background(), channelID)
+}
 
-	if !exists {
-		return nil // No hay proceso, no es error
-	}
+// stop pide a FFmpeg que termine ("q" por stdin y SIGINT donde exista), espera
+// el periodo de gracia para que vacíe el muxer y, si no termina, lo mata.
+// Cancelar ctx adelanta el kill.
+func (m *Manager) stop(ctx context.Context, channelID string) error {
+	var event *Event
+
+	m.supervise(channelID, func() error {
+		m.mutex.Lock()
+		proc, exists := m.processes[channelID]
+		if exists {
+			// Marcar como detenido intencionalmente para que monitorProcess no emita eventos
+			proc.stopped = true
+		}
+		grace := m.stopGrace
+		m.mutex.Unlock()
 
-	// Kill inmediato sin esperar - Windows libera el puerto automáticamente
-	if proc.cmd != nil && proc.cmd.Process != nil {
-		proc.cmd.Process.Kill()
-		// Espera mínima para que Windows procese el kill (solo 50ms)
-		time.Sleep(50 * time.Millisecond)
-	}
+		if !exists {
+			return nil // No hay proceso, no es error
+		}
 
-	// Cancelar contexto después del kill
-	if proc.cancel != nil {
-		proc.cancel()
-	}
+		graceful := proc.terminate(ctx, grace)
 
-	m.mutex.Lock()
-	delete(m.processes, channelID)
-	m.mutex.Unlock()
+		// Cancelar contexto después de que el proceso terminó
+		if proc.cancel != nil {
+			proc.cancel()
+		}
 
-	// Emitir evento de detención (solo si realmente se detuvo)
-	m.emitEvent(Event{
-		Type:      EventStopped,
-		ChannelID: channelID,
-		Message:   "Stream detenido",
+		m.mutex.Lock()
+		if m.processes[channelID] == proc {
+			delete(m.processes, channelID)
+		}
+		m.mutex.Unlock()
+
+		if graceful {
+			log.Printf("[FFmpeg] Proceso %s detenido ordenadamente", channelID)
+		} else {
+			log.Printf("[FFmpeg] Proceso %s forzado a terminar (kill)", channelID)
+		}
+
+		event = &Event{
+			Type:       EventStopped,
+			ChannelID:  channelID,
+			Message:    "Stream detenido",
+			Generation: proc.generation,
+			Data: map[string]interface{}{
+				"requested": true, // Detención solicitada (no terminó por sí mismo)
+				"graceful":  graceful,
+			},
+		}
+		return nil
 	})
 
-	log.Printf("[FFmpeg] Proceso %s detenido rápidamente", channelID)
+	// Emitir evento de detención (solo si realmente se detuvo)
+	if event != nil {
+		m.emitEvent(*event)
+	}
 
 	return nil
 }
 
-// StopAll detiene todos los procesos FFmpeg
-func (m *Manager) StopAll() {
+// terminate detiene el proceso; retorna true si terminó sin necesidad de kill
+func (p *ffmpegProcess) terminate(ctx context.Context, grace time.Duration) bool {
+	if p.process == nil {
+		return true
+	}
+
+	if grace > 0 {
+		// "q" es el comando interactivo de FFmpeg para terminar cerrando la salida
+		if stdin := p.process.Stdin(); stdin != nil {
+			stdin.Write([]byte("q\n"))
+			stdin.Close()
+		}
+		p.process.Interrupt()
+
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+
+		select {
+		case <-p.done:
+			return true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	p.process.Kill()
+	select {
+	case <-p.done:
+	case <-time.After(killWait):
+		log.Printf("[FFmpeg] El proceso %d no terminó tras kill", p.process.Pid())
+	}
+	return false
+}
+
+// StopAll detiene todos los procesos FFmpeg en paralelo. Al cancelarse ctx
+// (ej: deadline de cierre) los procesos pendientes se matan sin esperar la gracia.
+func (m *Manager) StopAll(ctx context.Context) error {
 	m.mutex.RLock()
 	channelIDs := make([]string, 0, len(m.processes))
 	for id := range m.processes {
@@ -345,9 +508,17 @@ func (m *Manager) StopAll() {
 	}
 	m.mutex.RUnlock()
 
+	var wg sync.WaitGroup
 	for _, id := range channelIDs {
-		m.Stop(id)
+		wg.Add(1)
+		go func(channelID string) {
+			defer wg.Done()
+			m.stop(ctx, channelID)
+		}(id)
 	}
+	wg.Wait()
+
+	return ctx.Err()
 }
 
 // IsRunning verifica si un proceso está corriendo
@@ -360,12 +531,30 @@ func (m *Manager) IsRunning(channelID string) bool {
 		return false
 	}
 
-	if proc.cmd == nil || proc.cmd.Process == nil {
+	return proc.isRunning()
+}
+
+// isRunning verifica si el proceso sigue corriendo (sin tomar el mutex del manager)
+func (p *ffmpegProcess) isRunning() bool {
+	if p.process == nil {
 		return false
 	}
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
 
-	// Verificar si el proceso sigue corriendo
-	return proc.cmd.ProcessState == nil || !proc.cmd.ProcessState.Exited()
+// SetRestartCount registra el número de reinicios automáticos de un canal
+func (m *Manager) SetRestartCount(channelID string, count int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if proc, exists := m.processes[channelID]; exists {
+		proc.restartCount = count
+	}
 }
 
 // GetProcessInfo obtiene información de un proceso
@@ -379,8 +568,8 @@ func (m *Manager) GetProcessInfo(channelID string) (*ProcessInfo, error) {
 	}
 
 	pid := 0
-	if proc.cmd != nil && proc.cmd.Process != nil {
-		pid = proc.cmd.Process.Pid
+	if proc.process != nil {
+		pid = proc.process.Pid()
 	}
 
 	return &ProcessInfo{
@@ -388,10 +577,13 @@ func (m *Manager) GetProcessInfo(channelID string) (*ProcessInfo, error) {
 		PID:          pid,
 		StartTime:    proc.startTime,
 		Config:       proc.config,
-		IsRunning:    m.IsRunning(channelID),
+		IsRunning:    proc.isRunning(),
 		Progress:     proc.progress,
 		LastError:    proc.lastError,
+		ErrorCode:    proc.errorCode,
 		RestartCount: proc.restartCount,
+		SRTConnected: proc.srtConnected,
+		Generation:   proc.generation,
 	}, nil
 }
 
@@ -403,8 +595,8 @@ func (m *Manager) GetAllProcessInfo() []ProcessInfo {
 	infos := make([]ProcessInfo, 0, len(m.processes))
 	for channelID, proc := range m.processes {
 		pid := 0
-		if proc.cmd != nil && proc.cmd.Process != nil {
-			pid = proc.cmd.Process.Pid
+		if proc.process != nil {
+			pid = proc.process.Pid()
 		}
 
 		infos = append(infos, ProcessInfo{
@@ -412,10 +604,13 @@ func (m *Manager) GetAllProcessInfo() []ProcessInfo {
 			PID:          pid,
 			StartTime:    proc.startTime,
 			Config:       proc.config,
-			IsRunning:    proc.cmd != nil && proc.cmd.ProcessState == nil,
+			IsRunning:    proc.isRunning(),
 			Progress:     proc.progress,
 			LastError:    proc.lastError,
+			ErrorCode:    proc.errorCode,
 			RestartCount: proc.restartCount,
+			SRTConnected: proc.srtConnected,
+			Generation:   proc.generation,
 		})
 	}
 
@@ -519,6 +714,16 @@ func (m *Manager) buildFFmpegArgs(config StreamConfig) []string {
 		if profile == "" {
 			profile = "main"
 		}
+		// Los perfiles 8-bit 4:2:0 no admiten 4:2:2 / 4:4:4, ni baseline entrelazado
+		switch config.PixelFormat {
+		case "yuv422p":
+			profile = "high422"
+		case "yuv444p":
+			profile = "high444"
+		}
+		if profile == "baseline" && isInterlaced(config.Interlace) {
+			profile = "main"
+		}
 		args = append(args,
 			"-profile:v", profile,
 			"-level", "4.0",
@@ -573,9 +778,16 @@ func (m *Manager) buildFFmpegArgs(config StreamConfig) []string {
 		args = append(args, "-maxrate", maxBitrate, "-bufsize", bufSize)
 	}
 
-	// === Resolución ===
-	if config.Width > 0 && config.Height > 0 {
-		args = append(args, "-s", fmt.Sprintf("%dx%d", config.Width, config.Height))
+	// === Resolución y barrido ===
+	if filters := videoFilters(config); len(filters) > 0 {
+		args = append(args, "-vf", strings.Join(filters, ","))
+	}
+	if isInterlaced(config.Interlace) {
+		top := "1"
+		if config.Interlace == "bff" {
+			top = "0"
+		}
+		args = append(args, "-flags", "+ildct+ilme", "-top", top)
 	}
 
 	// === Frame Rate ===
@@ -583,14 +795,22 @@ func (m *Manager) buildFFmpegArgs(config StreamConfig) []string {
 		args = append(args, "-r", strconv.Itoa(config.FrameRate))
 	}
 
-	// Formato de pixel (necesario para compatibilidad con NVENC)
-	args = append(args, "-pix_fmt", "yuv420p")
+	// Formato de pixel (yuv420p por compatibilidad con NVENC y receptores)
+	pixelFormat := config.PixelFormat
+	if pixelFormat == "" {
+		pixelFormat = "yuv420p"
+	}
+	args = append(args, "-pix_fmt", pixelFormat)
 
 	// === Audio ===
+	audioChannels := config.AudioChannels
+	if audioChannels <= 0 {
+		audioChannels = 2
+	}
 	args = append(args,
 		"-c:a", "aac",
 		"-ar", "48000",
-		"-ac", "2",
+		"-ac", strconv.Itoa(audioChannels),
 		"-af", "aresample=async=1:min_hard_comp=0.100000:first_pts=0",
 	)
 
@@ -657,41 +877,111 @@ func (m *Manager) buildFFmpegArgs(config StreamConfig) []string {
 	return args
 }
 
+// videoFilters construye la cadena -vf de escalado y barrido
+func videoFilters(config StreamConfig) []string {
+	var filters []string
+
+	if w, h := config.Width, config.Height; w > 0 && h > 0 {
+		switch config.ScaleMode {
+		case "letterbox":
+			// Encajar conservando aspecto y rellenar con negro
+			filters = append(filters,
+				fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", w, h),
+				fmt.Sprintf("pad=%d:%d:(ow-iw)/2:(oh-ih)/2", w, h),
+				"setsar=1",
+			)
+		case "crop":
+			// Llenar conservando aspecto y recortar el sobrante
+			filters = append(filters,
+				fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase", w, h),
+				fmt.Sprintf("crop=%d:%d", w, h),
+				"setsar=1",
+			)
+		default:
+			filters = append(filters, fmt.Sprintf("scale=%d:%d", w, h))
+		}
+	}
+
+	if isInterlaced(config.Interlace) {
+		filters = append(filters, "setfield="+config.Interlace)
+	}
+
+	return filters
+}
+
+// isInterlaced indica si el modo de barrido es entrelazado
+func isInterlaced(mode string) bool {
+	return mode == "tff" || mode == "bff"
+}
+
 // monitorProcess monitorea un proceso FFmpeg
 func (m *Manager) monitorProcess(channelID string, proc *ffmpegProcess) {
-	// Leer stderr para progreso
-	go m.parseProgress(channelID, proc)
+	// Leer stderr hasta EOF antes de Wait (Wait cierra el pipe y se perderían
+	// las últimas líneas, que son las que explican el fallo)
+	m.parseProgress(channelID, proc)
 
 	// Esperar a que el proceso termine
-	err := proc.cmd.Wait()
+	err := proc.process.Wait()
+	close(proc.done)
+
+	if proc.stderrLog != nil {
+		if err != nil {
+			proc.logLine(fmt.Sprintf("=== Fin: %v", err))
+		} else {
+			proc.logLine("=== Fin: terminado normalmente")
+		}
+		proc.stderrLog.Close()
+	}
 
+	// Solo emitir eventos si el proceso NO fue detenido intencionalmente y sigue
+	// siendo el proceso vigente del canal (un proceso reemplazado no borra al nuevo)
 	m.mutex.Lock()
-	// Solo emitir eventos si el proceso NO fue detenido intencionalmente
-	if !proc.stopped {
-		if _, exists := m.processes[channelID]; exists {
-			if err != nil {
-				proc.lastError = err.Error()
-				m.emitEvent(Event{
-					Type:      EventError,
-					ChannelID: channelID,
-					Message:   err.Error(),
-				})
-			} else {
-				m.emitEvent(Event{
-					Type:      EventStopped,
-					ChannelID: channelID,
-					Message:   "Proceso terminado normalmente",
-				})
+	current := !proc.stopped && m.processes[channelID] == proc
+	var code ErrorCode
+	message := ""
+	if current {
+		if err != nil {
+			code = ClassifyExit(err, proc.errorCode)
+			message = err.Error()
+			if proc.errorCode != "" && proc.lastError != "" {
+				message = fmt.Sprintf("%s (%v)", proc.lastError, err)
 			}
-			delete(m.processes, channelID)
+			proc.lastError = message
+			proc.errorCode = code
 		}
+		delete(m.processes, channelID)
 	}
 	m.mutex.Unlock()
+
+	if !current {
+		return
+	}
+
+	if err != nil {
+		m.emitEvent(Event{
+			Type:       EventError,
+			ChannelID:  channelID,
+			Message:    message,
+			Generation: proc.generation,
+			Data: map[string]interface{}{
+				"code":      string(code),
+				"retryable": code.Retryable(),
+			},
+		})
+	} else {
+		m.emitEvent(Event{
+			Type:       EventStopped,
+			ChannelID:  channelID,
+			Message:    "Proceso terminado normalmente",
+			Generation: proc.generation,
+		})
+	}
 }
 
 // parseProgress lee la salida de FFmpeg para logging y detección de errores
 func (m *Manager) parseProgress(channelID string, proc *ffmpegProcess) {
-	scanner := bufio.NewScanner(proc.stderr)
+	scanner := bufio.NewScanner(proc.process.Stderr())
+	scanner.Split(scanLines)
 	lastProgressLog := time.Now()
 	progressLogInterval := 30 * time.Second // Log de progreso cada 30 segundos
 	streamingStarted := false
@@ -699,24 +989,48 @@ func (m *Manager) parseProgress(channelID string, proc *ffmpegProcess) {
 	for scanner.Scan() {
 		line := scanner.Text()
 		lineLower := strings.ToLower(line)
+		isStats := false
 
 		// Detectar cuando un cliente SRT se conecta
 		if strings.Contains(lineLower, "srt: accepted connection") || strings.Contains(lineLower, "srt: listener accepted") {
 			log.Printf("[FFmpeg %s] ✓ Cliente SRT conectado", channelID)
-			m.emitEvent(Event{
+			m.mutex.Lock()
+			proc.srtConnected = true
+			m.mutex.Unlock()
+			m.emitFor(proc, Event{
 				Type:      EventProgress,
 				ChannelID: channelID,
 				Message:   "Cliente SRT conectado - streaming activo",
+				Data: map[string]interface{}{
+					"srtEvent": "connected",
+				},
 			})
+			m.markReady(channelID, proc, "srt_connected")
 			streamingStarted = true
 		}
 
+		// Entrada abierta: FFmpeg pasa a abrir el listener SRT y espera receptor
+		if strings.HasPrefix(line, "Input #") {
+			time.AfterFunc(listenerReadyDelay, func() {
+				m.markReady(channelID, proc, "listening")
+			})
+		}
+
 		// Detectar progreso de frames (indica que está strimeando)
-		if strings.Contains(line, "frame=") && strings.Contains(line, "fps=") {
+		m.mutex.RLock()
+		progress := proc.progress
+		m.mutex.RUnlock()
+		if parseStatsLine(line, &progress) {
+			isStats = true
+			m.mutex.Lock()
+			proc.progress = progress
+			m.mutex.Unlock()
+
 			if !streamingStarted {
 				log.Printf("[FFmpeg %s] ✓ Streaming iniciado - generando frames", channelID)
 				streamingStarted = true
 			}
+			m.markReady(channelID, proc, "frames")
 
 			// Log periódico (cada 30s) para confirmar que sigue strimeando
 			if time.Since(lastProgressLog) >= progressLogInterval {
@@ -726,10 +1040,11 @@ func (m *Manager) parseProgress(channelID string, proc *ffmpegProcess) {
 					progressInfo = progressInfo[:150] + "..."
 				}
 				log.Printf("[FFmpeg %s] → Streaming: %s", channelID, progressInfo)
+				proc.logLine(line)
 				lastProgressLog = time.Now()
 
 				// Emitir evento de progreso (sin llenar memoria)
-				m.emitEvent(Event{
+				m.emitFor(proc, Event{
 					Type:      EventProgress,
 					ChannelID: channelID,
 					Message:   "Streaming activo",
@@ -740,20 +1055,71 @@ func (m *Manager) parseProgress(channelID string, proc *ffmpegProcess) {
 			}
 		}
 
-		// Log completo solo para errores y warnings importantes
-		if strings.Contains(lineLower, "error") && !strings.Contains(lineLower, "no error") {
+		// El archivo del canal guarda todo el stderr salvo las líneas de
+		// estadísticas, que se guardan con el log periódico
+		if !isStats {
+			proc.logLine(line)
+		}
+
+		// Log completo solo para errores y warnings importantes. Los errores se
+		// clasifican aquí pero se reportan al terminar el proceso: FFmpeg sigue
+		// corriendo tras muchos errores de stderr (ej: frames corruptos)
+		if code := ClassifyLine(line); code != "" {
+			log.Printf("[FFmpeg %s] ✗ ERROR [%s]: %s", channelID, code, line)
+			m.mutex.Lock()
+			proc.errorCode = code
+			proc.lastError = line
+			m.mutex.Unlock()
+		} else if strings.Contains(lineLower, "error") && !strings.Contains(lineLower, "no error") {
 			log.Printf("[FFmpeg %s] ✗ ERROR: %s", channelID, line)
-			m.emitEvent(Event{
-				Type:      EventError,
-				ChannelID: channelID,
-				Message:   line,
-			})
 		} else if strings.Contains(lineLower, "warning") {
 			log.Printf("[FFmpeg %s] ⚠ WARNING: %s", channelID, line)
 		}
 	}
 }
 
+// logLine escribe una línea con fecha en la copia del stderr (si se guarda)
+func (p *ffmpegProcess) logLine(line string) {
+	if p.stderrLog == nil {
+		return
+	}
+	fmt.Fprintf(p.stderrLog, "%s %s\n", time.Now().Format("2006-01-02 15:04:05.000"), line)
+}
+
+// markReady emite EventReady una sola vez si el proceso sigue vigente
+func (m *Manager) markReady(channelID string, proc *ffmpegProcess, signal string) {
+	m.mutex.Lock()
+	if proc.ready || proc.stopped || m.processes[channelID] != proc {
+		m.mutex.Unlock()
+		return
+	}
+	proc.ready = true
+	m.mutex.Unlock()
+
+	m.emitEvent(Event{
+		Type:       EventReady,
+		ChannelID:  channelID,
+		Message:    "Stream listo",
+		Generation: proc.generation,
+		Data: map[string]interface{}{
+			"signal": signal, // frames, srt_connected o listening
+		},
+	})
+}
+
+// emitFor emite un evento de un proceso solo si sigue siendo el vigente del canal
+func (m *Manager) emitFor(proc *ffmpegProcess, event Event) {
+	m.mutex.RLock()
+	current := !proc.stopped && m.processes[event.ChannelID] == proc
+	m.mutex.RUnlock()
+
+	if !current {
+		return
+	}
+	event.Generation = proc.generation
+	m.emitEvent(event)
+}
+
 // emitEvent emite un evento
 func (m *Manager) emitEvent(event Event) {
 	if m.eventHandler != nil {
@@ -0,0 +1,520 @@
+package ffmpeg_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"servidor-stream/internal/ffmpeg"
+	"servidor-stream/internal/ffmpeg/ffmpegtest"
+)
+
+// Líneas típicas de stderr de FFmpeg
+const (
+	inputLine = "Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'in.mp4':"
+	statsLine = "frame=  250 fps= 25 q=-1.0 size=    1024kB time=00:00:10.00 bitrate= 838.9kbits/s dup=0 drop=0 speed=1x"
+)
+
+// recorder registra los eventos emitidos por el manager
+type recorder struct {
+	mutex  sync.Mutex
+	events []ffmpeg.Event
+}
+
+func (r *recorder) handle(event ffmpeg.Event) {
+	r.mutex.Lock()
+	r.events = append(r.events, event)
+	r.mutex.Unlock()
+}
+
+func (r *recorder) all() []ffmpeg.Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]ffmpeg.Event(nil), r.events...)
+}
+
+// wait espera el primer evento del tipo indicado
+func (r *recorder) wait(t *testing.T, eventType ffmpeg.EventType) ffmpeg.Event {
+	t.Helper()
+	var event ffmpeg.Event
+	eventually(t, "evento "+string(eventType), func() bool {
+		for _, e := range r.all() {
+			if e.Type == eventType {
+				event = e
+				return true
+			}
+		}
+		return false
+	})
+	return event
+}
+
+// count cuenta los eventos del tipo indicado
+func (r *recorder) count(eventType ffmpeg.EventType) int {
+	n := 0
+	for _, e := range r.all() {
+		if e.Type == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+// eventually reintenta cond hasta que se cumple o vence el plazo
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("no se cumplió a tiempo: %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// newManager crea un manager contra el runner simulado; al terminar la prueba
+// detiene todo y comprueba que no quedan procesos vivos
+func newManager(t *testing.T, runner *ffmpegtest.Runner) (*ffmpeg.Manager, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	m := ffmpeg.NewManager("ffmpeg", rec.handle)
+	m.SetRunner(runner)
+	m.SetStopGrace(200 * time.Millisecond)
+	t.Cleanup(func() {
+		m.StopAll(context.Background())
+		eventually(t, "procesos terminados", func() bool { return runner.Running() == 0 })
+	})
+	return m, rec
+}
+
+// streamConfig configuración mínima con un archivo de entrada existente
+func streamConfig(t *testing.T, channelID string) ffmpeg.StreamConfig {
+	t.Helper()
+	input := filepath.Join(t.TempDir(), "in.mp4")
+	if err := os.WriteFile(input, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return ffmpeg.StreamConfig{ChannelID: channelID, InputPath: input, SRTPort: 9001}
+}
+
+// flagValue retorna el valor que sigue a la primera aparición de flag
+func flagValue(args []string, flag string) (string, bool) {
+	i := slices.Index(args, flag)
+	if i < 0 || i+1 >= len(args) {
+		return "", false
+	}
+	return args[i+1], true
+}
+
+func TestBuildFFmpegArgs(t *testing.T) {
+	m := ffmpeg.NewManager("", nil)
+
+	tests := []struct {
+		name   string
+		config ffmpeg.StreamConfig
+		want   map[string]string // flag → valor
+		absent []string
+	}{
+		{
+			name:   "valores por defecto (libx264)",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4"},
+			want: map[string]string{
+				"-i": "in.mp4", "-c:v": "libx264", "-profile:v": "main", "-preset": "veryfast",
+				"-tune": "zerolatency", "-g": "15", "-keyint_min": "15", "-bf": "0",
+				"-b:v": "5M", "-maxrate": "5M", "-bufsize": "5M", "-pix_fmt": "yuv420p",
+				"-c:a": "aac", "-ac": "2", "-b:a": "192k", "-f": "mpegts",
+			},
+			absent: []string{"-stream_loop", "-vf", "-r", "-flags"},
+		},
+		{
+			name:   "bucle y frame rate",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", Loop: true, FrameRate: 50},
+			want:   map[string]string{"-stream_loop": "-1", "-r": "50"},
+		},
+		{
+			name: "libx264 personalizado",
+			config: ffmpeg.StreamConfig{
+				InputPath: "in.mp4", EncoderPreset: "fast", EncoderProfile: "high", EncoderTune: "film",
+				GopSize: 50, BFrames: 2, VideoBitrate: "8M", MaxBitrate: "10M", BufferSize: "4M",
+				AudioBitrate: "128k", AudioChannels: 8,
+			},
+			want: map[string]string{
+				"-preset": "fast", "-profile:v": "high", "-tune": "film", "-g": "50", "-bf": "2",
+				"-b:v": "8M", "-maxrate": "10M", "-bufsize": "4M", "-b:a": "128k", "-ac": "8",
+			},
+		},
+		{
+			name:   "NVENC",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", VideoEncoder: "h264_nvenc"},
+			want:   map[string]string{"-c:v": "h264_nvenc", "-g": "60", "-bf": "0"},
+			absent: []string{"-maxrate", "-bufsize", "-preset", "-tune"},
+		},
+		{
+			name:   "QuickSync",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", VideoEncoder: "h264_qsv", EncoderProfile: "main"},
+			want:   map[string]string{"-c:v": "h264_qsv", "-preset": "veryfast", "-look_ahead": "0", "-profile:v": "main"},
+			absent: []string{"-tune"},
+		},
+		{
+			name:   "AMF",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", VideoEncoder: "h264_amf"},
+			want:   map[string]string{"-c:v": "h264_amf", "-quality": "speed", "-rc": "cbr", "-maxrate": "5M"},
+			absent: []string{"-profile:v"},
+		},
+		{
+			name:   "4:2:2 fuerza high422",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", PixelFormat: "yuv422p"},
+			want:   map[string]string{"-profile:v": "high422", "-pix_fmt": "yuv422p"},
+		},
+		{
+			name:   "entrelazado tff (baseline pasa a main)",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", EncoderProfile: "baseline", Interlace: "tff", Width: 1920, Height: 1080},
+			want: map[string]string{
+				"-profile:v": "main", "-flags": "+ildct+ilme", "-top": "1",
+				"-vf": "scale=1920:1080,setfield=tff",
+			},
+		},
+		{
+			name:   "entrelazado bff",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", Interlace: "bff"},
+			want:   map[string]string{"-top": "0", "-vf": "setfield=bff"},
+		},
+		{
+			name:   "letterbox",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", Width: 1280, Height: 720, ScaleMode: "letterbox"},
+			want: map[string]string{
+				"-vf": "scale=1280:720:force_original_aspect_ratio=decrease,pad=1280:720:(ow-iw)/2:(oh-ih)/2,setsar=1",
+			},
+		},
+		{
+			name:   "crop",
+			config: ffmpeg.StreamConfig{InputPath: "in.mp4", Width: 1280, Height: 720, ScaleMode: "crop"},
+			want: map[string]string{
+				"-vf": "scale=1280:720:force_original_aspect_ratio=increase,crop=1280:720,setsar=1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := m.BuildFFmpegArgs(tt.config)
+			for flag, want := range tt.want {
+				if got, ok := flagValue(args, flag); !ok || got != want {
+					t.Errorf("%s = %q, se esperaba %q", flag, got, want)
+				}
+			}
+			for _, flag := range tt.absent {
+				if slices.Contains(args, flag) {
+					t.Errorf("%s no debería estar: %v", flag, args)
+				}
+			}
+			// El bucle es una opción de entrada: debe ir antes de -i
+			if loop := slices.Index(args, "-stream_loop"); loop >= 0 && loop > slices.Index(args, "-i") {
+				t.Errorf("-stream_loop después de -i: %v", args)
+			}
+		})
+	}
+}
+
+func TestBuildFFmpegArgsSRTOutput(t *testing.T) {
+	m := ffmpeg.NewManager("", nil)
+
+	tests := []struct {
+		name   string
+		config ffmpeg.StreamConfig
+		want   string
+	}{
+		{
+			"por defecto",
+			ffmpeg.StreamConfig{InputPath: "in.mp4"},
+			"srt://0.0.0.0:9000?mode=listener&latency=200000&pkt_size=1316&rcvbuf=2097152&sndbuf=2097152&maxbw=-1&oheadbw=25&listen_timeout=-1&tlpktdrop=1&nakreport=1",
+		},
+		{
+			"personalizado",
+			ffmpeg.StreamConfig{InputPath: "in.mp4", SRTHost: "192.168.1.10", SRTPort: 9005, SRTLatency: 120, SRTRecvBuffer: 1000, SRTSendBuffer: 2000, SRTOverheadBW: 50},
+			"srt://192.168.1.10:9005?mode=listener&latency=120000&pkt_size=1316&rcvbuf=1000&sndbuf=2000&maxbw=-1&oheadbw=50&listen_timeout=-1&tlpktdrop=1&nakreport=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := m.BuildFFmpegArgs(tt.config)
+			if got := args[len(args)-1]; got != tt.want {
+				t.Errorf("URL SRT = %q\nse esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHardwareEncoderFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		outputErr error
+		fallback  bool
+		want      string
+		probes    int
+	}{
+		{"encoder disponible", nil, true, "h264_nvenc", 1},
+		{"encoder no disponible", &ffmpegtest.ExitError{Code: 1}, true, "libx264", 1},
+		{"sin fallback no se prueba", &ffmpegtest.ExitError{Code: 1}, false, "h264_nvenc", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := ffmpegtest.NewRunner(ffmpegtest.Script{Hold: true})
+			runner.OutputErr = tt.outputErr
+			m, rec := newManager(t, runner)
+
+			config := streamConfig(t, "a")
+			config.VideoEncoder = "h264_nvenc"
+			start := m.Start
+			if tt.fallback {
+				start = m.StartWithFallback
+			}
+			if err := start(config); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, _ := flagValue(runner.Calls()[0], "-c:v"); got != tt.want {
+				t.Errorf("-c:v = %q, se esperaba %q", got, tt.want)
+			}
+			probes := runner.Probes()
+			if len(probes) != tt.probes {
+				t.Fatalf("pruebas de encoder = %d, se esperaban %d", len(probes), tt.probes)
+			}
+			if tt.probes > 0 {
+				if got, _ := flagValue(probes[0], "-c:v"); got != "h264_nvenc" {
+					t.Errorf("la prueba usó -c:v %q", got)
+				}
+			}
+			if warned := rec.count(ffmpeg.EventWarning) > 0; warned != (tt.want == "libx264") {
+				t.Errorf("aviso de fallback emitido = %v", warned)
+			}
+			info, err := m.GetProcessInfo("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Config.VideoEncoder != tt.want {
+				t.Errorf("encoder registrado = %q, se esperaba %q", info.Config.VideoEncoder, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartEmitsStartedAndReady(t *testing.T) {
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Lines: []string{inputLine, statsLine}, Hold: true})
+	m, rec := newManager(t, runner)
+
+	if err := m.Start(streamConfig(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsRunning("a") {
+		t.Fatal("el proceso no está corriendo")
+	}
+
+	started := rec.wait(t, ffmpeg.EventStarted)
+	ready := rec.wait(t, ffmpeg.EventReady)
+	if started.Generation == 0 || ready.Generation != started.Generation {
+		t.Errorf("generaciones: started=%d ready=%d", started.Generation, ready.Generation)
+	}
+	if signal := ready.Data["signal"]; signal != "frames" {
+		t.Errorf("señal de listo = %v, se esperaba frames", signal)
+	}
+	eventually(t, "progreso", func() bool {
+		info, err := m.GetProcessInfo("a")
+		return err == nil && info.Progress.Frame == 250
+	})
+}
+
+func TestStartErrors(t *testing.T) {
+	t.Run("entrada inexistente", func(t *testing.T) {
+		runner := ffmpegtest.NewRunner(ffmpegtest.Script{Hold: true})
+		m, _ := newManager(t, runner)
+
+		config := streamConfig(t, "a")
+		config.InputPath = filepath.Join(t.TempDir(), "no-existe.mp4")
+		if err := m.Start(config); err == nil {
+			t.Fatal("se esperaba error")
+		}
+		if len(runner.Calls()) != 0 {
+			t.Error("se lanzó FFmpeg sin archivo de entrada")
+		}
+	})
+
+	t.Run("ejecutable no encontrado", func(t *testing.T) {
+		runner := ffmpegtest.NewRunner(ffmpegtest.Script{StartErr: errors.New("executable file not found")})
+		m, rec := newManager(t, runner)
+
+		if err := m.Start(streamConfig(t, "a")); err == nil {
+			t.Fatal("se esperaba error")
+		}
+		if m.IsRunning("a") || rec.count(ffmpeg.EventStarted) != 0 {
+			t.Error("no debería quedar un proceso registrado")
+		}
+	})
+}
+
+func TestStopGraceful(t *testing.T) {
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Hold: true})
+	m, rec := newManager(t, runner)
+
+	if err := m.Start(streamConfig(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	m.Stop("a")
+
+	if m.IsRunning("a") || runner.Running() != 0 {
+		t.Fatal("el proceso sigue corriendo")
+	}
+	stopped := rec.wait(t, ffmpeg.EventStopped)
+	if stopped.Data["requested"] != true || stopped.Data["graceful"] != true {
+		t.Errorf("detención = %v, se esperaba solicitada y ordenada", stopped.Data)
+	}
+	if n := rec.count(ffmpeg.EventStopped) + rec.count(ffmpeg.EventError); n != 1 {
+		t.Errorf("eventos de fin = %d, se esperaba 1", n)
+	}
+}
+
+// Un FFmpeg que ignora "q" y la interrupción se mata al vencer la gracia
+func TestStopIgnoreQuitEscalatesToKill(t *testing.T) {
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Hold: true, IgnoreQuit: true})
+	m, rec := newManager(t, runner)
+	m.SetStopGrace(50 * time.Millisecond)
+
+	if err := m.Start(streamConfig(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	begin := time.Now()
+	m.Stop("a")
+
+	if elapsed := time.Since(begin); elapsed < 50*time.Millisecond {
+		t.Errorf("se mató sin esperar la gracia (%v)", elapsed)
+	}
+	if runner.Running() != 0 {
+		t.Fatal("el proceso sigue vivo tras el kill")
+	}
+	stopped := rec.wait(t, ffmpeg.EventStopped)
+	if stopped.Data["graceful"] != false {
+		t.Errorf("graceful = %v, se esperaba false", stopped.Data["graceful"])
+	}
+}
+
+// Cancelar el contexto de StopAll adelanta el kill
+func TestStopAllCancelledKillsImmediately(t *testing.T) {
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Hold: true, IgnoreQuit: true})
+	m, _ := newManager(t, runner)
+	m.SetStopGrace(time.Hour)
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := m.Start(streamConfig(t, id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := m.StopAll(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StopAll = %v, se esperaba DeadlineExceeded", err)
+	}
+	if runner.Running() != 0 || len(m.GetAllProcessInfo()) != 0 {
+		t.Error("quedaron procesos tras StopAll")
+	}
+}
+
+func TestReplaceRunningProcess(t *testing.T) {
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Hold: true})
+	m, rec := newManager(t, runner)
+
+	first := streamConfig(t, "a")
+	if err := m.Start(first); err != nil {
+		t.Fatal(err)
+	}
+	firstInfo, _ := m.GetProcessInfo("a")
+
+	second := streamConfig(t, "a")
+	if err := m.Start(second); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := m.GetProcessInfo("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Generation <= firstInfo.Generation || info.Config.InputPath != second.InputPath {
+		t.Errorf("proceso vigente = generación %d (%s)", info.Generation, info.Config.InputPath)
+	}
+	if runner.Running() != 1 {
+		t.Errorf("procesos vivos = %d, el anterior debía terminar", runner.Running())
+	}
+	// El reemplazo no es una detención ni un error del canal
+	if n := rec.count(ffmpeg.EventStopped) + rec.count(ffmpeg.EventError); n != 0 {
+		t.Errorf("el reemplazo emitió %d eventos de fin", n)
+	}
+	if n := rec.count(ffmpeg.EventStarted); n != 2 {
+		t.Errorf("eventos started = %d, se esperaban 2", n)
+	}
+}
+
+// Un proceso que termina por sí mismo se reporta con su categoría y el canal
+// puede volver a iniciarse (reinicio automático)
+func TestExitAndRestart(t *testing.T) {
+	crash := true
+	var mutex sync.Mutex
+	runner := ffmpegtest.NewRunnerFunc(func([]string) ffmpegtest.Script {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if crash {
+			crash = false
+			return ffmpegtest.Script{
+				Lines:    []string{inputLine, statsLine, "[srt @ 0x55] Connection was broken"},
+				ExitCode: 1,
+			}
+		}
+		return ffmpegtest.Script{Lines: []string{statsLine}, Hold: true}
+	})
+	m, rec := newManager(t, runner)
+
+	config := streamConfig(t, "a")
+	if err := m.Start(config); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := rec.wait(t, ffmpeg.EventError)
+	if failure.Data["code"] != string(ffmpeg.ErrorSRTDisconnected) || failure.Data["retryable"] != true {
+		t.Errorf("error = %v", failure.Data)
+	}
+	if !strings.Contains(failure.Message, "Connection was broken") {
+		t.Errorf("mensaje = %q, debe incluir la línea de stderr", failure.Message)
+	}
+	eventually(t, "proceso retirado", func() bool { return !m.IsRunning("a") })
+
+	if err := m.Start(config); err != nil {
+		t.Fatal(err)
+	}
+	info, err := m.GetProcessInfo("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Generation <= failure.Generation || info.ErrorCode != "" {
+		t.Errorf("tras reiniciar: generación %d, código %q", info.Generation, info.ErrorCode)
+	}
+}
+
+func TestExitCleanEmitsStopped(t *testing.T) {
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Lines: []string{statsLine}})
+	m, rec := newManager(t, runner)
+
+	if err := m.Start(streamConfig(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	stopped := rec.wait(t, ffmpeg.EventStopped)
+	if stopped.Data["requested"] != nil {
+		t.Errorf("fin natural marcado como solicitado: %v", stopped.Data)
+	}
+	if rec.count(ffmpeg.EventError) != 0 {
+		t.Error("un fin limpio no es un error")
+	}
+}
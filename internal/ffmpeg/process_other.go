@@ -0,0 +1,16 @@
+//go:build !windows
+
+package ffmpeg
+
+import (
+	"os"
+	"os/exec"
+)
+
+// configureCmd no requiere ajustes fuera de Windows
+func configureCmd(cmd *exec.Cmd) {}
+
+// interruptProcess envía SIGINT: FFmpeg cierra el muxer y termina ordenadamente
+func interruptProcess(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package ffmpeg
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// configureCmd oculta la ventana de consola del proceso
+func configureCmd(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
+	}
+}
+
+// interruptProcess no está disponible en Windows sin consola (CTRL_C_EVENT
+// requiere compartirla): la detención ordenada depende de "q" por stdin
+func interruptProcess(p *os.Process) error {
+	return errors.New("interrupción no soportada en Windows")
+}
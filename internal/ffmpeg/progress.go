@@ -0,0 +1,108 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// statsFieldRegex pares "clave=valor" de la línea de estadísticas (admite espacios tras '=')
+var statsFieldRegex = regexp.MustCompile(`(\w+)=\s*(\S+)`)
+
+// scanLines divide la salida de FFmpeg en líneas terminadas en '\n' o '\r'
+// (las líneas de -stats se reescriben con '\r' y nunca llevan '\n')
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, bytes.TrimSpace(data[:i]), nil
+	}
+	if atEOF {
+		return len(data), bytes.TrimSpace(data), nil
+	}
+	return 0, nil, nil
+}
+
+// parseStatsLine extrae el progreso de una línea de -stats de FFmpeg:
+//
+//	frame= 1234 fps= 30 q=-1.0 size=   1234kB time=00:00:41.13 bitrate=4972.1kbits/s dup=0 drop=3 speed=1.00x
+//
+// Retorna false si la línea no es de progreso
+func parseStatsLine(line string, progress *Progress) bool {
+	if !strings.Contains(line, "frame=") || !strings.Contains(line, "fps=") {
+		return false
+	}
+
+	for _, match := range statsFieldRegex.FindAllStringSubmatch(line, -1) {
+		key, value := match[1], match[2]
+		switch key {
+		case "frame":
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+				progress.Frame = v
+			}
+		case "fps":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				progress.FPS = v
+			}
+		case "size", "Lsize":
+			progress.TotalSize = parseSize(value)
+		case "time":
+			progress.OutTime = value
+		case "bitrate":
+			progress.Bitrate = value
+		case "dup":
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+				progress.DupFrames = v
+			}
+		case "drop":
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+				progress.DropFrames = v
+			}
+		case "speed":
+			progress.Speed = value
+		}
+	}
+
+	return true
+}
+
+// parseSize convierte tamaños de FFmpeg ("1234kB", "12MiB", "N/A") a bytes
+func parseSize(value string) int64 {
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"KiB", 1024}, {"MiB", 1024 * 1024}, {"GiB", 1024 * 1024 * 1024},
+		{"kB", 1024}, {"mB", 1024 * 1024}, {"MB", 1024 * 1024}, {"GB", 1024 * 1024 * 1024},
+		{"B", 1},
+	}
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(value, unit.suffix), 64); err == nil {
+				return int64(v * float64(unit.factor))
+			}
+			return 0
+		}
+	}
+	v, _ := strconv.ParseInt(value, 10, 64)
+	return v
+}
+
+// BitrateKbps convierte el bitrate reportado por FFmpeg ("4972.1kbits/s") a kbit/s
+func (p Progress) BitrateKbps() float64 {
+	value := strings.TrimSpace(p.Bitrate)
+	switch {
+	case strings.HasSuffix(value, "kbits/s"):
+		v, _ := strconv.ParseFloat(strings.TrimSuffix(value, "kbits/s"), 64)
+		return v
+	case strings.HasSuffix(value, "Mbits/s"):
+		v, _ := strconv.ParseFloat(strings.TrimSuffix(value, "Mbits/s"), 64)
+		return v * 1000
+	case strings.HasSuffix(value, "bits/s"):
+		v, _ := strconv.ParseFloat(strings.TrimSuffix(value, "bits/s"), 64)
+		return v / 1000
+	}
+	return 0
+}
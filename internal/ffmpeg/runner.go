@@ -0,0 +1,76 @@
+package ffmpeg
+
+import (
+	"context"
+	"io"
+	"os/exec"
+)
+
+// Runner lanza procesos FFmpeg. ExecRunner usa el ejecutable real; las pruebas
+// pueden usar un fake (ver ffmpegtest) que simula la salida de FFmpeg.
+type Runner interface {
+	// Start lanza un proceso de larga duración (streaming)
+	Start(ctx context.Context, path string, args []string) (Process, error)
+	// Output ejecuta un proceso corto y retorna stdout+stderr (ej: test de encoder)
+	Output(ctx context.Context, path string, args []string) ([]byte, error)
+}
+
+// Process proceso lanzado por un Runner
+type Process interface {
+	Pid() int
+	Stdin() io.WriteCloser // Comandos interactivos ("q")
+	Stderr() io.Reader     // Log y estadísticas de FFmpeg
+	Wait() error           // Bloquea hasta que termina; el error puede implementar ExitCode() int
+	Interrupt() error      // Petición de terminación ordenada (SIGINT donde exista)
+	Kill() error
+}
+
+// ExecRunner lanza el ejecutable real con os/exec
+type ExecRunner struct{}
+
+// Start implementa Runner
+func (ExecRunner) Start(ctx context.Context, path string, args []string) (Process, error) {
+	cmd := exec.CommandContext(ctx, path, args...)
+
+	// Ocultar ventana de consola en Windows
+	configureCmd(cmd)
+
+	// stdin para la detención ordenada ("q")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// Capturar stderr para progreso
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &execProcess{cmd: cmd, stdin: stdin, stderr: stderr}, nil
+}
+
+// Output implementa Runner
+func (ExecRunner) Output(ctx context.Context, path string, args []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, path, args...)
+	configureCmd(cmd)
+	return cmd.CombinedOutput()
+}
+
+// execProcess proceso del sistema operativo
+type execProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr io.ReadCloser
+}
+
+func (p *execProcess) Pid() int              { return p.cmd.Process.Pid }
+func (p *execProcess) Stdin() io.WriteCloser { return p.stdin }
+func (p *execProcess) Stderr() io.Reader     { return p.stderr }
+func (p *execProcess) Wait() error           { return p.cmd.Wait() }
+func (p *execProcess) Interrupt() error      { return interruptProcess(p.cmd.Process) }
+func (p *execProcess) Kill() error           { return p.cmd.Process.Kill() }
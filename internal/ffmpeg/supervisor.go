@@ -0,0 +1,45 @@
+package ffmpeg
+
+// supervisor serializa las operaciones sobre un canal. Su goroutine vive
+// mientras haya operaciones pendientes y se retira al quedar ocioso.
+type supervisor struct {
+	ops     chan func()
+	pending int // Operaciones encoladas o en curso (protegido por Manager.mutex)
+}
+
+// supervise ejecuta op en el supervisor del canal y espera su resultado.
+// op no debe volver a llamar a supervise para el mismo canal.
+func (m *Manager) supervise(channelID string, op func() error) error {
+	m.mutex.Lock()
+	sup, exists := m.supervisors[channelID]
+	if !exists {
+		sup = &supervisor{ops: make(chan func())}
+		m.supervisors[channelID] = sup
+		go m.runSupervisor(channelID, sup)
+	}
+	sup.pending++
+	m.mutex.Unlock()
+
+	result := make(chan error, 1)
+	sup.ops <- func() { result <- op() }
+	return <-result
+}
+
+// runSupervisor ejecuta las operaciones del canal en orden de llegada
+func (m *Manager) runSupervisor(channelID string, sup *supervisor) {
+	for op := range sup.ops {
+		op()
+
+		m.mutex.Lock()
+		sup.pending--
+		idle := sup.pending == 0
+		if idle {
+			delete(m.supervisors, channelID)
+		}
+		m.mutex.Unlock()
+
+		if idle {
+			return
+		}
+	}
+}
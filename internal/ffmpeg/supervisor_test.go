@@ -0,0 +1,246 @@
+package ffmpeg_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"servidor-stream/internal/ffmpeg"
+	"servidor-stream/internal/ffmpeg/ffmpegtest"
+)
+
+// Las operaciones concurrentes sobre un canal se serializan: nunca hay dos
+// procesos del mismo canal vivos a la vez y al final no queda nada corriendo
+func TestConcurrentStartStopSameChannel(t *testing.T) {
+	var runner *ffmpegtest.Runner
+	var mutex sync.Mutex
+	overlaps := 0
+	runner = ffmpegtest.NewRunnerFunc(func([]string) ffmpegtest.Script {
+		// Se llama al lanzar: el proceso anterior ya debe haber terminado
+		if runner.Running() != 0 {
+			mutex.Lock()
+			overlaps++
+			mutex.Unlock()
+		}
+		return ffmpegtest.Script{Lines: []string{inputLine, statsLine}, Hold: true}
+	})
+	m, rec := newManager(t, runner)
+	m.SetStopGrace(20 * time.Millisecond)
+	config := streamConfig(t, "a")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if (i+j)%3 == 0 {
+					m.Stop("a")
+				} else if err := m.Start(config); err != nil {
+					t.Error(err)
+				}
+				m.IsRunning("a")
+				m.GetAllProcessInfo()
+			}
+		}(i)
+	}
+	wg.Wait()
+	m.Stop("a")
+
+	if overlaps != 0 {
+		t.Errorf("%d lanzamientos con otro proceso del canal vivo", overlaps)
+	}
+	if m.IsRunning("a") || runner.Running() != 0 {
+		t.Error("quedó un proceso corriendo tras el Stop final")
+	}
+	eventually(t, "supervisor retirado", func() bool { return m.Supervisors() == 0 })
+
+	// Ningún proceso reemplazado o detenido reporta un error
+	if n := rec.count(ffmpeg.EventError); n != 0 {
+		t.Errorf("eventos de error = %d", n)
+	}
+}
+
+// Canales distintos no se esperan entre sí
+func TestChannelsAreIndependent(t *testing.T) {
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Hold: true})
+	runner.OutputFunc = func(ctx context.Context, args []string) ([]byte, error) {
+		close(probing)
+		<-release
+		return nil, nil
+	}
+	m, _ := newManager(t, runner)
+
+	slow := streamConfig(t, "a")
+	slow.VideoEncoder = "h264_nvenc"
+	started := make(chan error, 1)
+	go func() { started <- m.StartWithFallback(slow) }()
+	<-probing
+
+	done := make(chan error, 1)
+	go func() { done <- m.Start(streamConfig(t, "b")) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("el canal b esperó a la prueba de encoder del canal a")
+	}
+
+	close(release)
+	if err := <-started; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Un Stop que llega mientras el lanzamiento prueba el encoder de hardware
+// espera a que termine el lanzamiento y detiene el proceso recién creado
+func TestStopDuringHardwareProbe(t *testing.T) {
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{Lines: []string{statsLine}, Hold: true})
+	runner.OutputFunc = func(ctx context.Context, args []string) ([]byte, error) {
+		close(probing)
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil, &ffmpegtest.ExitError{Code: 1}
+	}
+	m, rec := newManager(t, runner)
+
+	config := streamConfig(t, "a")
+	config.VideoEncoder = "h264_nvenc"
+	started := make(chan error, 1)
+	go func() { started <- m.StartWithFallback(config) }()
+	<-probing
+
+	stopped := make(chan struct{})
+	go func() {
+		m.Stop("a")
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop no esperó al lanzamiento en curso")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-started; err != nil {
+		t.Fatal(err)
+	}
+	<-stopped
+
+	if m.IsRunning("a") || runner.Running() != 0 {
+		t.Fatal("el proceso lanzado tras la prueba sigue corriendo")
+	}
+	event := rec.wait(t, ffmpeg.EventStopped)
+	if event.Data["requested"] != true {
+		t.Errorf("detención = %v, se esperaba solicitada", event.Data)
+	}
+	eventually(t, "supervisor retirado", func() bool { return m.Supervisors() == 0 })
+}
+
+// Las líneas y la salida tardías de un proceso reemplazado no generan eventos
+// ni tocan el estado del proceso nuevo
+func TestReplacedProcessLateOutputIgnored(t *testing.T) {
+	// Sin frames antes del reemplazo: el receptor y los frames llegan tarde
+	var late []string
+	for i := 0; i < 4; i++ {
+		late = append(late, "[srt @ 0x55] Connection was broken")
+	}
+	late = append(late, "SRT: accepted connection", statsLine, "[srt @ 0x55] Connection lost")
+
+	var mutex sync.Mutex
+	launches := 0
+	runner := ffmpegtest.NewRunnerFunc(func([]string) ffmpegtest.Script {
+		mutex.Lock()
+		defer mutex.Unlock()
+		launches++
+		if launches == 1 {
+			// Ignora "q": sigue escribiendo durante la gracia y muere por kill
+			return ffmpegtest.Script{Lines: late, LineDelay: 30 * time.Millisecond, Hold: true, IgnoreQuit: true}
+		}
+		return ffmpegtest.Script{Lines: []string{statsLine}, Hold: true}
+	})
+	m, rec := newManager(t, runner)
+	m.SetStopGrace(400 * time.Millisecond)
+
+	if err := m.Start(streamConfig(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	first := rec.wait(t, ffmpeg.EventStarted).Generation
+
+	if err := m.Start(streamConfig(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	rec.wait(t, ffmpeg.EventReady)
+	time.Sleep(50 * time.Millisecond)
+
+	for _, event := range rec.all() {
+		if event.Generation == first && event.Type != ffmpeg.EventStarted {
+			t.Errorf("evento tardío del proceso reemplazado: %s %q", event.Type, event.Message)
+		}
+		if event.Type == ffmpeg.EventError || event.Type == ffmpeg.EventStopped {
+			t.Errorf("evento de fin inesperado: %s (generación %d)", event.Type, event.Generation)
+		}
+	}
+
+	info, err := m.GetProcessInfo("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Generation == first || info.ErrorCode != "" || info.LastError != "" || info.SRTConnected {
+		t.Errorf("estado del proceso nuevo contaminado: %+v", info)
+	}
+}
+
+// emitFor/markReady descartan eventos de generaciones que ya no son vigentes
+// aunque el reemplazo y las líneas de stderr ocurran a la vez
+func TestGenerationsUnderConcurrentReplace(t *testing.T) {
+	runner := ffmpegtest.NewRunner(ffmpegtest.Script{
+		Lines:     []string{inputLine, statsLine, "SRT: accepted connection", statsLine},
+		LineDelay: time.Millisecond,
+		Hold:      true,
+	})
+	m, rec := newManager(t, runner)
+	m.SetStopGrace(10 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if err := m.Start(streamConfig(t, fmt.Sprintf("canal-%d", i%2))); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Cada EventReady corresponde a un proceso lanzado y se emite una sola vez
+	seen := make(map[uint64]bool)
+	for _, event := range rec.all() {
+		if event.Type != ffmpeg.EventReady {
+			continue
+		}
+		if seen[event.Generation] {
+			t.Errorf("EventReady repetido para la generación %d", event.Generation)
+		}
+		seen[event.Generation] = true
+	}
+	if n := rec.count(ffmpeg.EventError) + rec.count(ffmpeg.EventStopped); n != 0 {
+		t.Errorf("los reemplazos emitieron %d eventos de fin", n)
+	}
+	if runner.Running() != 2 {
+		t.Errorf("procesos vivos = %d, se esperaba uno por canal", runner.Running())
+	}
+}
@@ -0,0 +1,239 @@
+// Package logfile escribe archivos de log con rotación por tamaño y por tiempo
+// y retención de los archivos rotados.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedFormat sufijo de fecha de los archivos rotados (<nombre>-<fecha>.log)
+const rotatedFormat = "20060102-150405"
+
+// Options rotación y retención
+type Options struct {
+	MaxSize    int64         // Rotar al superar este tamaño en bytes (0 = sin límite)
+	Interval   time.Duration // Rotar al cruzar un límite de este intervalo en hora local, ej: 24h = a medianoche (0 = sin límite)
+	MaxBackups int           // Archivos rotados que se conservan (0 = todos)
+	MaxAge     time.Duration // Borrar archivos rotados más antiguos (0 = nunca)
+}
+
+// Writer archivo de log que rota solo. Es seguro usarlo desde varias goroutines.
+type Writer struct {
+	path    string
+	options Options
+	mutex   sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time        // Periodo del archivo actual (rotación por tiempo)
+	now     func() time.Time // Reemplazable en pruebas
+}
+
+// Open abre (o crea) el archivo de log en modo append, creando el directorio
+func Open(path string, options Options) (*Writer, error) {
+	return openWithClock(path, options, time.Now)
+}
+
+// openWithClock igual que Open con el reloj indicado
+func openWithClock(path string, options Options, now func() time.Time) (*Writer, error) {
+	w := &Writer{path: path, options: options, now: now}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.cleanup()
+	return w, nil
+}
+
+// Path ruta del archivo actual
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// open abre el archivo actual. Si ya existía, su periodo es el de la última
+// escritura: un archivo de ayer rota en la primera escritura de hoy.
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = info.Size()
+	w.opened = w.now()
+	if w.size > 0 {
+		w.opened = info.ModTime()
+	}
+	return nil
+}
+
+// Write escribe p, rotando antes si el archivo llegó al tamaño o cambió de periodo
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(len(p), w.now()) {
+		if err := w.rotate(); err != nil {
+			// Seguir escribiendo en el archivo actual antes que perder el log
+			fmt.Fprintf(os.Stderr, "logfile: error rotando %s: %v\n", w.path, err)
+		}
+		if w.file == nil {
+			return 0, os.ErrClosed // No se pudo reabrir
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// shouldRotate indica si hay que rotar antes de escribir n bytes
+func (w *Writer) shouldRotate(n int, now time.Time) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.options.MaxSize > 0 && w.size+int64(n) > w.options.MaxSize {
+		return true
+	}
+	if w.options.Interval > 0 && period(now, w.options.Interval) != period(w.opened, w.options.Interval) {
+		return true
+	}
+	return false
+}
+
+// period inicio del intervalo que contiene t, alineado a la hora local
+func period(t time.Time, interval time.Duration) time.Time {
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(interval).Add(-shift)
+}
+
+// Rotate cierra el archivo actual, lo renombra con la fecha y abre uno nuevo
+func (w *Writer) Rotate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+func (w *Writer) rotate() error {
+	w.file.Close()
+	w.file = nil
+
+	rotated := w.rotatedName(w.now())
+	renameErr := os.Rename(w.path, rotated)
+
+	// Reabrir siempre: aunque falle el rename, el log debe continuar
+	if err := w.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	w.cleanup()
+	return nil
+}
+
+// rotatedName nombre libre para el archivo rotado
+func (w *Writer) rotatedName(now time.Time) string {
+	ext := filepath.Ext(w.path)
+	base := strings.TrimSuffix(w.path, ext) + "-" + now.Format(rotatedFormat)
+	name := base + ext
+	for n := 2; ; n++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+		name = fmt.Sprintf("%s.%d%s", base, n, ext)
+	}
+}
+
+// Rotated archivos rotados, del más antiguo al más reciente
+func (w *Writer) Rotated() ([]string, error) {
+	ext := filepath.Ext(w.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(w.path, ext) + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(filepath.Base(w.path), ext) + "-"
+	modTimes := map[string]time.Time{}
+	rotated := matches[:0]
+	for _, match := range matches {
+		// Descartar archivos de otro log con el mismo prefijo (ej: app-ffmpeg.log)
+		stamp := strings.TrimPrefix(filepath.Base(match), prefix)
+		if len(stamp) < len(rotatedFormat) {
+			continue
+		}
+		if _, err := time.Parse(rotatedFormat, stamp[:len(rotatedFormat)]); err != nil {
+			continue
+		}
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		modTimes[match] = info.ModTime()
+		rotated = append(rotated, match)
+	}
+
+	// La última escritura de cada archivo ordena también los rotados en el mismo segundo
+	sort.SliceStable(rotated, func(i, j int) bool {
+		return modTimes[rotated[i]].Before(modTimes[rotated[j]])
+	})
+	return rotated, nil
+}
+
+// cleanup borra los archivos rotados que exceden MaxBackups o MaxAge
+func (w *Writer) cleanup() {
+	if w.options.MaxBackups <= 0 && w.options.MaxAge <= 0 {
+		return
+	}
+	rotated, err := w.Rotated()
+	if err != nil {
+		return
+	}
+
+	cutoff := w.now().Add(-w.options.MaxAge)
+	for i, path := range rotated {
+		remove := w.options.MaxBackups > 0 && len(rotated)-i > w.options.MaxBackups
+		if !remove && w.options.MaxAge > 0 {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+				remove = true
+			}
+		}
+		if remove {
+			os.Remove(path)
+		}
+	}
+}
+
+// Close cierra el archivo (las escrituras posteriores fallan con os.ErrClosed)
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
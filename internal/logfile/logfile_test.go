@@ -0,0 +1,228 @@
+package logfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock reloj manual para la rotación por tiempo
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mutex.Lock()
+	c.now = t
+	c.mutex.Unlock()
+}
+
+func openTest(t *testing.T, path string, options Options, clock *fakeClock) *Writer {
+	t.Helper()
+	w, err := openWithClock(path, options, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func write(t *testing.T, w *Writer, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// contents contenido de los archivos rotados (del más antiguo al más reciente)
+func contents(t *testing.T, w *Writer) []string {
+	t.Helper()
+	rotated, err := w.Rotated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, path := range rotated {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, string(data))
+	}
+	return result
+}
+
+func TestRotateBySize(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxSize     int64
+		writes      []string
+		wantRotated []string
+		wantCurrent string
+	}{
+		{"sin límite", 0, []string{"aaaa", "bbbb", "cccc"}, nil, "aaaabbbbcccc"},
+		{"por debajo del límite", 10, []string{"aaaa", "bbbb"}, nil, "aaaabbbb"},
+		{"justo en el límite", 8, []string{"aaaa", "bbbb", "c"}, []string{"aaaabbbb"}, "c"},
+		{"varias rotaciones", 5, []string{"aaaa", "bbbb", "cccc"}, []string{"aaaa", "bbbb"}, "cccc"},
+		// Una escritura mayor que el límite va entera a un archivo nuevo
+		{"escritura mayor que el límite", 4, []string{"aa", "bbbbbbbb", "cc"}, []string{"aa", "bbbbbbbb"}, "cc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 1, 15, 10, 0, 0, 0, time.Local)}
+			path := filepath.Join(t.TempDir(), "logs", "app.log")
+			w := openTest(t, path, Options{MaxSize: tt.maxSize}, clock)
+
+			for _, line := range tt.writes {
+				write(t, w, line)
+				clock.Set(clock.Now().Add(time.Second)) // Nombres de rotado distintos
+			}
+
+			if got := contents(t, w); strings.Join(got, "|") != strings.Join(tt.wantRotated, "|") {
+				t.Errorf("rotados = %q, se esperaba %q", got, tt.wantRotated)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.wantCurrent {
+				t.Errorf("archivo actual = %q, se esperaba %q", data, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestRotateByInterval(t *testing.T) {
+	day := func(d, h, m int) time.Time { return time.Date(2026, 1, d, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		name     string
+		interval time.Duration
+		times    []time.Time // Hora de cada escritura
+		want     int         // Archivos rotados esperados
+	}{
+		{"mismo día", 24 * time.Hour, []time.Time{day(15, 0, 1), day(15, 12, 0), day(15, 23, 59)}, 0},
+		{"cruza la medianoche", 24 * time.Hour, []time.Time{day(15, 23, 59), day(16, 0, 0)}, 1},
+		{"varios días", 24 * time.Hour, []time.Time{day(15, 10, 0), day(16, 10, 0), day(16, 11, 0), day(18, 9, 0)}, 2},
+		{"por hora", time.Hour, []time.Time{day(15, 10, 0), day(15, 10, 59), day(15, 11, 0), day(15, 12, 30)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: tt.times[0]}
+			w := openTest(t, filepath.Join(t.TempDir(), "app.log"), Options{Interval: tt.interval}, clock)
+			for _, at := range tt.times {
+				clock.Set(at)
+				write(t, w, at.Format(time.TimeOnly)+"\n")
+			}
+			if got := len(contents(t, w)); got != tt.want {
+				t.Errorf("rotados = %d, se esperaba %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// Un archivo existente de ayer rota con la primera escritura de hoy
+func TestRotateExistingFileFromPreviousPeriod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("ayer\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Date(2026, 1, 14, 22, 0, 0, 0, time.Local)
+	if err := os.Chtimes(path, yesterday, yesterday); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &fakeClock{now: time.Date(2026, 1, 15, 8, 0, 0, 0, time.Local)}
+	w := openTest(t, path, Options{Interval: 24 * time.Hour}, clock)
+	write(t, w, "hoy\n")
+
+	if got := contents(t, w); len(got) != 1 || got[0] != "ayer\n" {
+		t.Errorf("rotados = %q, se esperaba el archivo de ayer", got)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		options Options
+		ages    []time.Duration // Antigüedad de los rotados existentes, del más antiguo al más reciente
+		want    int             // Rotados que quedan tras una rotación más
+	}{
+		{"sin límites", Options{}, []time.Duration{72 * time.Hour, 48 * time.Hour, 24 * time.Hour}, 4},
+		{"MaxBackups", Options{MaxBackups: 2}, []time.Duration{72 * time.Hour, 48 * time.Hour, 24 * time.Hour}, 2},
+		{"MaxAge", Options{MaxAge: 36 * time.Hour}, []time.Duration{72 * time.Hour, 48 * time.Hour, 24 * time.Hour}, 2},
+		{"ambos", Options{MaxBackups: 1, MaxAge: 36 * time.Hour}, []time.Duration{72 * time.Hour, 24 * time.Hour}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+			for i, age := range tt.ages {
+				at := now.Add(-age)
+				name := filepath.Join(dir, "app-"+at.Format(rotatedFormat)+".log")
+				if err := os.WriteFile(name, []byte{byte('a' + i)}, 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(name, at, at); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Otro log con el mismo prefijo: no cuenta ni se borra
+			other := filepath.Join(dir, "app-ffmpeg.log")
+			if err := os.WriteFile(other, []byte("ffmpeg"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			clock := &fakeClock{now: now}
+			w := openTest(t, path, tt.options, clock)
+			write(t, w, "actual")
+			if err := w.Rotate(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := contents(t, w)
+			if len(got) != tt.want {
+				t.Fatalf("rotados = %q, se esperaban %d", got, tt.want)
+			}
+			if got[len(got)-1] != "actual" {
+				t.Errorf("el más reciente = %q, se esperaba el recién rotado", got[len(got)-1])
+			}
+			if _, err := os.Stat(other); err != nil {
+				t.Errorf("se borró otro log: %v", err)
+			}
+		})
+	}
+}
+
+func TestPeriodAlignsToLocalTime(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	at := time.Date(2026, 1, 15, 1, 30, 0, 0, zone)
+	if got, want := period(at, 24*time.Hour), time.Date(2026, 1, 15, 0, 0, 0, 0, zone); !got.Equal(want) {
+		t.Errorf("period = %v, se esperaba la medianoche local %v", got, want)
+	}
+	if got, want := period(at, time.Hour), time.Date(2026, 1, 15, 1, 0, 0, 0, zone); !got.Equal(want) {
+		t.Errorf("period = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	w := openTest(t, filepath.Join(t.TempDir(), "app.log"), Options{}, &fakeClock{now: time.Now()})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write tras Close = %v, se esperaba os.ErrClosed", err)
+	}
+	if err := w.Rotate(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Rotate tras Close = %v, se esperaba os.ErrClosed", err)
+	}
+}
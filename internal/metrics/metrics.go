@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Tipos de métrica Prometheus
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+// ContentType cabecera del formato de exposición de texto de Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Sample valor de una métrica con sus labels
+type Sample struct {
+	Labels []string // Pares nombre, valor
+	Value  float64
+}
+
+// Family familia de métricas con nombre, ayuda y tipo
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// NewGauge crea una familia de tipo gauge
+func NewGauge(name, help string) *Family {
+	return &Family{Name: name, Help: help, Type: TypeGauge}
+}
+
+// NewCounter crea una familia de tipo counter
+func NewCounter(name, help string) *Family {
+	return &Family{Name: name, Help: help, Type: TypeCounter}
+}
+
+// Add agrega un valor con labels dados como pares nombre, valor
+func (f *Family) Add(value float64, labels ...string) {
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// Write escribe las familias en formato de exposición de texto de Prometheus
+func Write(w io.Writer, families []*Family) error {
+	bw := bufio.NewWriter(w)
+
+	for _, f := range families {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		bw.WriteString("# HELP " + f.Name + " " + escapeHelp(f.Help) + "\n")
+		bw.WriteString("# TYPE " + f.Name + " " + f.Type + "\n")
+		for _, sample := range f.Samples {
+			bw.WriteString(f.Name)
+			if len(sample.Labels) >= 2 {
+				bw.WriteByte('{')
+				for i := 0; i+1 < len(sample.Labels); i += 2 {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(sample.Labels[i] + `="` + escapeLabel(sample.Labels[i+1]) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + formatValue(sample.Value) + "\n")
+		}
+	}
+
+	return bw.Flush()
+}
+
+// formatValue formatea un valor según el formato de Prometheus
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+// Bool convierte un booleano a 1/0
+func Bool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,265 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Topics (bajo el prefijo configurado, ej: "servidor-stream"):
+//
+//	{prefix}/status                          "online" | "offline" (retenido, LWT)
+//	{prefix}/channels/{channelId}/status     JSON del canal (retenido)
+//	{prefix}/channels/{channelId}/stats      JSON de estadísticas (retenido)
+//	{prefix}/clients                         JSON de clientes conectados (retenido)
+//	{prefix}/cmd/{action}                    comandos (payload JSON opcional)
+//	{prefix}/channels/{channel}/cmd/{action} comandos sobre un canal (ID o label)
+//	{prefix}/responses                       respuestas a los comandos
+
+// ClientID identificador usado para los comandos MQTT en el manejador de
+// mensajes. No es un cliente WebSocket conectado: solo gestiona los canales que
+// creó por MQTT.
+const ClientID = "mqtt-bridge"
+
+// commandQueueSize comandos pendientes antes de descartar los nuevos
+const commandQueueSize = 64
+
+// Options configuración del bridge MQTT
+type Options struct {
+	Broker      string // ej: tcp://localhost:1883
+	ClientID    string
+	Username    string
+	Password    string
+	TopicPrefix string
+}
+
+// Bridge cliente MQTT que publica el estado y recibe comandos
+type Bridge struct {
+	opts      Options
+	client    mqtt.Client
+	handler   func(clientID string, message []byte) []byte
+	newClient func(*mqtt.ClientOptions) mqtt.Client // Reemplazable en pruebas
+	commands  chan []byte                           // Comandos pendientes (en orden de llegada)
+	done      chan struct{}
+	stopOnce  sync.Once
+	mutex     sync.Mutex
+}
+
+// NewBridge crea un nuevo bridge MQTT
+func NewBridge(opts Options, handler func(clientID string, message []byte) []byte) *Bridge {
+	if opts.TopicPrefix == "" {
+		opts.TopicPrefix = "servidor-stream"
+	}
+	opts.TopicPrefix = strings.TrimSuffix(opts.TopicPrefix, "/")
+	if opts.ClientID == "" {
+		opts.ClientID = "servidor-stream"
+	}
+
+	return &Bridge{
+		opts:      opts,
+		handler:   handler,
+		newClient: mqtt.NewClient,
+		commands:  make(chan []byte, commandQueueSize),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start conecta con el broker (con reconexión automática) y se suscribe a los comandos
+func (b *Bridge) Start() error {
+	clientOpts := mqtt.NewClientOptions().
+		AddBroker(b.opts.Broker).
+		SetClientID(b.opts.ClientID).
+		SetUsername(b.opts.Username).
+		SetPassword(b.opts.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(5*time.Second).
+		SetMaxReconnectInterval(30*time.Second).
+		SetWill(b.topic("status"), "offline", 1, true).
+		SetOnConnectHandler(b.onConnect).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Printf("[MQTT] Conexión perdida con %s: %v", b.opts.Broker, err)
+		})
+
+	client := b.newClient(clientOpts)
+
+	b.mutex.Lock()
+	b.client = client
+	b.mutex.Unlock()
+
+	go b.runCommands()
+
+	// Con ConnectRetry el token no falla: reintenta en segundo plano
+	token := client.Connect()
+	if token.WaitTimeout(5*time.Second) && token.Error() != nil {
+		return token.Error()
+	}
+
+	return nil
+}
+
+// Stop publica el estado offline y desconecta
+func (b *Bridge) Stop() {
+	b.stopOnce.Do(func() { close(b.done) })
+
+	b.mutex.Lock()
+	client := b.client
+	b.client = nil
+	b.mutex.Unlock()
+
+	if client == nil {
+		return
+	}
+
+	if client.IsConnected() {
+		client.Publish(b.topic("status"), 1, true, "offline").WaitTimeout(2 * time.Second)
+	}
+	client.Disconnect(500)
+	log.Println("Bridge MQTT detenido")
+}
+
+// onConnect se ejecuta en cada (re)conexión
+func (b *Bridge) onConnect(client mqtt.Client) {
+	log.Printf("[MQTT] Conectado a %s", b.opts.Broker)
+
+	client.Publish(b.topic("status"), 1, true, "online")
+
+	filters := map[string]byte{
+		b.topic("cmd", "+"):                  1,
+		b.topic("channels", "+", "cmd", "+"): 1,
+	}
+	token := client.SubscribeMultiple(filters, b.onCommand)
+	if token.WaitTimeout(5*time.Second) && token.Error() != nil {
+		log.Printf("[MQTT] Error suscribiendo a comandos: %v", token.Error())
+	}
+}
+
+// onCommand encola un comando. Se ejecuta en la goroutine de paho: el comando
+// se procesa en runCommands para no bloquear la recepción de mensajes.
+func (b *Bridge) onCommand(_ mqtt.Client, msg mqtt.Message) {
+	message, ok := b.commandMessage(msg.Topic(), msg.Payload())
+	if !ok {
+		return
+	}
+	log.Printf("[MQTT] Comando %s: %s", msg.Topic(), string(message))
+
+	select {
+	case b.commands <- message:
+	default:
+		log.Printf("[MQTT] Cola de comandos llena, descartado: %s", msg.Topic())
+	}
+}
+
+// runCommands ejecuta los comandos encolados de uno en uno hasta Stop
+func (b *Bridge) runCommands() {
+	for {
+		select {
+		case <-b.done:
+			return
+		case message := <-b.commands:
+			if b.handler == nil {
+				continue
+			}
+			if response := b.handler(ClientID, message); response != nil {
+				b.publish(b.topic("responses"), false, response)
+			}
+		}
+	}
+}
+
+// commandMessage traduce un comando MQTT a un mensaje del protocolo WebSocket
+// (false si el topic no es un comando)
+func (b *Bridge) commandMessage(topic string, payload []byte) ([]byte, bool) {
+	rest, ok := strings.CutPrefix(topic, b.opts.TopicPrefix+"/")
+	if !ok {
+		return nil, false
+	}
+	parts := strings.Split(rest, "/")
+
+	var action, channelID string
+	switch {
+	case len(parts) == 2 && parts[0] == "cmd":
+		action = parts[1]
+	case len(parts) == 4 && parts[0] == "channels" && parts[2] == "cmd":
+		channelID = parts[1]
+		action = parts[3]
+	default:
+		return nil, false
+	}
+	if action == "" {
+		return nil, false
+	}
+
+	fields := map[string]interface{}{}
+	if payload := strings.TrimSpace(string(payload)); payload != "" {
+		if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+			// Payload no JSON: se interpreta como filePath (ej: play_video)
+			fields = map[string]interface{}{"filePath": payload}
+		} else if fields == nil {
+			fields = map[string]interface{}{} // "null"
+		}
+	}
+	fields["action"] = action
+	if channelID != "" {
+		fields["channelId"] = channelID
+	}
+
+	message, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false
+	}
+	return message, true
+}
+
+// PublishChannel publica el estado (retenido) de un canal
+func (b *Bridge) PublishChannel(channelID string, data interface{}) {
+	b.publishJSON(b.topic("channels", channelID, "status"), true, data)
+}
+
+// PublishStats publica las estadísticas (retenidas) de un canal
+func (b *Bridge) PublishStats(channelID string, stats interface{}) {
+	b.publishJSON(b.topic("channels", channelID, "stats"), true, stats)
+}
+
+// PublishClients publica la lista (retenida) de clientes conectados
+func (b *Bridge) PublishClients(clients interface{}) {
+	b.publishJSON(b.topic("clients"), true, clients)
+}
+
+// RemoveChannel borra los mensajes retenidos de un canal eliminado
+func (b *Bridge) RemoveChannel(channelID string) {
+	b.publish(b.topic("channels", channelID, "status"), true, []byte{})
+	b.publish(b.topic("channels", channelID, "stats"), true, []byte{})
+}
+
+// publishJSON serializa y publica un payload
+func (b *Bridge) publishJSON(topic string, retained bool, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[MQTT] Error serializando %s: %v", topic, err)
+		return
+	}
+	b.publish(topic, retained, payload)
+}
+
+// publish publica sin bloquear si el cliente no está conectado
+func (b *Bridge) publish(topic string, retained bool, payload []byte) {
+	b.mutex.Lock()
+	client := b.client
+	b.mutex.Unlock()
+
+	if client == nil || !client.IsConnectionOpen() {
+		return
+	}
+	client.Publish(topic, 1, retained, payload)
+}
+
+// topic construye un topic bajo el prefijo configurado
+func (b *Bridge) topic(parts ...string) string {
+	return fmt.Sprintf("%s/%s", b.opts.TopicPrefix, strings.Join(parts, "/"))
+}
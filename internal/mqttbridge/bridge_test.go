@@ -0,0 +1,306 @@
+package mqttbridge
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// fakeToken token ya completado
+type fakeToken struct{ err error }
+
+func (t fakeToken) Wait() bool                     { return true }
+func (t fakeToken) WaitTimeout(time.Duration) bool { return true }
+func (t fakeToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+func (t fakeToken) Error() error { return t.err }
+
+// published mensaje publicado en el broker simulado
+type published struct {
+	topic    string
+	retained bool
+	payload  string
+}
+
+// fakeClient sustituye al cliente paho: registra publicaciones y suscripciones
+type fakeClient struct {
+	mutex     sync.Mutex
+	connected bool
+	published []published
+	filters   map[string]byte
+	callback  mqtt.MessageHandler
+}
+
+func (c *fakeClient) IsConnected() bool      { return c.isConnected() }
+func (c *fakeClient) IsConnectionOpen() bool { return c.isConnected() }
+func (c *fakeClient) isConnected() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.connected
+}
+func (c *fakeClient) Connect() mqtt.Token {
+	c.mutex.Lock()
+	c.connected = true
+	c.mutex.Unlock()
+	return fakeToken{}
+}
+func (c *fakeClient) Disconnect(uint) {
+	c.mutex.Lock()
+	c.connected = false
+	c.mutex.Unlock()
+}
+func (c *fakeClient) Publish(topic string, _ byte, retained bool, payload interface{}) mqtt.Token {
+	var data string
+	switch v := payload.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	}
+	c.mutex.Lock()
+	c.published = append(c.published, published{topic, retained, data})
+	c.mutex.Unlock()
+	return fakeToken{}
+}
+func (c *fakeClient) Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token {
+	return c.SubscribeMultiple(map[string]byte{topic: qos}, callback)
+}
+func (c *fakeClient) SubscribeMultiple(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+	c.mutex.Lock()
+	c.filters, c.callback = filters, callback
+	c.mutex.Unlock()
+	return fakeToken{}
+}
+func (c *fakeClient) Unsubscribe(...string) mqtt.Token        { return fakeToken{} }
+func (c *fakeClient) AddRoute(string, mqtt.MessageHandler)    {}
+func (c *fakeClient) OptionsReader() mqtt.ClientOptionsReader { return mqtt.ClientOptionsReader{} }
+func (c *fakeClient) deliver(topic, payload string)           { c.callback(c, fakeMessage{topic, payload}) }
+func (c *fakeClient) publications() []published {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]published(nil), c.published...)
+}
+
+// fakeMessage mensaje recibido del broker simulado
+type fakeMessage struct {
+	topic   string
+	payload string
+}
+
+func (m fakeMessage) Duplicate() bool   { return false }
+func (m fakeMessage) Qos() byte         { return 1 }
+func (m fakeMessage) Retained() bool    { return false }
+func (m fakeMessage) Topic() string     { return m.topic }
+func (m fakeMessage) MessageID() uint16 { return 0 }
+func (m fakeMessage) Payload() []byte   { return []byte(m.payload) }
+func (m fakeMessage) Ack()              {}
+
+// startBridge arranca un bridge contra el cliente simulado y simula la conexión
+func startBridge(t *testing.T, handler func(clientID string, message []byte) []byte) (*Bridge, *fakeClient) {
+	t.Helper()
+	client := &fakeClient{}
+	b := NewBridge(Options{Broker: "tcp://localhost:1883", TopicPrefix: "estudio/"}, handler)
+	b.newClient = func(*mqtt.ClientOptions) mqtt.Client { return client }
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	b.onConnect(client)
+	t.Cleanup(b.Stop)
+	return b, client
+}
+
+func TestCommandMessage(t *testing.T) {
+	b := NewBridge(Options{TopicPrefix: "estudio"}, nil)
+
+	tests := []struct {
+		name    string
+		topic   string
+		payload string
+		want    map[string]interface{} // nil = no es un comando
+	}{
+		{"global sin payload", "estudio/cmd/status", "", map[string]interface{}{"action": "status"}},
+		{"canal con ruta", "estudio/channels/Principal/cmd/play_video", `C:\Videos\intro.mp4`,
+			map[string]interface{}{"action": "play_video", "channelId": "Principal", "filePath": `C:\Videos\intro.mp4`}},
+		{"payload JSON", "estudio/channels/abc/cmd/set_port", `{"parameters":{"port":9001}}`,
+			map[string]interface{}{"action": "set_port", "channelId": "abc", "parameters": map[string]interface{}{"port": float64(9001)}}},
+		{"el topic manda sobre el payload", "estudio/channels/abc/cmd/stop", `{"action":"delete_channel","channelId":"otro"}`,
+			map[string]interface{}{"action": "stop", "channelId": "abc"}},
+		{"payload null", "estudio/cmd/status", "null", map[string]interface{}{"action": "status"}},
+		{"payload JSON no objeto", "estudio/cmd/play_video", `"video.mp4"`,
+			map[string]interface{}{"action": "play_video", "filePath": `"video.mp4"`}},
+		{"otro prefijo", "otro/cmd/status", "", nil},
+		{"sin prefijo", "cmd/status", "", nil},
+		{"acción vacía", "estudio/cmd/", "", nil},
+		{"estado publicado", "estudio/channels/abc/status", "", nil},
+		{"demasiados niveles", "estudio/channels/a/b/cmd/stop", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, ok := b.commandMessage(tt.topic, []byte(tt.payload))
+			if tt.want == nil {
+				if ok {
+					t.Errorf("commandMessage(%q) = %s, no debería ser un comando", tt.topic, message)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("commandMessage(%q) no reconocido", tt.topic)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(message, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commandMessage(%q) = %v, se esperaba %v", tt.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectPublishesStatusAndSubscribes(t *testing.T) {
+	_, client := startBridge(t, nil)
+
+	pubs := client.publications()
+	if len(pubs) == 0 || pubs[0] != (published{"estudio/status", true, "online"}) {
+		t.Errorf("publicaciones al conectar = %+v", pubs)
+	}
+	want := map[string]byte{"estudio/cmd/+": 1, "estudio/channels/+/cmd/+": 1}
+	if !reflect.DeepEqual(client.filters, want) {
+		t.Errorf("suscripciones = %v, se esperaba %v", client.filters, want)
+	}
+}
+
+func TestRetainedPublishes(t *testing.T) {
+	b, client := startBridge(t, nil)
+
+	b.PublishChannel("abc", map[string]string{"status": "active"})
+	b.PublishStats("abc", map[string]int{"fps": 25})
+	b.PublishClients([]string{})
+	b.RemoveChannel("abc")
+
+	want := []published{
+		{"estudio/channels/abc/status", true, `{"status":"active"}`},
+		{"estudio/channels/abc/stats", true, `{"fps":25}`},
+		{"estudio/clients", true, `[]`},
+		{"estudio/channels/abc/status", true, ""}, // Borra el retenido
+		{"estudio/channels/abc/stats", true, ""},
+	}
+	if got := client.publications()[1:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("publicaciones = %+v\nse esperaba %+v", got, want)
+	}
+}
+
+func TestPublishSkippedWhileDisconnected(t *testing.T) {
+	b, client := startBridge(t, nil)
+	client.Disconnect(0)
+	before := len(client.publications())
+
+	b.PublishChannel("abc", map[string]string{})
+	if after := len(client.publications()); after != before {
+		t.Errorf("se publicó sin conexión (%d publicaciones nuevas)", after-before)
+	}
+}
+
+func TestCommandsRunOffCallbackInOrder(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan string, 2)
+	_, client := startBridge(t, func(clientID string, message []byte) []byte {
+		if clientID != ClientID {
+			t.Errorf("clientID = %q, se esperaba %q", clientID, ClientID)
+		}
+		<-release // Simula un arranque lento de FFmpeg
+		received <- string(message)
+		return []byte(`{"success":true}`)
+	})
+
+	// El callback de paho no debe esperar al manejador
+	delivered := make(chan struct{})
+	go func() {
+		client.deliver("estudio/channels/a/cmd/play", "")
+		client.deliver("estudio/channels/a/cmd/stop", "")
+		close(delivered)
+	}()
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("el callback MQTT quedó bloqueado por el manejador")
+	}
+
+	close(release)
+	for _, action := range []string{"play", "stop"} {
+		select {
+		case message := <-received:
+			var fields map[string]string
+			json.Unmarshal([]byte(message), &fields)
+			if fields["action"] != action {
+				t.Errorf("comando = %s, se esperaba %s", message, action)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("el comando %s no se ejecutó", action)
+		}
+	}
+
+	// Las respuestas se publican sin retener
+	deadline := time.Now().Add(time.Second)
+	for {
+		var responses []published
+		for _, p := range client.publications() {
+			if p.topic == "estudio/responses" {
+				responses = append(responses, p)
+			}
+		}
+		if len(responses) == 2 {
+			if responses[0].retained || responses[0].payload != `{"success":true}` {
+				t.Errorf("respuesta = %+v", responses[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("respuestas publicadas = %d, se esperaban 2", len(responses))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCommandQueueFullDrops(t *testing.T) {
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	handled := 0
+	b, client := startBridge(t, func(string, []byte) []byte {
+		<-release
+		mutex.Lock()
+		handled++
+		mutex.Unlock()
+		return nil
+	})
+
+	// Uno en ejecución (bloqueado) + la cola llena + descartados
+	for i := 0; i < commandQueueSize+10; i++ {
+		client.deliver("estudio/cmd/status", "")
+	}
+	close(release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mutex.Lock()
+		n := handled
+		mutex.Unlock()
+		if n > 0 && len(b.commands) == 0 {
+			if n > commandQueueSize+1 {
+				t.Errorf("se ejecutaron %d comandos, máximo %d", n, commandQueueSize+1)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("solo se ejecutaron %d comandos", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,428 @@
+package osc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message representa un mensaje OSC
+type Message struct {
+	Address string
+	Args    []interface{}
+	Source  *net.UDPAddr // Remitente (nil en mensajes salientes)
+}
+
+// String retorna el argumento i como string (acepta cualquier tipo)
+func (m Message) String(i int) string {
+	if i >= len(m.Args) {
+		return ""
+	}
+	switch v := m.Args[i].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// messageQueueSize mensajes pendientes antes de descartar los nuevos
+const messageQueueSize = 64
+
+// Server listener OSC sobre UDP con feedback opcional a destinos configurados
+type Server struct {
+	port     int
+	handler  func(msg Message)
+	conn     *net.UDPConn
+	targets  []*net.UDPAddr
+	messages chan Message // Mensajes pendientes (en orden de llegada)
+	done     chan struct{}
+	mutex    sync.RWMutex
+	stopOnce sync.Once
+}
+
+// NewServer crea un nuevo servidor OSC
+func NewServer(port int, handler func(msg Message)) *Server {
+	return &Server{
+		port:     port,
+		handler:  handler,
+		messages: make(chan Message, messageQueueSize),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetFeedbackTargets establece los destinos "host:puerto" para el feedback
+func (s *Server) SetFeedbackTargets(targets []string) error {
+	resolved := make([]*net.UDPAddr, 0, len(targets))
+	var errs []string
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		addr, err := net.ResolveUDPAddr("udp", target)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target, err))
+			continue
+		}
+		resolved = append(resolved, addr)
+	}
+
+	s.mutex.Lock()
+	s.targets = resolved
+	s.mutex.Unlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("destinos OSC inválidos: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Start inicia el listener OSC (bloquea hasta que se detiene)
+func (s *Server) Start(ctx context.Context) error {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: s.port})
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.conn = conn
+	s.mutex.Unlock()
+
+	log.Printf("Servidor OSC escuchando en puerto UDP %d", s.port)
+
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+	go s.runMessages()
+
+	buf := make([]byte, 65536)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			log.Printf("[OSC] Error leyendo paquete: %v", err)
+			continue
+		}
+
+		messages, err := ParsePacket(buf[:n])
+		if err != nil {
+			log.Printf("[OSC] Paquete inválido desde %s: %v", addr, err)
+			continue
+		}
+
+		// El manejador (ej: arrancar un canal) corre en runMessages para no
+		// bloquear la lectura de paquetes
+		for _, msg := range messages {
+			msg.Source = addr
+			select {
+			case s.messages <- msg:
+			default:
+				log.Printf("[OSC] Cola de mensajes llena, descartado: %s desde %s", msg.Address, addr)
+			}
+		}
+	}
+}
+
+// runMessages ejecuta los mensajes encolados de uno en uno hasta Stop
+func (s *Server) runMessages() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case msg := <-s.messages:
+			if s.handler != nil {
+				s.handler(msg)
+			}
+		}
+	}
+}
+
+// Stop detiene el listener OSC
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		s.mutex.Lock()
+		if s.conn != nil {
+			s.conn.Close()
+		}
+		s.mutex.Unlock()
+		log.Println("Servidor OSC detenido")
+	})
+}
+
+// Send envía un mensaje OSC a todos los destinos de feedback
+func (s *Server) Send(address string, args ...interface{}) {
+	s.mutex.RLock()
+	conn := s.conn
+	targets := s.targets
+	s.mutex.RUnlock()
+
+	if conn == nil || len(targets) == 0 {
+		return
+	}
+
+	packet, err := EncodeMessage(Message{Address: address, Args: args})
+	if err != nil {
+		log.Printf("[OSC] Error codificando %s: %v", address, err)
+		return
+	}
+
+	for _, target := range targets {
+		if _, err := conn.WriteToUDP(packet, target); err != nil {
+			log.Printf("[OSC] Error enviando feedback a %s: %v", target, err)
+		}
+	}
+}
+
+// SendTo envía un mensaje OSC a un destino concreto (ej: respuesta al remitente)
+func (s *Server) SendTo(addr *net.UDPAddr, address string, args ...interface{}) error {
+	s.mutex.RLock()
+	conn := s.conn
+	s.mutex.RUnlock()
+
+	if conn == nil {
+		return errors.New("servidor OSC no iniciado")
+	}
+
+	packet, err := EncodeMessage(Message{Address: address, Args: args})
+	if err != nil {
+		return err
+	}
+	_, err = conn.WriteToUDP(packet, addr)
+	return err
+}
+
+// ==================== Codificación OSC 1.0 ====================
+
+// ParsePacket decodifica un paquete OSC (mensaje o bundle)
+func ParsePacket(data []byte) ([]Message, error) {
+	if len(data) == 0 {
+		return nil, errors.New("paquete vacío")
+	}
+
+	if bytes.HasPrefix(data, []byte("#bundle\x00")) {
+		return parseBundle(data)
+	}
+
+	msg, err := parseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{msg}, nil
+}
+
+// parseBundle decodifica un bundle OSC (el time tag se ignora: ejecución inmediata)
+func parseBundle(data []byte) ([]Message, error) {
+	if len(data) < 16 {
+		return nil, errors.New("bundle demasiado corto")
+	}
+
+	var messages []Message
+	pos := 16 // "#bundle\0" + time tag de 8 bytes
+	for pos < len(data) {
+		if pos+4 > len(data) {
+			return nil, errors.New("bundle truncado")
+		}
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		pos += 4
+		if size < 0 || pos+size > len(data) {
+			return nil, errors.New("elemento de bundle truncado")
+		}
+		inner, err := ParsePacket(data[pos : pos+size])
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, inner...)
+		pos += size
+	}
+
+	return messages, nil
+}
+
+// parseMessage decodifica un mensaje OSC
+func parseMessage(data []byte) (Message, error) {
+	var msg Message
+
+	address, pos, err := readString(data, 0)
+	if err != nil {
+		return msg, err
+	}
+	if !strings.HasPrefix(address, "/") {
+		return msg, fmt.Errorf("dirección inválida: %q", address)
+	}
+	msg.Address = address
+
+	if pos >= len(data) {
+		return msg, nil // Sin type tags (OSC 1.0 antiguo)
+	}
+
+	tags, pos, err := readString(data, pos)
+	if err != nil {
+		return msg, err
+	}
+	if !strings.HasPrefix(tags, ",") {
+		return msg, fmt.Errorf("type tags inválidos: %q", tags)
+	}
+
+	for _, tag := range tags[1:] {
+		switch tag {
+		case 'i':
+			if pos+4 > len(data) {
+				return msg, errors.New("int32 truncado")
+			}
+			msg.Args = append(msg.Args, int32(binary.BigEndian.Uint32(data[pos:])))
+			pos += 4
+		case 'f':
+			if pos+4 > len(data) {
+				return msg, errors.New("float32 truncado")
+			}
+			msg.Args = append(msg.Args, math.Float32frombits(binary.BigEndian.Uint32(data[pos:])))
+			pos += 4
+		case 'h':
+			if pos+8 > len(data) {
+				return msg, errors.New("int64 truncado")
+			}
+			msg.Args = append(msg.Args, int64(binary.BigEndian.Uint64(data[pos:])))
+			pos += 8
+		case 'd':
+			if pos+8 > len(data) {
+				return msg, errors.New("float64 truncado")
+			}
+			msg.Args = append(msg.Args, math.Float64frombits(binary.BigEndian.Uint64(data[pos:])))
+			pos += 8
+		case 's', 'S':
+			var str string
+			str, pos, err = readString(data, pos)
+			if err != nil {
+				return msg, err
+			}
+			msg.Args = append(msg.Args, str)
+		case 'b':
+			if pos+4 > len(data) {
+				return msg, errors.New("blob truncado")
+			}
+			size := int(binary.BigEndian.Uint32(data[pos:]))
+			pos += 4
+			if size < 0 || pos+size > len(data) {
+				return msg, errors.New("blob truncado")
+			}
+			if pos+pad4(size) > len(data) {
+				return msg, errors.New("blob sin relleno")
+			}
+			msg.Args = append(msg.Args, append([]byte(nil), data[pos:pos+size]...))
+			pos += pad4(size)
+		case 'T':
+			msg.Args = append(msg.Args, true)
+		case 'F':
+			msg.Args = append(msg.Args, false)
+		case 'N', 'I':
+			msg.Args = append(msg.Args, nil)
+		case 't':
+			// Time tag: se ignora el valor
+			if pos+8 > len(data) {
+				return msg, errors.New("time tag truncado")
+			}
+			pos += 8
+		default:
+			return msg, fmt.Errorf("type tag no soportado: %c", tag)
+		}
+	}
+
+	return msg, nil
+}
+
+// readString lee un string OSC terminado en NUL y alineado a 4 bytes. El
+// relleno debe estar completo: la posición devuelta nunca supera len(data).
+func readString(data []byte, pos int) (string, int, error) {
+	if pos >= len(data) {
+		return "", pos, errors.New("string truncado")
+	}
+	end := bytes.IndexByte(data[pos:], 0)
+	if end < 0 {
+		return "", pos, errors.New("string sin terminar")
+	}
+	next := pos + pad4(end+1)
+	if next > len(data) {
+		return "", pos, errors.New("string sin relleno")
+	}
+	return string(data[pos : pos+end]), next, nil
+}
+
+// pad4 redondea n al siguiente múltiplo de 4
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// EncodeMessage codifica un mensaje OSC
+// Tipos soportados: string, int, int32, int64, float32, float64, bool, []byte, nil
+func EncodeMessage(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writeString(&buf, msg.Address)
+
+	tags := []byte{','}
+	var args bytes.Buffer
+	for _, arg := range msg.Args {
+		switch v := arg.(type) {
+		case string:
+			tags = append(tags, 's')
+			writeString(&args, v)
+		case int:
+			tags = append(tags, 'i')
+			binary.Write(&args, binary.BigEndian, int32(v))
+		case int32:
+			tags = append(tags, 'i')
+			binary.Write(&args, binary.BigEndian, v)
+		case int64:
+			tags = append(tags, 'h')
+			binary.Write(&args, binary.BigEndian, v)
+		case float32:
+			tags = append(tags, 'f')
+			binary.Write(&args, binary.BigEndian, math.Float32bits(v))
+		case float64:
+			tags = append(tags, 'f')
+			binary.Write(&args, binary.BigEndian, math.Float32bits(float32(v)))
+		case bool:
+			if v {
+				tags = append(tags, 'T')
+			} else {
+				tags = append(tags, 'F')
+			}
+		case []byte:
+			tags = append(tags, 'b')
+			binary.Write(&args, binary.BigEndian, int32(len(v)))
+			args.Write(v)
+			args.Write(make([]byte, pad4(len(v))-len(v)))
+		case nil:
+			tags = append(tags, 'N')
+		case time.Duration:
+			tags = append(tags, 'f')
+			binary.Write(&args, binary.BigEndian, math.Float32bits(float32(v.Seconds())))
+		default:
+			return nil, fmt.Errorf("tipo OSC no soportado: %T", arg)
+		}
+	}
+
+	writeString(&buf, string(tags))
+	buf.Write(args.Bytes())
+	return buf.Bytes(), nil
+}
+
+// writeString escribe un string OSC terminado en NUL y alineado a 4 bytes
+func writeString(buf *bytes.Buffer, str string) {
+	buf.WriteString(str)
+	buf.Write(make([]byte, pad4(len(str)+1)-len(str)))
+}
@@ -0,0 +1,242 @@
+package osc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParsePacketRoundTrip(t *testing.T) {
+	msg := Message{
+		Address: "/channel/Plató 1/play",
+		Args:    []interface{}{"intro.mp4", int32(7), float32(0.5), int64(-3), true, false, nil, []byte{1, 2, 3}},
+	}
+	data, err := EncodeMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data)%4 != 0 {
+		t.Fatalf("mensaje sin alinear: %d bytes", len(data))
+	}
+
+	messages, err := ParsePacket(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("se esperaba 1 mensaje, hay %d", len(messages))
+	}
+	if messages[0].Address != msg.Address || !reflect.DeepEqual(messages[0].Args, msg.Args) {
+		t.Errorf("ParsePacket = %+v, se esperaba %+v", messages[0], msg)
+	}
+}
+
+func TestParsePacketBundle(t *testing.T) {
+	first, _ := EncodeMessage(Message{Address: "/stop_all"})
+	second, _ := EncodeMessage(Message{Address: "/status", Args: []interface{}{"x"}})
+
+	var bundle bytes.Buffer
+	bundle.WriteString("#bundle\x00")
+	bundle.Write(make([]byte, 8)) // time tag
+	for _, element := range [][]byte{first, second} {
+		bundle.Write([]byte{0, 0, 0, byte(len(element))})
+		bundle.Write(element)
+	}
+
+	messages, err := ParsePacket(bundle.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].Address != "/stop_all" || messages[1].Address != "/status" {
+		t.Errorf("ParsePacket(bundle) = %+v", messages)
+	}
+}
+
+func TestParsePacketMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"type tags sin relleno", "/a\x00\x00,s\x00"},
+		{"time tag sin relleno", "/a\x00\x00,ts\x00"},
+		{"dirección sin relleno", "/a\x00"},
+		{"dirección sin terminar", "/abc"},
+		{"string truncado", "/a\x00\x00,s\x00\x00"},
+		{"string sin relleno", "/a\x00\x00,s\x00\x00hola\x00"},
+		{"int32 truncado", "/a\x00\x00,i\x00\x00\x00\x00"},
+		{"time tag truncado", "/a\x00\x00,t\x00\x00\x00\x00\x00\x00"},
+		{"blob truncado", "/a\x00\x00,b\x00\x00\x00\x00\x00\x08ab"},
+		{"blob sin relleno", "/a\x00\x00,b\x00\x00\x00\x00\x00\x02ab"},
+		{"blob de tamaño enorme", "/a\x00\x00,b\x00\x00\xff\xff\xff\xff"},
+		{"bundle truncado", "#bundle\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10/a"},
+		{"bundle con elemento inválido", "#bundle\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x04/abc"},
+		{"dirección inválida", "abc\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if messages, err := ParsePacket([]byte(tt.data)); err == nil {
+				t.Errorf("ParsePacket(%q) = %+v, se esperaba error", tt.data, messages)
+			}
+		})
+	}
+}
+
+// Ningún prefijo de un paquete válido debe provocar un panic
+func TestParsePacketTruncatedPrefixes(t *testing.T) {
+	data, err := EncodeMessage(Message{
+		Address: "/channel/a/play",
+		Args:    []interface{}{"video.mp4", int32(1), int64(2), []byte{1, 2, 3, 4, 5}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Time tag al final (EncodeMessage no los genera)
+	data = append(data[:len(data):len(data)], make([]byte, 8)...)
+	tags := bytes.Index(data, []byte(",sihb"))
+	data[tags+5] = 't'
+
+	for n := 0; n < len(data); n++ {
+		ParsePacket(data[:n])
+	}
+	if _, err := ParsePacket(data); err != nil {
+		t.Errorf("paquete completo: %v", err)
+	}
+}
+
+// startServer inicia un servidor en un puerto libre y retorna una conexión
+// para enviarle paquetes y el canal con el resultado de Start
+func startServer(t *testing.T, s *Server) (*net.UDPConn, <-chan error) {
+	t.Helper()
+	result := make(chan error, 1)
+	go func() { result <- s.Start(context.Background()) }()
+
+	deadline := time.Now().Add(3 * time.Second)
+	var addr *net.UDPAddr
+	for addr == nil {
+		s.mutex.RLock()
+		if s.conn != nil {
+			addr = s.conn.LocalAddr().(*net.UDPAddr)
+		}
+		s.mutex.RUnlock()
+		if time.Now().After(deadline) {
+			t.Fatal("el servidor no se inició")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	client, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: addr.Port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, result
+}
+
+func sendMessage(t *testing.T, conn *net.UDPConn, address string) {
+	t.Helper()
+	packet, err := EncodeMessage(Message{Address: address})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(packet); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Un manejador lento no bloquea la lectura: los mensajes se ejecutan en orden
+// en otra goroutine y Stop no espera al manejador
+func TestHandlerDoesNotBlockReadLoop(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	var mutex sync.Mutex
+	var handled []string
+	s := NewServer(0, func(msg Message) {
+		if msg.Address == "/lento" {
+			entered <- struct{}{}
+			<-release
+		}
+		mutex.Lock()
+		handled = append(handled, msg.Address)
+		mutex.Unlock()
+	})
+	conn, result := startServer(t, s)
+
+	sendMessage(t, conn, "/lento")
+	<-entered
+	sendMessage(t, conn, "/a")
+	sendMessage(t, conn, "/b")
+
+	// Esperar a que la lectura encole los dos mensajes con el manejador bloqueado
+	deadline := time.Now().Add(3 * time.Second)
+	for len(s.messages) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("mensajes encolados = %d, la lectura está bloqueada", len(s.messages))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(release)
+	deadline = time.Now().Add(3 * time.Second)
+	for {
+		mutex.Lock()
+		n := len(handled)
+		mutex.Unlock()
+		if n == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("mensajes procesados = %d, se esperaban 3", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if want := []string{"/lento", "/a", "/b"}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("orden = %v, se esperaba %v", handled, want)
+	}
+
+	s.Stop()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Start no terminó tras Stop")
+	}
+}
+
+// Con la cola llena los mensajes nuevos se descartan sin bloquear la lectura
+func TestFullQueueDropsMessages(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	s := NewServer(0, func(msg Message) {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		<-release
+	})
+	defer close(release)
+	conn, result := startServer(t, s)
+
+	sendMessage(t, conn, "/bloquea")
+	<-entered
+	for i := 0; i < messageQueueSize+10; i++ {
+		sendMessage(t, conn, "/relleno")
+		time.Sleep(100 * time.Microsecond)
+	}
+
+	// Stop cierra el socket: Start termina aunque el manejador siga bloqueado
+	s.Stop()
+	select {
+	case <-result:
+	case <-time.After(3 * time.Second):
+		t.Fatal("la lectura quedó bloqueada con la cola llena")
+	}
+	if n := len(s.messages); n != messageQueueSize {
+		t.Errorf("mensajes encolados = %d, se esperaba la cola llena (%d)", n, messageQueueSize)
+	}
+}
@@ -0,0 +1,240 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidPort puerto fuera de 1-65535
+	ErrInvalidPort = errors.New("puerto inválido")
+	// ErrPortTaken el puerto ya está asignado a otro canal
+	ErrPortTaken = errors.New("el puerto ya está asignado a otro canal")
+	// ErrPortUnavailable el puerto está ocupado en el sistema
+	ErrPortUnavailable = errors.New("el puerto está ocupado en el sistema")
+	// ErrNoFreePort no quedan puertos libres en los rangos configurados
+	ErrNoFreePort = errors.New("no hay puertos libres en los rangos configurados")
+)
+
+// Range rango de puertos inclusivo
+type Range struct {
+	Start int
+	End   int
+}
+
+func (r Range) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// Contains indica si el puerto está dentro del rango
+func (r Range) Contains(port int) bool {
+	return port >= r.Start && port <= r.End
+}
+
+// DefaultRanges rangos por defecto para puertos SRT
+var DefaultRanges = []Range{{Start: 9000, End: 9999}}
+
+// ParseRanges interpreta rangos como "9000-9099,9200,9300-9310"
+func ParseRanges(s string) ([]Range, error) {
+	var ranges []Range
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		startStr, endStr, isRange := strings.Cut(part, "-")
+		if !isRange {
+			endStr = startStr
+		}
+		start, err := strconv.Atoi(strings.TrimSpace(startStr))
+		if err != nil {
+			return nil, fmt.Errorf("rango de puertos inválido %q", part)
+		}
+		end, err := strconv.Atoi(strings.TrimSpace(endStr))
+		if err != nil {
+			return nil, fmt.Errorf("rango de puertos inválido %q", part)
+		}
+		if !validPort(start) || !validPort(end) || start > end {
+			return nil, fmt.Errorf("rango de puertos inválido %q", part)
+		}
+		ranges = append(ranges, Range{Start: start, End: end})
+	}
+
+	if len(ranges) == 0 {
+		return nil, errors.New("no se especificó ningún rango de puertos")
+	}
+	return ranges, nil
+}
+
+// FormatRanges inverso de ParseRanges
+func FormatRanges(ranges []Range) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// CheckUDP verifica que un puerto UDP se puede enlazar en el host (SRT usa UDP)
+func CheckUDP(host string, port int) error {
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Allocator asigna puertos SRT a canales: un puerto por canal, el más bajo
+// libre dentro de los rangos configurados (los puertos liberados se reutilizan)
+type Allocator struct {
+	mutex  sync.Mutex
+	ranges []Range
+	owners map[int]string // puerto -> canal
+	ports  map[string]int // canal -> puerto
+	check  func(host string, port int) error
+}
+
+// NewAllocator crea un asignador con los rangos indicados (nil = DefaultRanges)
+func NewAllocator(ranges []Range) *Allocator {
+	if len(ranges) == 0 {
+		ranges = DefaultRanges
+	}
+	return &Allocator{
+		ranges: ranges,
+		owners: make(map[int]string),
+		ports:  make(map[string]int),
+		check:  CheckUDP,
+	}
+}
+
+// SetRanges cambia los rangos de asignación automática. Los puertos ya
+// asignados se conservan aunque queden fuera de los nuevos rangos.
+func (a *Allocator) SetRanges(ranges []Range) {
+	if len(ranges) == 0 {
+		ranges = DefaultRanges
+	}
+	a.mutex.Lock()
+	a.ranges = ranges
+	a.mutex.Unlock()
+}
+
+// SetChecker reemplaza la verificación de puerto libre en el sistema
+func (a *Allocator) SetChecker(check func(host string, port int) error) {
+	a.mutex.Lock()
+	a.check = check
+	a.mutex.Unlock()
+}
+
+// Allocate asigna al canal el puerto libre más bajo de los rangos, saltando los
+// puertos ocupados en el sistema. Si el canal ya tiene puerto, lo retorna.
+func (a *Allocator) Allocate(channelID, host string) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if port, ok := a.ports[channelID]; ok {
+		return port, nil
+	}
+
+	for _, r := range a.ranges {
+		for port := r.Start; port <= r.End; port++ {
+			if _, taken := a.owners[port]; taken {
+				continue
+			}
+			if a.check != nil && a.check(host, port) != nil {
+				continue
+			}
+			a.assign(channelID, port)
+			return port, nil
+		}
+	}
+
+	return 0, ErrNoFreePort
+}
+
+// Assign asigna un puerto concreto al canal (asignación manual) verificando
+// que no lo use otro canal ni otro proceso del sistema. Libera el puerto
+// anterior del canal.
+func (a *Allocator) Assign(channelID, host string, port int) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err := a.validate(channelID, port); err != nil {
+		return err
+	}
+	if a.ports[channelID] == port {
+		return nil
+	}
+	if a.check != nil {
+		if err := a.check(host, port); err != nil {
+			return fmt.Errorf("%w: %d (%v)", ErrPortUnavailable, port, err)
+		}
+	}
+
+	a.assign(channelID, port)
+	return nil
+}
+
+// Reserve registra un puerto ya asignado (ej: canales cargados de disco) sin
+// verificar el sistema: el puerto puede estar en uso por el propio canal
+func (a *Allocator) Reserve(channelID string, port int) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err := a.validate(channelID, port); err != nil {
+		return err
+	}
+	a.assign(channelID, port)
+	return nil
+}
+
+// Release libera el puerto del canal
+func (a *Allocator) Release(channelID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if port, ok := a.ports[channelID]; ok {
+		delete(a.owners, port)
+		delete(a.ports, channelID)
+	}
+}
+
+// Owner retorna el canal al que está asignado un puerto
+func (a *Allocator) Owner(port int) (string, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	channelID, ok := a.owners[port]
+	return channelID, ok
+}
+
+func (a *Allocator) validate(channelID string, port int) error {
+	if !validPort(port) {
+		return fmt.Errorf("%w: %d", ErrInvalidPort, port)
+	}
+	if owner, taken := a.owners[port]; taken && owner != channelID {
+		return fmt.Errorf("%w: %d", ErrPortTaken, port)
+	}
+	return nil
+}
+
+func (a *Allocator) assign(channelID string, port int) {
+	if previous, ok := a.ports[channelID]; ok {
+		delete(a.owners, previous)
+	}
+	a.owners[port] = channelID
+	a.ports[channelID] = port
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
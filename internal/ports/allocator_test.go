@@ -0,0 +1,179 @@
+package ports_test
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+
+	"servidor-stream/internal/ports"
+)
+
+func TestParseRanges(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []ports.Range
+		wantErr bool
+	}{
+		{"9000-9099", []ports.Range{{9000, 9099}}, false},
+		{"9000-9099, 9200 ,9300-9310", []ports.Range{{9000, 9099}, {9200, 9200}, {9300, 9310}}, false},
+		{" 9000 - 9001 ,", []ports.Range{{9000, 9001}}, false},
+		{"", nil, true},
+		{" , ", nil, true},
+		{"9100-9000", nil, true},
+		{"0-10", nil, true},
+		{"65535-65536", nil, true},
+		{"9000-abc", nil, true},
+		{"puerto", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ports.ParseRanges(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRanges(%q) error = %v, se esperaba error: %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && ports.FormatRanges(got) != ports.FormatRanges(tt.want) {
+			t.Errorf("ParseRanges(%q) = %v, se esperaba %v", tt.input, got, tt.want)
+		}
+	}
+
+	if s := ports.FormatRanges([]ports.Range{{9000, 9099}, {9200, 9200}}); s != "9000-9099,9200" {
+		t.Errorf("FormatRanges = %q", s)
+	}
+}
+
+// newAllocator asignador cuyos puertos ocupados en el sistema son busy
+func newAllocator(ranges []ports.Range, busy ...int) *ports.Allocator {
+	a := ports.NewAllocator(ranges)
+	a.SetChecker(func(host string, port int) error {
+		for _, b := range busy {
+			if port == b {
+				return errors.New("address already in use")
+			}
+		}
+		return nil
+	})
+	return a
+}
+
+// Operaciones sucesivas sobre un asignador y el resultado esperado de cada una
+func TestAllocator(t *testing.T) {
+	type op struct {
+		action  string // allocate, assign, reserve, release
+		channel string
+		port    int   // assign/reserve: puerto pedido
+		want    int   // allocate: puerto esperado
+		wantErr error // Error esperado (nil = éxito)
+	}
+	tests := []struct {
+		name   string
+		ranges []ports.Range
+		busy   []int
+		ops    []op
+	}{
+		{"el más bajo libre", []ports.Range{{9000, 9002}}, nil, []op{
+			{action: "allocate", channel: "a", want: 9000},
+			{action: "allocate", channel: "b", want: 9001},
+			{action: "allocate", channel: "a", want: 9000}, // Ya tenía puerto
+		}},
+		{"reutiliza los liberados", []ports.Range{{9000, 9002}}, nil, []op{
+			{action: "allocate", channel: "a", want: 9000},
+			{action: "allocate", channel: "b", want: 9001},
+			{action: "release", channel: "a"},
+			{action: "allocate", channel: "c", want: 9000},
+		}},
+		{"salta los ocupados en el sistema", []ports.Range{{9000, 9002}}, []int{9000, 9001}, []op{
+			{action: "allocate", channel: "a", want: 9002},
+			{action: "allocate", channel: "b", wantErr: ports.ErrNoFreePort},
+		}},
+		{"varios rangos en orden", []ports.Range{{9100, 9100}, {9000, 9001}}, nil, []op{
+			{action: "allocate", channel: "a", want: 9100},
+			{action: "allocate", channel: "b", want: 9000},
+			{action: "allocate", channel: "c", want: 9001},
+			{action: "allocate", channel: "d", wantErr: ports.ErrNoFreePort},
+		}},
+		{"asignación manual", []ports.Range{{9000, 9001}}, []int{9500}, []op{
+			{action: "assign", channel: "a", port: 9000},
+			{action: "allocate", channel: "b", want: 9001},
+			{action: "assign", channel: "c", port: 9000, wantErr: ports.ErrPortTaken},
+			{action: "assign", channel: "c", port: 9500, wantErr: ports.ErrPortUnavailable},
+			{action: "assign", channel: "c", port: 0, wantErr: ports.ErrInvalidPort},
+			{action: "assign", channel: "c", port: 70000, wantErr: ports.ErrInvalidPort},
+			{action: "assign", channel: "a", port: 9000}, // El propio puerto
+		}},
+		{"cambiar de puerto libera el anterior", []ports.Range{{9000, 9001}}, nil, []op{
+			{action: "allocate", channel: "a", want: 9000},
+			{action: "assign", channel: "a", port: 9200},
+			{action: "allocate", channel: "b", want: 9000},
+		}},
+		{"reservar no consulta el sistema", []ports.Range{{9000, 9001}}, []int{9000}, []op{
+			{action: "reserve", channel: "a", port: 9000},
+			{action: "reserve", channel: "b", port: 9000, wantErr: ports.ErrPortTaken},
+			{action: "allocate", channel: "b", want: 9001},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAllocator(tt.ranges, tt.busy...)
+			for i, o := range tt.ops {
+				var err error
+				switch o.action {
+				case "allocate":
+					var port int
+					port, err = a.Allocate(o.channel, "0.0.0.0")
+					if err == nil && port != o.want {
+						t.Fatalf("paso %d: Allocate(%s) = %d, se esperaba %d", i, o.channel, port, o.want)
+					}
+				case "assign":
+					err = a.Assign(o.channel, "0.0.0.0", o.port)
+				case "reserve":
+					err = a.Reserve(o.channel, o.port)
+				case "release":
+					a.Release(o.channel)
+				}
+				if !errors.Is(err, o.wantErr) {
+					t.Fatalf("paso %d: %s(%s) error = %v, se esperaba %v", i, o.action, o.channel, err, o.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocatorOwnerAndRanges(t *testing.T) {
+	a := newAllocator([]ports.Range{{9000, 9000}})
+	if _, err := a.Allocate("a", ""); err != nil {
+		t.Fatal(err)
+	}
+	if owner, ok := a.Owner(9000); !ok || owner != "a" {
+		t.Errorf("Owner(9000) = %q, %v", owner, ok)
+	}
+
+	// Los puertos asignados se conservan fuera de los rangos nuevos
+	a.SetRanges([]ports.Range{{9100, 9100}})
+	if port, _ := a.Allocate("a", ""); port != 9000 {
+		t.Errorf("puerto de a = %d tras cambiar los rangos, se esperaba 9000", port)
+	}
+	if port, _ := a.Allocate("b", ""); port != 9100 {
+		t.Errorf("puerto de b = %d, se esperaba 9100", port)
+	}
+
+	a.Release("a")
+	if _, ok := a.Owner(9000); ok {
+		t.Error("el puerto sigue asignado tras Release")
+	}
+}
+
+// CheckUDP detecta un puerto UDP ocupado
+func TestCheckUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("sin UDP local: %v", err)
+	}
+	defer conn.Close()
+	_, portStr, _ := net.SplitHostPort(conn.LocalAddr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	if err := ports.CheckUDP("127.0.0.1", port); err == nil {
+		t.Error("se esperaba error con el puerto ocupado")
+	}
+}
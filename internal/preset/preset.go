@@ -0,0 +1,267 @@
+// Package preset guarda conjuntos de canales con sus parámetros de emisión
+// (un preset por show) y los exporta/importa como archivos JSON portables.
+package preset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"servidor-stream/internal/channel"
+	"servidor-stream/internal/storage"
+)
+
+// Version versión actual del esquema de los archivos de preset
+const Version = 1
+
+// schema versiones de los archivos de preset
+var schema = storage.Schema{
+	Version: Version,
+	Backups: 1,
+}
+
+// maxNameLength longitud máxima de un nombre en caracteres
+const maxNameLength = 64
+
+// namePattern nombres válidos (se usan como nombre de archivo)
+var namePattern = regexp.MustCompile(`^[\p{L}\p{N}_-][\p{L}\p{N} _-]{0,63}$`)
+
+var (
+	// ErrNotFound no existe un preset con ese nombre
+	ErrNotFound = errors.New("preset no encontrado")
+	// ErrInvalid el preset no es válido
+	ErrInvalid = errors.New("preset inválido")
+)
+
+// Preset conjunto de canales y parámetros de emisión de un show
+type Preset struct {
+	Version     int                        `json:"version"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	CreatedAt   time.Time                  `json:"createdAt"`
+	Channels    []channel.Spec             `json:"channels"`
+	Config      map[string]json.RawMessage `json:"config,omitempty"` // Campos de config.PresetFields
+}
+
+// Summary resumen de un preset para listarlo
+type Summary struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Channels    int       `json:"channels"`
+}
+
+// Summary retorna el resumen del preset
+func (p *Preset) Summary() Summary {
+	return Summary{
+		Name:        p.Name,
+		Description: p.Description,
+		CreatedAt:   p.CreatedAt,
+		Channels:    len(p.Channels),
+	}
+}
+
+// ValidateName verifica un nombre de preset
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: nombre %q (hasta 64 letras, números, espacios, - y _)", ErrInvalid, name)
+	}
+	return nil
+}
+
+// Validate verifica nombre y canales: etiquetas, streams y puertos únicos
+func (p *Preset) Validate() error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+
+	labels := map[string]bool{}
+	streams := map[string]bool{}
+	ports := map[int]bool{}
+	for _, spec := range p.Channels {
+		if err := spec.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if labels[spec.Label] {
+			return fmt.Errorf("%w: canal %s repetido", ErrInvalid, spec.Label)
+		}
+		labels[spec.Label] = true
+
+		stream := spec.SRTStreamName
+		if stream == "" {
+			stream = "STREAM_" + spec.Label
+		}
+		if streams[stream] {
+			return fmt.Errorf("%w: stream %s repetido", ErrInvalid, stream)
+		}
+		streams[stream] = true
+
+		if spec.SRTPort != 0 {
+			if ports[spec.SRTPort] {
+				return fmt.Errorf("%w: puerto %d repetido", ErrInvalid, spec.SRTPort)
+			}
+			ports[spec.SRTPort] = true
+		}
+	}
+	return nil
+}
+
+// Decode lee un preset (archivo del almacén o exportado) y lo valida
+func Decode(data []byte) (*Preset, error) {
+	p := &Preset{}
+	if _, err := schema.Decode(data, func(doc []byte) error {
+		return json.Unmarshal(doc, p)
+	}); err != nil {
+		return nil, err
+	}
+	p.Version = Version
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Encode serializa un preset en el formato de archivo
+func Encode(p *Preset) ([]byte, error) {
+	p.Version = Version
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// Store almacén de presets: un archivo <nombre>.json por preset
+type Store struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewStore crea un almacén en dir (se crea al guardar el primer preset)
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// path archivo de un preset
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// List retorna los presets guardados ordenados por nombre. Los archivos que no
+// se pueden leer se omiten (se retornan en el error).
+func (s *Store) List() ([]Summary, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Summary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []Summary{}
+	var problems []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		p, err := s.read(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		summaries = append(summaries, p.Summary())
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return strings.ToLower(summaries[i].Name) < strings.ToLower(summaries[j].Name)
+	})
+	return summaries, errors.Join(problems...)
+}
+
+// Get lee un preset
+func (s *Store) Get(name string) (*Preset, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p, err := s.read(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return p, err
+}
+
+// Exists indica si hay un preset con ese nombre
+func (s *Store) Exists(name string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := os.Stat(s.path(name))
+	return err == nil
+}
+
+// Save guarda un preset (reemplaza el que tenga el mismo nombre)
+func (s *Store) Save(p *Preset) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	data, err := Encode(p)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return schema.Save(s.path(p.Name), data)
+}
+
+// Delete elimina un preset
+func (s *Store) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := os.Remove(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return err
+}
+
+// UniqueName nombre libre a partir de name (name, name-2, name-3...). La base
+// se recorta para que el sufijo no supere la longitud máxima del nombre.
+func (s *Store) UniqueName(name string) string {
+	candidate := name
+	for n := 2; s.Exists(candidate); n++ {
+		suffix := fmt.Sprintf("-%d", n)
+		base := []rune(name)
+		if max := maxNameLength - len(suffix); len(base) > max {
+			base = base[:max]
+		}
+		candidate = string(base) + suffix
+	}
+	return candidate
+}
+
+// read lee y valida un archivo de preset
+func (s *Store) read(path string) (*Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
@@ -0,0 +1,47 @@
+package preset
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestUniqueName(t *testing.T) {
+	long := strings.Repeat("a", 64)
+	accented := strings.Repeat("ñ", 63)
+	tests := []struct {
+		name     string
+		existing []string
+		input    string
+		want     string
+	}{
+		{"libre", nil, "show", "show"},
+		{"ocupado", []string{"show"}, "show", "show-2"},
+		{"varios ocupados", []string{"show", "show-2", "show-3"}, "show", "show-4"},
+		{"64 caracteres", []string{long}, long, long[:62] + "-2"},
+		{"63 caracteres", []string{long[:63]}, long[:63], long[:62] + "-2"},
+		{"sufijo de dos cifras", []string{long, long[:62] + "-2", long[:62] + "-3", long[:62] + "-4", long[:62] + "-5",
+			long[:62] + "-6", long[:62] + "-7", long[:62] + "-8", long[:62] + "-9"}, long, long[:61] + "-10"},
+		// El recorte cuenta caracteres, no bytes
+		{"caracteres multibyte", []string{accented}, accented, strings.Repeat("ñ", 62) + "-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(t.TempDir())
+			for _, name := range tt.existing {
+				if err := store.Save(&Preset{Name: name}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got := store.UniqueName(tt.input)
+			if got != tt.want {
+				t.Errorf("UniqueName = %q (%d caracteres), se esperaba %q", got, utf8.RuneCountInString(got), tt.want)
+			}
+			// El nombre resultante se puede guardar
+			if err := store.Save(&Preset{Name: got}); err != nil {
+				t.Errorf("Save(%q) = %v", got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package restart
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// maxHistory número de intentos conservados por canal
+const maxHistory = 20
+
+// Resultados de un intento de reinicio
+const (
+	ResultOK      = "ok"
+	ResultFailed  = "failed"
+	ResultSkipped = "skipped"
+)
+
+// Policy política de reinicio automático de un canal
+type Policy struct {
+	MaxAttempts  int           // Intentos consecutivos antes de abrir el circuito (0 = sin límite)
+	InitialDelay time.Duration // Retardo del primer intento
+	MaxDelay     time.Duration // Retardo máximo entre intentos
+	Multiplier   float64       // Factor de crecimiento del retardo
+	Jitter       float64       // Variación aleatoria del retardo (0-1, ej: 0.2 = ±20%)
+	ResetWindow  time.Duration // Sin fallos durante este tiempo se reinicia el contador
+}
+
+// DefaultPolicy retorna la política por defecto
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:  5,
+		InitialDelay: 2 * time.Second,
+		MaxDelay:     60 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+		ResetWindow:  5 * time.Minute,
+	}
+}
+
+// Delay calcula el retardo del intento n (1 = primer intento) sin jitter
+func (p Policy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	return time.Duration(delay)
+}
+
+// Attempt registro de un intento de reinicio
+type Attempt struct {
+	Time    time.Time `json:"time"`
+	Attempt int       `json:"attempt"`
+	DelayMs int64     `json:"delayMs"`
+	Reason  string    `json:"reason,omitempty"` // Error que provocó el reinicio
+	Result  string    `json:"result"`           // ok, failed, skipped
+	Error   string    `json:"error,omitempty"`
+}
+
+// State estado de reinicio de un canal (copia, segura para serializar)
+type State struct {
+	Count       int        `json:"count"`       // Reinicios automáticos exitosos
+	Attempts    int        `json:"attempts"`    // Intentos consecutivos en la ventana actual
+	MaxAttempts int        `json:"maxAttempts"` // Límite de la política (0 = sin límite)
+	CircuitOpen bool       `json:"circuitOpen"` // Reintentos suspendidos hasta intervención manual
+	LastFailure *time.Time `json:"lastFailure,omitempty"`
+	NextAttempt *time.Time `json:"nextAttempt,omitempty"`
+	History     []Attempt  `json:"history,omitempty"`
+}
+
+// entry estado interno de un canal
+type entry struct {
+	count       int
+	attempts    int
+	circuitOpen bool
+	lastFailure time.Time
+	nextAttempt time.Time
+	seq         uint64 // Identifica el reinicio pendiente vigente
+	history     []Attempt
+}
+
+// Tracker aplica la política de reinicio y lleva el historial por canal
+type Tracker struct {
+	policy Policy
+	states map[string]*entry
+	rand   *rand.Rand
+	now    func() time.Time // Reemplazable en pruebas
+	mutex  sync.Mutex
+}
+
+// NewTracker crea un nuevo tracker de reinicios
+func NewTracker(policy Policy) *Tracker {
+	return &Tracker{
+		policy: policy,
+		states: make(map[string]*entry),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now,
+	}
+}
+
+// SetPolicy reemplaza la política (afecta a los próximos fallos)
+func (t *Tracker) SetPolicy(policy Policy) {
+	t.mutex.Lock()
+	t.policy = policy
+	t.mutex.Unlock()
+}
+
+// Failure registra un fallo y decide si reintentar.
+// Retorna el retardo, el número de intento y el identificador del reinicio
+// pendiente; ok es false si el circuito está (o acaba de quedar) abierto.
+func (t *Tracker) Failure(channelID string) (delay time.Duration, attempt int, seq uint64, ok bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	e := t.get(channelID)
+	now := t.now()
+	t.expire(e, now)
+	e.lastFailure = now
+	e.seq++
+
+	if e.circuitOpen {
+		return 0, e.attempts, e.seq, false
+	}
+	if t.policy.MaxAttempts > 0 && e.attempts >= t.policy.MaxAttempts {
+		e.circuitOpen = true
+		e.nextAttempt = time.Time{}
+		return 0, e.attempts, e.seq, false
+	}
+
+	e.attempts++
+	delay = t.jitter(t.policy.Delay(e.attempts))
+	e.nextAttempt = now.Add(delay)
+	return delay, e.attempts, e.seq, true
+}
+
+// Due indica si el reinicio pendiente seq sigue vigente (no hubo Reset ni otro fallo)
+func (t *Tracker) Due(channelID string, seq uint64) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	e, exists := t.states[channelID]
+	return exists && e.seq == seq && !e.circuitOpen
+}
+
+// Record agrega un intento al historial
+func (t *Tracker) Record(channelID string, attempt Attempt) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	e := t.get(channelID)
+	if attempt.Time.IsZero() {
+		attempt.Time = t.now()
+	}
+	if attempt.Result == ResultOK {
+		e.count++
+	}
+	e.nextAttempt = time.Time{}
+
+	e.history = append(e.history, attempt)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// Reset cierra el circuito y cancela el reinicio pendiente (intervención manual)
+func (t *Tracker) Reset(channelID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	e, exists := t.states[channelID]
+	if !exists {
+		return
+	}
+	e.attempts = 0
+	e.circuitOpen = false
+	e.nextAttempt = time.Time{}
+	e.seq++
+}
+
+// Remove elimina el estado de un canal
+func (t *Tracker) Remove(channelID string) {
+	t.mutex.Lock()
+	delete(t.states, channelID)
+	t.mutex.Unlock()
+}
+
+// State retorna una copia del estado de un canal (nil si nunca falló)
+func (t *Tracker) State(channelID string) *State {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	e, exists := t.states[channelID]
+	if !exists {
+		return nil
+	}
+	t.expire(e, t.now())
+
+	state := &State{
+		Count:       e.count,
+		Attempts:    e.attempts,
+		MaxAttempts: t.policy.MaxAttempts,
+		CircuitOpen: e.circuitOpen,
+		History:     append([]Attempt(nil), e.history...),
+	}
+	if !e.lastFailure.IsZero() {
+		lastFailure := e.lastFailure
+		state.LastFailure = &lastFailure
+	}
+	if !e.nextAttempt.IsZero() {
+		nextAttempt := e.nextAttempt
+		state.NextAttempt = &nextAttempt
+	}
+	return state
+}
+
+// get obtiene o crea el estado de un canal (requiere el mutex)
+func (t *Tracker) get(channelID string) *entry {
+	e, exists := t.states[channelID]
+	if !exists {
+		e = &entry{}
+		t.states[channelID] = e
+	}
+	return e
+}
+
+// expire reinicia el contador si no hubo fallos durante la ventana (el circuito
+// abierto solo se cierra con Reset)
+func (t *Tracker) expire(e *entry, now time.Time) {
+	if e.circuitOpen || t.policy.ResetWindow <= 0 || e.lastFailure.IsZero() {
+		return
+	}
+	if now.Sub(e.lastFailure) > t.policy.ResetWindow {
+		e.attempts = 0
+	}
+}
+
+// jitter aplica la variación aleatoria al retardo (requiere el mutex)
+func (t *Tracker) jitter(delay time.Duration) time.Duration {
+	if t.policy.Jitter <= 0 {
+		return delay
+	}
+	factor := 1 + t.policy.Jitter*(2*t.rand.Float64()-1)
+	return time.Duration(float64(delay) * factor)
+}
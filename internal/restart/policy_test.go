@@ -0,0 +1,209 @@
+package restart
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// fakeClock reloj manual para la ventana de reinicio
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestTracker tracker con reloj manual y jitter determinista
+func newTestTracker(policy Policy) (*Tracker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)}
+	t := NewTracker(policy)
+	t.now = clock.Now
+	t.rand = rand.New(rand.NewSource(1))
+	return t, clock
+}
+
+func TestPolicyDelay(t *testing.T) {
+	policy := Policy{InitialDelay: 2 * time.Second, MaxDelay: 30 * time.Second, Multiplier: 2}
+	tests := []struct {
+		name    string
+		policy  Policy
+		attempt int
+		want    time.Duration
+	}{
+		{"primer intento", policy, 1, 2 * time.Second},
+		{"intento 0 equivale al primero", policy, 0, 2 * time.Second},
+		{"exponencial", policy, 3, 8 * time.Second},
+		{"tope MaxDelay", policy, 5, 30 * time.Second},
+		{"sin tope", Policy{InitialDelay: time.Second, Multiplier: 3}, 4, 27 * time.Second},
+		{"multiplicador menor que 1 es constante", Policy{InitialDelay: time.Second, Multiplier: 0.5}, 4, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %v, se esperaba %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+// El jitter mantiene el retardo dentro de ±Jitter del valor sin variación
+func TestFailureJitter(t *testing.T) {
+	for _, jitter := range []float64{0, 0.2, 0.5} {
+		policy := Policy{InitialDelay: 10 * time.Second, Multiplier: 1, Jitter: jitter}
+		tracker, _ := newTestTracker(policy)
+		varied := false
+		for i := 0; i < 200; i++ {
+			delay, _, _, ok := tracker.Failure("a")
+			if !ok {
+				t.Fatal("circuito abierto sin MaxAttempts")
+			}
+			low := time.Duration(float64(10*time.Second) * (1 - jitter))
+			high := time.Duration(float64(10*time.Second) * (1 + jitter))
+			if delay < low || delay > high {
+				t.Fatalf("jitter %.1f: retardo %v fuera de [%v, %v]", jitter, delay, low, high)
+			}
+			varied = varied || delay != 10*time.Second
+		}
+		if varied != (jitter > 0) {
+			t.Errorf("jitter %.1f: variación = %v", jitter, varied)
+		}
+	}
+}
+
+// Secuencia de fallos, reseteos y paso del tiempo contra el estado esperado
+func TestTrackerCircuit(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, InitialDelay: time.Second, MaxDelay: 4 * time.Second, Multiplier: 2, ResetWindow: time.Minute}
+
+	type step struct {
+		op          string // fail, reset, wait
+		wait        time.Duration
+		wantOK      bool
+		wantAttempt int
+		wantDelay   time.Duration
+		wantOpen    bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"backoff hasta abrir el circuito", []step{
+			{op: "fail", wantOK: true, wantAttempt: 1, wantDelay: time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 2, wantDelay: 2 * time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 3, wantDelay: 4 * time.Second},
+			{op: "fail", wantOK: false, wantAttempt: 3, wantOpen: true},
+			{op: "fail", wantOK: false, wantAttempt: 3, wantOpen: true},
+		}},
+		{"la ventana reinicia el contador", []step{
+			{op: "fail", wantOK: true, wantAttempt: 1, wantDelay: time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 2, wantDelay: 2 * time.Second},
+			{op: "wait", wait: time.Minute + time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 1, wantDelay: time.Second},
+		}},
+		{"dentro de la ventana no se reinicia", []step{
+			{op: "fail", wantOK: true, wantAttempt: 1, wantDelay: time.Second},
+			{op: "wait", wait: 59 * time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 2, wantDelay: 2 * time.Second},
+		}},
+		{"la ventana no cierra el circuito", []step{
+			{op: "fail", wantOK: true, wantAttempt: 1, wantDelay: time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 2, wantDelay: 2 * time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 3, wantDelay: 4 * time.Second},
+			{op: "fail", wantOK: false, wantAttempt: 3, wantOpen: true},
+			{op: "wait", wait: time.Hour},
+			{op: "fail", wantOK: false, wantAttempt: 3, wantOpen: true},
+		}},
+		{"Reset cierra el circuito", []step{
+			{op: "fail", wantOK: true, wantAttempt: 1, wantDelay: time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 2, wantDelay: 2 * time.Second},
+			{op: "fail", wantOK: true, wantAttempt: 3, wantDelay: 4 * time.Second},
+			{op: "fail", wantOK: false, wantAttempt: 3, wantOpen: true},
+			{op: "reset"},
+			{op: "fail", wantOK: true, wantAttempt: 1, wantDelay: time.Second},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, clock := newTestTracker(policy)
+			for i, s := range tt.steps {
+				switch s.op {
+				case "wait":
+					clock.Advance(s.wait)
+					continue
+				case "reset":
+					tracker.Reset("a")
+					continue
+				}
+				delay, attempt, _, ok := tracker.Failure("a")
+				if ok != s.wantOK || attempt != s.wantAttempt || delay != s.wantDelay {
+					t.Fatalf("paso %d: Failure = (%v, %d, ok=%v), se esperaba (%v, %d, ok=%v)", i, delay, attempt, ok, s.wantDelay, s.wantAttempt, s.wantOK)
+				}
+				if state := tracker.State("a"); state.CircuitOpen != s.wantOpen {
+					t.Fatalf("paso %d: circuito abierto = %v, se esperaba %v", i, state.CircuitOpen, s.wantOpen)
+				}
+			}
+		})
+	}
+}
+
+// Due solo vale para el último reinicio pendiente: otro fallo o Reset lo anulan
+func TestTrackerDue(t *testing.T) {
+	tracker, _ := newTestTracker(Policy{MaxAttempts: 1, InitialDelay: time.Second})
+
+	_, _, first, _ := tracker.Failure("a")
+	if !tracker.Due("a", first) {
+		t.Error("el reinicio pendiente no está vigente")
+	}
+	tracker.Reset("a")
+	if tracker.Due("a", first) {
+		t.Error("el reinicio sigue vigente tras Reset")
+	}
+
+	_, _, second, _ := tracker.Failure("a")
+	_, _, third, ok := tracker.Failure("a") // Abre el circuito
+	if ok || tracker.Due("a", second) || tracker.Due("a", third) {
+		t.Error("con el circuito abierto no debe haber reinicios vigentes")
+	}
+	if tracker.Due("otro", 1) {
+		t.Error("canal sin estado con reinicio vigente")
+	}
+}
+
+func TestTrackerRecordHistory(t *testing.T) {
+	tracker, clock := newTestTracker(Policy{InitialDelay: time.Second})
+	tracker.Failure("a")
+	if state := tracker.State("a"); state.NextAttempt == nil || !state.NextAttempt.Equal(clock.now.Add(time.Second)) {
+		t.Errorf("próximo intento = %v, se esperaba %v", state.NextAttempt, clock.now.Add(time.Second))
+	}
+
+	for i := 0; i < maxHistory+5; i++ {
+		result := ResultFailed
+		if i%2 == 0 {
+			result = ResultOK
+		}
+		tracker.Record("a", Attempt{Attempt: i, Result: result})
+	}
+
+	state := tracker.State("a")
+	if state.NextAttempt != nil {
+		t.Errorf("próximo intento = %v tras registrar el intento", state.NextAttempt)
+	}
+	if state.Count != 13 {
+		t.Errorf("reinicios exitosos = %d, se esperaba 13", state.Count)
+	}
+	if len(state.History) != maxHistory || state.History[0].Attempt != 5 {
+		t.Errorf("historial = %d entradas desde el intento %d, se esperaban las %d últimas", len(state.History), state.History[0].Attempt, maxHistory)
+	}
+	if !state.History[0].Time.Equal(clock.now) {
+		t.Errorf("hora del intento = %v, se esperaba la del reloj", state.History[0].Time)
+	}
+
+	// La copia no comparte el historial
+	state.History[0].Result = "modificado"
+	if tracker.State("a").History[0].Result == "modificado" {
+		t.Error("State comparte el historial interno")
+	}
+
+	tracker.Remove("a")
+	if tracker.State("a") != nil {
+		t.Error("estado tras Remove")
+	}
+}
@@ -0,0 +1,232 @@
+// Package storage persiste archivos JSON de forma atómica y versionada:
+// escritura en temporal + rename, copias de las últimas versiones y migraciones
+// de esquema al cargar.
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultBackups copias anteriores que se conservan de cada archivo (.bak.1 es la más reciente)
+const DefaultBackups = 5
+
+// ErrUnreadable el archivo existe pero no se puede usar (JSON inválido, versión
+// desconocida o contenido que no se puede decodificar)
+var ErrUnreadable = errors.New("archivo ilegible")
+
+// Migration convierte un documento de la versión N a la N+1
+type Migration func(doc []byte) ([]byte, error)
+
+// Schema describe un archivo JSON versionado
+type Schema struct {
+	Version    int         // Versión actual del esquema
+	Migrations []Migration // Migrations[i] convierte de la versión i a la i+1
+	Backups    int         // Copias anteriores a conservar (0 = ninguna)
+}
+
+// Result resultado de cargar un archivo versionado
+type Result struct {
+	FromVersion  int    // Versión encontrada en disco (menor que la actual = migrado)
+	RestoredFrom string // Copia usada si el archivo principal estaba dañado
+	SetAside     string // Dónde quedó el archivo dañado
+	Problem      error  // Por qué no se pudo usar el archivo principal
+}
+
+// Err describe el problema del archivo principal para mostrarlo al usuario
+// (nil si se cargó sin incidencias)
+func (r *Result) Err() error {
+	if r == nil || r.Problem == nil {
+		return nil
+	}
+
+	err := r.Problem
+	if r.RestoredFrom != "" {
+		err = fmt.Errorf("%w; restaurado desde %s", err, filepath.Base(r.RestoredFrom))
+	} else {
+		err = fmt.Errorf("%w; no hay copias válidas, se usan valores iniciales", err)
+	}
+	if r.SetAside != "" {
+		err = fmt.Errorf("%w; el archivo dañado se conservó como %s", err, filepath.Base(r.SetAside))
+	}
+	return err
+}
+
+// Load lee path, aplica las migraciones pendientes y llama a decode con el
+// documento en la versión actual. Si el archivo está dañado lo aparta
+// (path.unreadable-<fecha>) y prueba las copias de seguridad, de la más
+// reciente a la más antigua (Result.Err lo describe). Retorna fs.ErrNotExist
+// si no hay archivo, o ErrUnreadable (junto con el Result) si ni el archivo ni
+// sus copias se pueden usar. decode puede llamarse varias veces.
+func (s Schema) Load(path string, decode func(doc []byte) error) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.Decode(data, decode)
+	if err == nil {
+		return &Result{FromVersion: from}, nil
+	}
+
+	result := &Result{Problem: fmt.Errorf("%s: %w", filepath.Base(path), err)}
+	aside := fmt.Sprintf("%s.unreadable-%s", path, time.Now().Format("20060102-150405"))
+	for n := 2; fileExists(aside); n++ {
+		aside = fmt.Sprintf("%s.unreadable-%s-%d", path, time.Now().Format("20060102-150405"), n)
+	}
+	if os.Rename(path, aside) == nil {
+		result.SetAside = aside
+	}
+
+	for i := 1; i <= s.Backups; i++ {
+		backup := backupPath(path, i)
+		data, err := os.ReadFile(backup)
+		if err != nil {
+			continue
+		}
+		if from, err := s.Decode(data, decode); err == nil {
+			result.FromVersion = from
+			result.RestoredFrom = backup
+			return result, nil
+		}
+	}
+
+	return result, result.Err()
+}
+
+// Decode detecta la versión, migra y decodifica un documento sin tocar el
+// disco (para validar un archivo editado antes de aplicarlo). Retorna la
+// versión encontrada.
+func (s Schema) Decode(data []byte, decode func(doc []byte) error) (int, error) {
+	from, err := Version(data)
+	if err != nil {
+		return 0, err
+	}
+	if from > s.Version {
+		return from, fmt.Errorf("%w: versión %d más reciente que la soportada (%d)", ErrUnreadable, from, s.Version)
+	}
+
+	doc := data
+	for v := from; v < s.Version; v++ {
+		if v >= len(s.Migrations) || s.Migrations[v] == nil {
+			return from, fmt.Errorf("%w: sin migración de la versión %d a la %d", ErrUnreadable, v, v+1)
+		}
+		if doc, err = s.Migrations[v](doc); err != nil {
+			return from, fmt.Errorf("%w: migrando de la versión %d a la %d: %v", ErrUnreadable, v, v+1, err)
+		}
+	}
+
+	if err := decode(doc); err != nil {
+		return from, fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+	return from, nil
+}
+
+// Version versión de esquema de un documento: el campo "version" de un objeto
+// JSON. Un objeto sin el campo es de la versión 1 (los objetos sin versión solo
+// se diferencian de la 1 en que no tienen el campo) y solo un documento que no
+// es un objeto (la lista del formato antiguo) es de la versión 0.
+func Version(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if !json.Valid(trimmed) {
+		return 0, fmt.Errorf("%w: JSON inválido", ErrUnreadable)
+	}
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return 0, nil
+	}
+
+	var probe struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return 0, fmt.Errorf("%w: campo version: %v", ErrUnreadable, err)
+	}
+	if probe.Version == nil {
+		return 1, nil
+	}
+	return *probe.Version, nil
+}
+
+// SetVersion escribe el campo "version" de un objeto JSON (para migraciones
+// que solo cambian la versión)
+func SetVersion(doc []byte, version int) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return nil, err
+	}
+	fields["version"] = json.RawMessage(fmt.Sprint(version))
+	return json.Marshal(fields)
+}
+
+// Save escribe data en path de forma atómica y, si el contenido cambia,
+// conserva la versión anterior como copia de seguridad
+func (s Schema) Save(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if current, err := os.ReadFile(path); err == nil && s.Backups > 0 && !bytes.Equal(current, data) {
+		if err := rotateBackups(path, current, s.Backups); err != nil {
+			return fmt.Errorf("copia de seguridad de %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	return WriteFileAtomic(path, data, 0644)
+}
+
+// WriteFileAtomic escribe en un temporal del mismo directorio, lo sincroniza a
+// disco y lo renombra sobre path: un corte a mitad de escritura deja intacto el
+// archivo anterior
+func WriteFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op tras el rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// rotateBackups desplaza path.bak.1..N-1 una posición y guarda current como path.bak.1
+func rotateBackups(path string, current []byte, keep int) error {
+	os.Remove(backupPath(path, keep))
+	for i := keep - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(path, i), backupPath(path, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return WriteFileAtomic(backupPath(path, 1), current, 0644)
+}
+
+// backupPath ruta de la copia número n
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.bak.%d", path, n)
+}
+
+// fileExists indica si existe path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{"lista del formato antiguo", `[{"id":"a"}]`, 0, false},
+		{"objeto con versión", `{"version":2,"channels":[]}`, 2, false},
+		{"objeto sin versión", `{"webSocketPort":8765}`, 1, false},
+		{"objeto con versión 0", `{"version":0}`, 0, false},
+		{"espacios alrededor", " \n {\"version\":3} \n", 3, false},
+		{"JSON inválido", `{"version":`, 0, true},
+		{"versión no numérica", `{"version":"2"}`, 0, true},
+		{"vacío", ``, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Version([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, se esperaba error: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrUnreadable) {
+					t.Errorf("error = %v, se esperaba ErrUnreadable", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Version = %d, se esperaba %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// testSchema esquema de prueba: v0 lista de nombres, v1 objeto {"items": [...]},
+// v2 añade "count"
+var testSchema = Schema{
+	Version: 2,
+	Migrations: []Migration{
+		func(doc []byte) ([]byte, error) {
+			var items []string
+			if err := json.Unmarshal(doc, &items); err != nil {
+				return nil, err
+			}
+			return json.Marshal(map[string]interface{}{"version": 1, "items": items})
+		},
+		func(doc []byte) ([]byte, error) {
+			var file testFile
+			if err := json.Unmarshal(doc, &file); err != nil {
+				return nil, err
+			}
+			file.Version, file.Count = 2, len(file.Items)
+			return json.Marshal(file)
+		},
+	},
+	Backups: 3,
+}
+
+type testFile struct {
+	Version int      `json:"version"`
+	Items   []string `json:"items"`
+	Count   int      `json:"count"`
+}
+
+func TestDecodeMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		schema   Schema
+		wantFrom int
+		want     testFile
+		wantErr  string
+	}{
+		{"versión 0", `["a","b"]`, testSchema, 0, testFile{2, []string{"a", "b"}, 2}, ""},
+		{"versión 1", `{"version":1,"items":["a"]}`, testSchema, 1, testFile{2, []string{"a"}, 1}, ""},
+		{"objeto sin versión es la 1", `{"items":["a","b","c"]}`, testSchema, 1, testFile{2, []string{"a", "b", "c"}, 3}, ""},
+		{"actual", `{"version":2,"items":[],"count":7}`, testSchema, 2, testFile{2, []string{}, 7}, ""},
+		{"más reciente", `{"version":3}`, testSchema, 3, testFile{}, "más reciente"},
+		{"sin migración", `["a"]`, Schema{Version: 1}, 0, testFile{}, "sin migración de la versión 0 a la 1"},
+		{"migración fallida", `["a",1]`, testSchema, 0, testFile{}, "migrando de la versión 0 a la 1"},
+		{"decode fallido", `{"version":2,"items":"x"}`, testSchema, 2, testFile{}, "cannot unmarshal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testFile
+			from, err := tt.schema.Decode([]byte(tt.data), func(doc []byte) error {
+				got = testFile{}
+				return json.Unmarshal(doc, &got)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !errors.Is(err, ErrUnreadable) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, se esperaba ErrUnreadable con %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.wantFrom {
+				t.Errorf("versión encontrada = %d, se esperaba %d", from, tt.wantFrom)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("documento = %+v, se esperaba %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	doc, err := SetVersion([]byte(`{"items":["a"],"version":1}`), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := Version(doc); v != 2 {
+		t.Errorf("versión = %d en %s, se esperaba 2", v, doc)
+	}
+	if _, err := SetVersion([]byte(`["a"]`), 2); err == nil {
+		t.Error("se esperaba error con un documento que no es un objeto")
+	}
+}
+
+// save guarda contenidos sucesivos en path
+func save(t *testing.T, s Schema, path string, contents ...string) {
+	t.Helper()
+	for _, content := range contents {
+		if err := s.Save(path, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(data)
+}
+
+func TestSaveRotatesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "datos", "file.json")
+	save(t, testSchema, path, `{"n":1}`, `{"n":2}`, `{"n":2}`, `{"n":3}`, `{"n":4}`, `{"n":5}`)
+
+	// El contenido repetido no genera copia; se conservan 3 (Backups)
+	want := map[string]string{
+		path:                `{"n":5}`,
+		backupPath(path, 1): `{"n":4}`,
+		backupPath(path, 2): `{"n":3}`,
+		backupPath(path, 3): `{"n":2}`,
+	}
+	for file, content := range want {
+		if got := readFile(t, file); got != content {
+			t.Errorf("%s = %s, se esperaba %s", filepath.Base(file), got, content)
+		}
+	}
+	if _, err := os.Stat(backupPath(path, 4)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("se conservó una copia de más: %v", err)
+	}
+
+	// Sin temporales huérfanos
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("temporal sin borrar: %s", entry.Name())
+		}
+	}
+}
+
+func TestLoadRestoresBackup(t *testing.T) {
+	tests := []struct {
+		name         string
+		main         string
+		backups      []string // .bak.1, .bak.2, ...
+		wantItems    string
+		wantRestored int // Número de copia usada (0 = ninguna)
+		wantErr      bool
+	}{
+		{"archivo válido", `{"version":2,"items":["a"]}`, nil, "[a]", 0, false},
+		{"archivo migrado", `["a","b"]`, nil, "[a b]", 0, false},
+		{"JSON inválido", `{"version":`, []string{`{"version":2,"items":["copia"]}`}, "[copia]", 1, false},
+		{"versión futura", `{"version":9}`, []string{`{"version":2,"items":["copia"]}`}, "[copia]", 1, false},
+		{"copia más reciente dañada", `{`, []string{`{`, `["antigua"]`}, "[antigua]", 2, false},
+		{"sin copias válidas", `{`, []string{`[`}, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file.json")
+			if err := os.WriteFile(path, []byte(tt.main), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for i, backup := range tt.backups {
+				if err := os.WriteFile(backupPath(path, i+1), []byte(backup), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got testFile
+			result, err := testSchema.Load(path, func(doc []byte) error {
+				got = testFile{}
+				return json.Unmarshal(doc, &got)
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnreadable) {
+					t.Fatalf("error = %v, se esperaba ErrUnreadable", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if items := fmt.Sprint(got.Items); items != tt.wantItems {
+				t.Errorf("items = %s, se esperaba %s", items, tt.wantItems)
+			}
+
+			if tt.wantRestored == 0 {
+				if result.Err() != nil || result.RestoredFrom != "" {
+					t.Errorf("resultado = %+v, se esperaba carga sin incidencias", result)
+				}
+				return
+			}
+			if result.RestoredFrom != backupPath(path, tt.wantRestored) {
+				t.Errorf("restaurado desde %q, se esperaba la copia %d", result.RestoredFrom, tt.wantRestored)
+			}
+			if result.Err() == nil || !strings.Contains(result.Err().Error(), filepath.Base(result.SetAside)) {
+				t.Errorf("Err() = %v, se esperaba la descripción con el archivo apartado", result.Err())
+			}
+			if got := readFile(t, result.SetAside); got != tt.main {
+				t.Errorf("archivo apartado = %s, se esperaba el original %s", got, tt.main)
+			}
+			if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("el archivo dañado sigue en su sitio: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := testSchema.Load(filepath.Join(t.TempDir(), "no-existe.json"), func([]byte) error { return nil })
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("error = %v, se esperaba fs.ErrNotExist", err)
+	}
+}
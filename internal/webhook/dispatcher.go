@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Eventos emitidos por el servidor
+const (
+	EventChannelStarted   = "channel.started"
+	EventChannelStopped   = "channel.stopped"
+	EventChannelError     = "channel.error"
+	EventSRTConnected     = "srt.client_connected"
+	EventSRTDisconnected  = "srt.client_disconnected"
+	EventRestartAttempted = "channel.restart_attempted"
+	EventCircuitOpen      = "channel.circuit_open"
+	EventEncoderFallback  = "encoder.fallback"
+)
+
+// Cabeceras HTTP de cada entrega
+const (
+	HeaderSignature = "X-Signature-256" // sha256=<hex> del cuerpo con el secreto del destino
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderAttempt   = "X-Webhook-Attempt"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultQueueSize  = 256
+	defaultWorkers    = 2
+	initialRetryDelay = 1 * time.Second
+	maxRetryDelay     = 60 * time.Second
+	requestTimeout    = 10 * time.Second
+)
+
+// Target destino de webhooks
+type Target struct {
+	URL    string
+	Secret string   // Clave HMAC-SHA256 (opcional)
+	Events []string // Eventos a enviar (vacío = todos)
+}
+
+// accepts indica si el destino está suscrito a un evento
+func (t Target) accepts(event string) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	for _, e := range t.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload cuerpo JSON enviado en cada webhook
+type Payload struct {
+	ID           string                 `json:"id"`
+	Event        string                 `json:"event"`
+	Timestamp    time.Time              `json:"timestamp"`
+	ChannelID    string                 `json:"channelId,omitempty"`
+	ChannelLabel string                 `json:"channelLabel,omitempty"`
+	Message      string                 `json:"message,omitempty"`
+	Data         map[string]interface{} `json:"data,omitempty"`
+}
+
+// delivery entrega pendiente a un destino
+type delivery struct {
+	target  Target
+	payload Payload
+	body    []byte
+	attempt int // Intentos ya realizados
+}
+
+// targetQueue cola y worker de un destino: un destino lento o caído no retrasa
+// las entregas a los demás
+type targetQueue struct {
+	queue chan delivery
+	stop  chan struct{} // Se cierra al quitar el destino
+}
+
+// Dispatcher envía webhooks en segundo plano con reintentos y backoff
+// exponencial. Cada destino tiene su cola; los reintentos se programan con un
+// temporizador y no ocupan el worker mientras esperan.
+type Dispatcher struct {
+	targets    []Target
+	maxRetries int
+	retryDelay time.Duration // Espera antes del primer reintento
+	client     *http.Client
+	queues     map[string]*targetQueue // Por URL
+	ctx        context.Context         // nil hasta Start
+	mutex      sync.RWMutex
+	wg         sync.WaitGroup
+}
+
+// NewDispatcher crea un nuevo dispatcher de webhooks
+func NewDispatcher(targets []Target, maxRetries int) *Dispatcher {
+	if maxRetries < 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	return &Dispatcher{
+		targets:    targets,
+		maxRetries: maxRetries,
+		retryDelay: initialRetryDelay,
+		client:     &http.Client{Timeout: requestTimeout},
+		queues:     make(map[string]*targetQueue),
+	}
+}
+
+// SetTargets reemplaza los destinos configurados. Las entregas pendientes a
+// destinos que ya no están se descartan.
+func (d *Dispatcher) SetTargets(targets []Target) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.targets = targets
+	for url, q := range d.queues {
+		if !slices.ContainsFunc(targets, func(t Target) bool { return t.URL == url }) {
+			close(q.stop)
+			delete(d.queues, url)
+		}
+	}
+}
+
+// Start arranca los workers de envío hasta que se cancela el contexto
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.ctx = ctx
+	for _, q := range d.queues {
+		d.startWorker(q)
+	}
+}
+
+// Wait espera a que terminen los workers (tras cancelar el contexto)
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Dispatch encola un evento para todos los destinos suscritos
+func (d *Dispatcher) Dispatch(payload Payload) {
+	if payload.ID == "" {
+		payload.ID = uuid.New().String()
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+
+	d.mutex.RLock()
+	targets := d.targets
+	d.mutex.RUnlock()
+
+	var body []byte
+	for _, target := range targets {
+		if target.URL == "" || !target.accepts(payload.Event) {
+			continue
+		}
+		if body == nil {
+			var err error
+			body, err = json.Marshal(payload)
+			if err != nil {
+				log.Printf("[Webhook] Error serializando evento %s: %v", payload.Event, err)
+				return
+			}
+		}
+
+		d.enqueue(delivery{target: target, payload: payload, body: body})
+	}
+}
+
+// enqueue agrega una entrega a la cola de su destino (sin bloquear)
+func (d *Dispatcher) enqueue(del delivery) {
+	d.mutex.Lock()
+	q, ok := d.queues[del.target.URL]
+	if !ok {
+		q = &targetQueue{
+			queue: make(chan delivery, defaultQueueSize),
+			stop:  make(chan struct{}),
+		}
+		d.queues[del.target.URL] = q
+		if d.ctx != nil {
+			d.startWorker(q)
+		}
+	}
+	d.mutex.Unlock()
+
+	select {
+	case q.queue <- del:
+	default:
+		log.Printf("[Webhook] Cola llena, descartando %s para %s", del.payload.Event, del.target.URL)
+	}
+}
+
+// startWorker arranca el worker de una cola. Llamar con mutex tomado.
+func (d *Dispatcher) startWorker(q *targetQueue) {
+	d.wg.Add(1)
+	go d.worker(d.ctx, q)
+}
+
+// worker procesa las entregas de un destino
+func (d *Dispatcher) worker(ctx context.Context, q *targetQueue) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.stop:
+			return
+		case del := <-q.queue:
+			d.deliver(ctx, q, del)
+		}
+	}
+}
+
+// deliver hace un intento de envío y, si el error es reintentable, programa el
+// siguiente con backoff exponencial
+func (d *Dispatcher) deliver(ctx context.Context, q *targetQueue, del delivery) {
+	retry, err := d.send(ctx, del, del.attempt)
+	if err == nil {
+		return
+	}
+	del.attempt++
+	if !retry || del.attempt > d.maxRetries || ctx.Err() != nil {
+		log.Printf("[Webhook] Entrega %s (%s) a %s fallida: %v", del.payload.ID, del.payload.Event, del.target.URL, err)
+		return
+	}
+
+	delay := d.retryDelay << (del.attempt - 1)
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+	log.Printf("[Webhook] Reintento %d/%d de %s a %s en %v: %v", del.attempt, d.maxRetries, del.payload.Event, del.target.URL, delay, err)
+
+	time.AfterFunc(delay, func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.stop: // Destino quitado mientras tanto
+			return
+		default:
+		}
+		select {
+		case q.queue <- del:
+		default:
+			log.Printf("[Webhook] Cola llena, descartando reintento de %s para %s", del.payload.Event, del.target.URL)
+		}
+	})
+}
+
+// send realiza un intento de envío; retorna si el error es reintentable
+func (d *Dispatcher) send(ctx context.Context, del delivery, attempt int) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.target.URL, bytes.NewReader(del.body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "servidor-stream-webhook/1.0")
+	req.Header.Set(HeaderEvent, del.payload.Event)
+	req.Header.Set(HeaderDelivery, del.payload.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(del.payload.Timestamp.Unix(), 10))
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt+1))
+	if del.target.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(del.target.Secret, del.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("HTTP %d", resp.StatusCode)
+	default:
+		// 4xx: el destino rechazó el payload, no tiene sentido reintentar
+		return false, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+}
+
+// Sign calcula la firma HMAC-SHA256 del cuerpo ("sha256=<hex>")
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// request petición recibida por el destino de prueba
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// recorder destino HTTP que responde con los códigos indicados (el último se
+// repite) y registra cada petición
+type recorder struct {
+	mutex    sync.Mutex
+	statuses []int
+	requests []request
+	received chan struct{}
+}
+
+func newRecorder(t *testing.T, statuses ...int) (*recorder, *httptest.Server) {
+	r := &recorder{statuses: statuses, received: make(chan struct{}, 100)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mutex.Lock()
+		r.requests = append(r.requests, request{req.Header.Clone(), body})
+		status := r.statuses[min(len(r.requests), len(r.statuses))-1]
+		r.mutex.Unlock()
+		w.WriteHeader(status)
+		r.received <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+// wait espera n peticiones
+func (r *recorder) wait(t *testing.T, n int) []request {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.received:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("se recibieron %d peticiones, se esperaban %d", i, n)
+		}
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]request(nil), r.requests...)
+}
+
+// expectNoMore comprueba que no llegan más peticiones
+func (r *recorder) expectNoMore(t *testing.T) {
+	t.Helper()
+	select {
+	case <-r.received:
+		t.Error("llegó una petición de más")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func startDispatcher(t *testing.T, maxRetries int, targets ...Target) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(targets, maxRetries)
+	d.retryDelay = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		d.Wait()
+	})
+	return d
+}
+
+func TestDeliverySignatureAndHeaders(t *testing.T) {
+	rec, server := newRecorder(t, http.StatusNoContent)
+	d := startDispatcher(t, 0, Target{URL: server.URL, Secret: "clave"})
+
+	d.Dispatch(Payload{ID: "entrega-1", Event: EventChannelError, ChannelID: "abc"})
+
+	req := rec.wait(t, 1)[0]
+	if got, want := req.header.Get(HeaderSignature), Sign("clave", req.body); got != want {
+		t.Errorf("%s = %q, se esperaba %q", HeaderSignature, got, want)
+	}
+	for header, want := range map[string]string{
+		HeaderEvent:    EventChannelError,
+		HeaderDelivery: "entrega-1",
+		HeaderAttempt:  "1",
+		"Content-Type": "application/json",
+	} {
+		if got := req.header.Get(header); got != want {
+			t.Errorf("%s = %q, se esperaba %q", header, got, want)
+		}
+	}
+	if req.header.Get(HeaderTimestamp) == "" {
+		t.Errorf("falta %s", HeaderTimestamp)
+	}
+}
+
+func TestDeliveryWithoutSecretIsUnsigned(t *testing.T) {
+	rec, server := newRecorder(t, http.StatusOK)
+	d := startDispatcher(t, 0, Target{URL: server.URL})
+
+	d.Dispatch(Payload{Event: EventChannelStarted})
+
+	if sig := rec.wait(t, 1)[0].header.Get(HeaderSignature); sig != "" {
+		t.Errorf("%s = %q sin secreto", HeaderSignature, sig)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac clave
+	want := "sha256=21a69fefed490f3f56de59be0b248591d506dde035781bc6cd160095378c6501"
+	if got := Sign("clave", []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestEventFiltering(t *testing.T) {
+	errorsOnly, errorsServer := newRecorder(t, http.StatusOK)
+	all, allServer := newRecorder(t, http.StatusOK)
+	wildcard, wildcardServer := newRecorder(t, http.StatusOK)
+	d := startDispatcher(t, 0,
+		Target{URL: errorsServer.URL, Events: []string{EventChannelError}},
+		Target{URL: allServer.URL},
+		Target{URL: wildcardServer.URL, Events: []string{"*"}},
+	)
+
+	d.Dispatch(Payload{Event: EventChannelStarted})
+	d.Dispatch(Payload{Event: EventChannelError})
+
+	if got := errorsOnly.wait(t, 1)[0].header.Get(HeaderEvent); got != EventChannelError {
+		t.Errorf("destino filtrado recibió %s", got)
+	}
+	errorsOnly.expectNoMore(t)
+	all.wait(t, 2)
+	wildcard.wait(t, 2)
+}
+
+func TestRetryOnServerErrors(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusTooManyRequests} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			rec, server := newRecorder(t, status, status, http.StatusOK)
+			d := startDispatcher(t, 5, Target{URL: server.URL})
+
+			d.Dispatch(Payload{ID: "entrega-1", Event: EventChannelError})
+
+			requests := rec.wait(t, 3)
+			for i, req := range requests {
+				if got, want := req.header.Get(HeaderAttempt), string(rune('1'+i)); got != want {
+					t.Errorf("intento %d: %s = %q", i+1, HeaderAttempt, got)
+				}
+				if got := req.header.Get(HeaderDelivery); got != "entrega-1" {
+					t.Errorf("intento %d: %s = %q, el ID debe mantenerse", i+1, HeaderDelivery, got)
+				}
+			}
+			rec.expectNoMore(t)
+		})
+	}
+}
+
+func TestNoRetryOnClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			rec, server := newRecorder(t, status)
+			d := startDispatcher(t, 5, Target{URL: server.URL})
+
+			d.Dispatch(Payload{Event: EventChannelError})
+
+			rec.wait(t, 1)
+			rec.expectNoMore(t)
+		})
+	}
+}
+
+func TestRetriesStopAtMaxRetries(t *testing.T) {
+	rec, server := newRecorder(t, http.StatusServiceUnavailable)
+	d := startDispatcher(t, 2, Target{URL: server.URL})
+
+	d.Dispatch(Payload{Event: EventChannelError})
+
+	rec.wait(t, 3) // Intento inicial + 2 reintentos
+	rec.expectNoMore(t)
+}
+
+// Un destino que reintenta o no responde no debe retrasar a los demás
+func TestFailingTargetDoesNotDelayOthers(t *testing.T) {
+	block := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(block) })
+
+	failing, failingServer := newRecorder(t, http.StatusInternalServerError)
+	healthy, healthyServer := newRecorder(t, http.StatusOK)
+	d := NewDispatcher([]Target{{URL: slow.URL}, {URL: failingServer.URL}, {URL: healthyServer.URL}}, 5)
+	d.retryDelay = time.Hour // El reintento queda programado, sin ocupar el worker
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		d.Wait()
+	})
+
+	for i := 0; i < 5; i++ {
+		d.Dispatch(Payload{Event: EventChannelError})
+	}
+
+	healthy.wait(t, 5)
+	failing.wait(t, 5) // Los eventos nuevos no esperan al reintento pendiente
+}
+
+func TestSetTargetsDropsRemovedTargets(t *testing.T) {
+	rec, server := newRecorder(t, http.StatusInternalServerError)
+	d := startDispatcher(t, 5, Target{URL: server.URL})
+
+	d.Dispatch(Payload{Event: EventChannelError})
+	rec.wait(t, 1)
+	d.SetTargets(nil)
+
+	rec.expectNoMore(t) // El reintento programado se descarta
+	d.Dispatch(Payload{Event: EventChannelError})
+	rec.expectNoMore(t)
+}
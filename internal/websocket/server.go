@@ -2,17 +2,23 @@ package websocket
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// APIClientID identificador con el que /api/channels pasa list_channels al
+// manejador de mensajes (no es un cliente conectado)
+const APIClientID = "api"
+
 // Message representa un mensaje WebSocket
 type Message struct {
 	Action     string                 `json:"action"`
@@ -22,6 +28,32 @@ type Message struct {
 	Parameters map[string]interface{} `json:"parameters,omitempty"`
 }
 
+// StringParam lee un parámetro de texto; ok=false si no viene en el mensaje
+func (m Message) StringParam(key string) (value string, ok bool, err error) {
+	raw, exists := m.Parameters[key]
+	if !exists || raw == nil {
+		return "", false, nil
+	}
+	value, isString := raw.(string)
+	if !isString {
+		return "", true, fmt.Errorf("parameters.%s debe ser texto", key)
+	}
+	return value, true, nil
+}
+
+// IntParam lee un parámetro entero (JSON numérico llega como float64)
+func (m Message) IntParam(key string) (value int, ok bool, err error) {
+	raw, exists := m.Parameters[key]
+	if !exists || raw == nil {
+		return 0, false, nil
+	}
+	number, isNumber := raw.(float64)
+	if !isNumber || number != float64(int(number)) {
+		return 0, true, fmt.Errorf("parameters.%s debe ser un número entero", key)
+	}
+	return int(number), true, nil
+}
+
 // Response representa una respuesta WebSocket
 type Response struct {
 	Success bool        `json:"success"`
@@ -35,16 +67,21 @@ type Response struct {
 type ClientInfo struct {
 	ID            string    `json:"id"`
 	Name          string    `json:"name"`
+	Key           string    `json:"key"` // Identidad estable entre reconexiones
 	ConnectedAt   time.Time `json:"connectedAt"`
 	LastMessageAt time.Time `json:"lastMessageAt"`
 	MessageCount  int       `json:"messageCount"`
 	RemoteAddr    string    `json:"remoteAddr"`
+	Transport     string    `json:"transport"` // websocket | socketio
 }
 
 // Client representa un cliente WebSocket conectado
 type Client struct {
 	ID            string
 	Name          string
+	Key           string // Identidad estable (ver clientKey)
+	nameKey       bool   // Key derivada del nombre: no se comparte entre conexiones vivas
+	token         string // Token presentado al conectar (ver HasToken)
 	conn          *websocket.Conn
 	send          chan []byte
 	server        *Server
@@ -52,25 +89,43 @@ type Client struct {
 	lastMessageAt time.Time
 	messageCount  int
 	remoteAddr    string
+	transport     string      // websocket | socketio
+	sio           *sioSession // Sesión Engine.IO (solo clientes Socket.IO)
+	lifecycle     sync.Mutex  // Serializa alta y baja junto con sus callbacks
+	removed       bool        // Ya dado de baja: no se vuelve a registrar
 }
 
 // Server servidor WebSocket
 type Server struct {
 	port               int
 	clients            map[string]*Client
+	sioSessions        map[string]*sioSession
 	mutex              sync.RWMutex
 	upgrader           websocket.Upgrader
 	messageHandler     func(clientID string, message []byte) []byte
 	onClientConnect    func(client ClientInfo)
-	onClientDisconnect func(clientID string)
+	onClientDisconnect func(client ClientInfo)
 	httpServer         *http.Server
+	handlers           map[string]http.HandlerFunc // Endpoints adicionales registrados por la aplicación
+	messagesReceived   atomic.Uint64
+	messagesSent       atomic.Uint64
+}
+
+// Stats estadísticas del servidor WebSocket
+type Stats struct {
+	Clients          int    `json:"clients"`
+	SocketIOSessions int    `json:"socketIOSessions"`
+	MessagesReceived uint64 `json:"messagesReceived"`
+	MessagesSent     uint64 `json:"messagesSent"`
 }
 
 // NewServer crea un nuevo servidor WebSocket
 func NewServer(port int, handler func(clientID string, message []byte) []byte) *Server {
 	return &Server{
-		port:    port,
-		clients: make(map[string]*Client),
+		port:        port,
+		clients:     make(map[string]*Client),
+		sioSessions: make(map[string]*sioSession),
+		handlers:    make(map[string]http.HandlerFunc),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -86,8 +141,12 @@ func NewServer(port int, handler func(clientID string, message []byte) []byte) *
 func (s *Server) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/ws", s.handleConnection)
+	mux.HandleFunc("/socket.io/", s.handleSocketIO)
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/api/channels", s.handleChannelsAPI)
+	for pattern, handler := range s.handlers {
+		mux.HandleFunc(pattern, handler)
+	}
 
 	s.httpServer = &http.Server{
 		Addr:    fmt.Sprintf(":%d", s.port),
@@ -109,6 +168,11 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// Handle registra un endpoint HTTP adicional (debe llamarse antes de Start)
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.handlers[pattern] = handler
+}
+
 // Stop detiene el servidor WebSocket
 func (s *Server) Stop() {
 	if s.httpServer != nil {
@@ -117,10 +181,24 @@ func (s *Server) Stop() {
 		s.httpServer.Shutdown(ctx)
 	}
 
+	// Cerrar todas las sesiones Socket.IO
+	s.mutex.RLock()
+	sessions := make([]*sioSession, 0, len(s.sioSessions))
+	for _, sess := range s.sioSessions {
+		sessions = append(sessions, sess)
+	}
+	s.mutex.RUnlock()
+	for _, sess := range sessions {
+		sess.sendPacket(string(eioClose))
+		sess.close("server shutdown")
+	}
+
 	// Cerrar todas las conexiones de clientes
 	s.mutex.Lock()
 	for _, client := range s.clients {
-		client.conn.Close()
+		if client.conn != nil {
+			client.conn.Close()
+		}
 	}
 	s.clients = make(map[string]*Client)
 	s.mutex.Unlock()
@@ -137,7 +215,9 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 	}
 
 	clientID := uuid.New().String()
-	clientName := r.URL.Query().Get("name")
+	query := r.URL.Query()
+	clientName := query.Get("name")
+	key := clientKey(query.Get("clientKey"), clientName, clientID)
 	if clientName == "" {
 		clientName = "Aximmetry_" + clientID[:8]
 	}
@@ -145,11 +225,15 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 	client := &Client{
 		ID:          clientID,
 		Name:        clientName,
+		Key:         key,
+		nameKey:     query.Get("clientKey") == "" && query.Get("name") != "",
+		token:       query.Get("token"),
 		conn:        conn,
 		send:        make(chan []byte, 256),
 		server:      s,
 		connectedAt: time.Now(),
 		remoteAddr:  r.RemoteAddr,
+		transport:   "websocket",
 	}
 
 	s.registerClient(client)
@@ -160,8 +244,9 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 		Action:  "connected",
 		Message: "Conectado al servidor SRT Stream",
 		Data: map[string]interface{}{
-			"clientId": clientID,
-			"name":     clientName,
+			"clientId":  clientID,
+			"name":      clientName,
+			"clientKey": client.Key,
 		},
 	}
 	welcomeBytes, _ := json.Marshal(welcome)
@@ -174,12 +259,16 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Cliente conectado: %s (%s) desde %s", clientName, clientID, r.RemoteAddr)
 }
 
-// handleHealth endpoint de salud
+// handleHealth endpoint de salud básico (ver /health/live y /health/ready)
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	clients := len(s.clients)
+	s.mutex.RUnlock()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":  "healthy",
-		"clients": len(s.clients),
+		"clients": clients,
 		"time":    time.Now().Format(time.RFC3339),
 	})
 }
@@ -196,50 +285,100 @@ func (s *Server) handleChannelsAPI(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Este endpoint será manejado por la aplicación principal
-	response := s.messageHandler("api", []byte(`{"action":"list_channels"}`))
+	response := s.messageHandler(APIClientID, []byte(`{"action":"list_channels"}`))
 	w.Write(response)
 }
 
-// registerClient registra un nuevo cliente
-func (s *Server) registerClient(client *Client) {
+// clientKey identidad estable de un cliente: la clave enviada al conectar, si no
+// el nombre elegido por el cliente (salvo que otra conexión viva ya lo use, ver
+// registerClient) y, si tampoco hay, el ID de la conexión (solo válido para esa
+// conexión)
+func clientKey(key, name, clientID string) string {
+	if key != "" {
+		return key
+	}
+	if name != "" {
+		return name
+	}
+	return clientID
+}
+
+// info copia la información pública del cliente
+func (c *Client) info() ClientInfo {
+	return ClientInfo{
+		ID:            c.ID,
+		Name:          c.Name,
+		Key:           c.Key,
+		ConnectedAt:   c.connectedAt,
+		LastMessageAt: c.lastMessageAt,
+		MessageCount:  c.messageCount,
+		RemoteAddr:    c.remoteAddr,
+		Transport:     c.transport,
+	}
+}
+
+// registerClient registra un nuevo cliente. Retorna false si el cliente ya se
+// dio de baja (ej: la sesión Socket.IO se cerró durante el CONNECT); en ese
+// caso no se agrega ni se notifica la conexión
+func (s *Server) registerClient(client *Client) bool {
+	client.lifecycle.Lock()
+	defer client.lifecycle.Unlock()
+	if client.removed {
+		return false
+	}
+
 	s.mutex.Lock()
+	// Un nombre no identifica en exclusiva: si otra conexión viva ya lo usa
+	// como identidad, esta conexión se identifica con su propio ID
+	if client.nameKey && s.hasClientKeyLocked(client.Key) {
+		client.Key = client.ID
+	}
 	s.clients[client.ID] = client
+	info := client.info()
 	s.mutex.Unlock()
 
-	// Notificar conexión
+	// Notificar conexión (bajo lifecycle: la desconexión nunca se adelanta)
 	if s.onClientConnect != nil {
-		s.onClientConnect(ClientInfo{
-			ID:            client.ID,
-			Name:          client.Name,
-			ConnectedAt:   client.connectedAt,
-			LastMessageAt: client.lastMessageAt,
-			MessageCount:  client.messageCount,
-			RemoteAddr:    client.remoteAddr,
-		})
+		s.onClientConnect(info)
 	}
+	return true
 }
 
-// unregisterClient elimina un cliente
+// unregisterClient elimina un cliente. Solo notifica la desconexión de un
+// cliente que llegó a registrarse, y una sola vez
 func (s *Server) unregisterClient(client *Client) {
+	client.lifecycle.Lock()
+	defer client.lifecycle.Unlock()
+	if client.removed {
+		return
+	}
+	client.removed = true
+
 	clientID := client.ID
 
 	s.mutex.Lock()
-	if _, ok := s.clients[clientID]; ok {
+	_, registered := s.clients[clientID]
+	if registered {
 		delete(s.clients, clientID)
 		close(client.send)
 	}
+	info := client.info()
 	s.mutex.Unlock()
 
+	if !registered {
+		return
+	}
+
 	log.Printf("Cliente desconectado: %s (%s)", client.Name, clientID)
 
 	// Notificar desconexión
 	if s.onClientDisconnect != nil {
-		s.onClientDisconnect(clientID)
+		s.onClientDisconnect(info)
 	}
 }
 
 // SetClientCallbacks establece los callbacks para eventos de clientes
-func (s *Server) SetClientCallbacks(onConnect func(ClientInfo), onDisconnect func(string)) {
+func (s *Server) SetClientCallbacks(onConnect func(ClientInfo), onDisconnect func(ClientInfo)) {
 	s.onClientConnect = onConnect
 	s.onClientDisconnect = onDisconnect
 }
@@ -251,19 +390,71 @@ func (s *Server) GetClients() []ClientInfo {
 
 	clients := make([]ClientInfo, 0, len(s.clients))
 	for _, c := range s.clients {
-		clients = append(clients, ClientInfo{
-			ID:            c.ID,
-			Name:          c.Name,
-			ConnectedAt:   c.connectedAt,
-			LastMessageAt: c.lastMessageAt,
-			MessageCount:  c.messageCount,
-			RemoteAddr:    c.remoteAddr,
-		})
+		clients = append(clients, c.info())
 	}
 
 	return clients
 }
 
+// GetClient retorna la información de un cliente conectado
+func (s *Server) GetClient(clientID string) (ClientInfo, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	c, ok := s.clients[clientID]
+	if !ok {
+		return ClientInfo{}, false
+	}
+
+	return c.info(), true
+}
+
+// HasToken indica si el cliente presentó al conectar (?token= o auth.token) el
+// token indicado. Un token vacío nunca coincide.
+func (s *Server) HasToken(clientID, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	s.mutex.RLock()
+	c, exists := s.clients[clientID]
+	s.mutex.RUnlock()
+
+	return exists && subtle.ConstantTimeCompare([]byte(c.token), []byte(token)) == 1
+}
+
+// HasClientKey indica si hay algún cliente conectado con esa identidad
+func (s *Server) HasClientKey(key string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.hasClientKeyLocked(key)
+}
+
+// hasClientKeyLocked igual que HasClientKey; requiere s.mutex tomado
+func (s *Server) hasClientKeyLocked(key string) bool {
+	for _, c := range s.clients {
+		if c.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetStats retorna las estadísticas del servidor
+func (s *Server) GetStats() Stats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return Stats{
+		Clients:          len(s.clients),
+		SocketIOSessions: len(s.sioSessions),
+		MessagesReceived: s.messagesReceived.Load(),
+		MessagesSent:     s.messagesSent.Load(),
+	}
+}
+
 // Broadcast envía un mensaje a todos los clientes
 func (s *Server) Broadcast(message []byte) {
 	s.mutex.RLock()
@@ -278,12 +469,41 @@ func (s *Server) Broadcast(message []byte) {
 	}
 }
 
+// BroadcastExcept envía un mensaje a todos los clientes salvo uno (el que originó el cambio)
+func (s *Server) BroadcastExcept(excludeID string, message []byte) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for id, client := range s.clients {
+		if id == excludeID {
+			continue
+		}
+		select {
+		case client.send <- message:
+		default:
+			// Canal lleno, cliente lento
+		}
+	}
+}
+
+// recordMessage actualiza las estadísticas de un cliente al recibir un mensaje
+// (bajo el lock: GetClients las lee)
+func (s *Server) recordMessage(c *Client) {
+	s.mutex.Lock()
+	c.lastMessageAt = time.Now()
+	c.messageCount++
+	s.mutex.Unlock()
+	s.messagesReceived.Add(1)
+}
+
 // SendToClient envía un mensaje a un cliente específico
 func (s *Server) SendToClient(clientID string, message []byte) error {
+	// El lock se mantiene durante el envío (no bloqueante): unregisterClient
+	// cierra el canal send con el lock tomado
 	s.mutex.RLock()
-	client, ok := s.clients[clientID]
-	s.mutex.RUnlock()
+	defer s.mutex.RUnlock()
 
+	client, ok := s.clients[clientID]
 	if !ok {
 		return fmt.Errorf("cliente no encontrado: %s", clientID)
 	}
@@ -319,8 +539,7 @@ func (c *Client) readPump() {
 			break
 		}
 
-		c.lastMessageAt = time.Now()
-		c.messageCount++
+		c.server.recordMessage(c)
 
 		// Procesar mensaje y obtener respuesta
 		response := c.server.messageHandler(c.ID, message)
@@ -352,12 +571,14 @@ func (c *Client) writePump() {
 				return
 			}
 			w.Write(message)
+			c.server.messagesSent.Add(1)
 
 			// Agregar mensajes en cola al mismo write
 			n := len(c.send)
 			for i := 0; i < n; i++ {
 				w.Write([]byte{'\n'})
 				w.Write(<-c.send)
+				c.server.messagesSent.Add(1)
 			}
 
 			if err := w.Close(); err != nil {
@@ -0,0 +1,44 @@
+package websocket
+
+import "testing"
+
+func newTestClient(s *Server, id, name, key string) *Client {
+	return &Client{
+		ID:      id,
+		Name:    name,
+		Key:     clientKey(key, name, id),
+		nameKey: key == "" && name != "",
+		send:    make(chan []byte, 1),
+		server:  s,
+	}
+}
+
+// El nombre solo sirve de identidad mientras ninguna otra conexión viva lo use;
+// una clave explícita sí se comparte
+func TestNameKeyNotShared(t *testing.T) {
+	s := NewServer(0, nil)
+
+	first := newTestClient(s, "id-1", "Aximmetry", "")
+	second := newTestClient(s, "id-2", "Aximmetry", "")
+	s.registerClient(first)
+	s.registerClient(second)
+	if first.Key != "Aximmetry" || second.Key != "id-2" {
+		t.Errorf("claves = %q, %q; se esperaba Aximmetry, id-2", first.Key, second.Key)
+	}
+
+	keyed := newTestClient(s, "id-3", "Aximmetry", "estudio")
+	other := newTestClient(s, "id-4", "Otro", "estudio")
+	s.registerClient(keyed)
+	s.registerClient(other)
+	if keyed.Key != "estudio" || other.Key != "estudio" {
+		t.Errorf("claves explícitas = %q, %q; se esperaba estudio", keyed.Key, other.Key)
+	}
+
+	// Al irse la primera conexión el nombre vuelve a estar libre
+	s.unregisterClient(first)
+	third := newTestClient(s, "id-5", "Aximmetry", "")
+	s.registerClient(third)
+	if third.Key != "Aximmetry" {
+		t.Errorf("clave tras reconectar = %q, se esperaba Aximmetry", third.Key)
+	}
+}
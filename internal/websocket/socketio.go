@@ -0,0 +1,681 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Implementación mínima de Engine.IO v4 / Socket.IO v5 sobre /socket.io/
+// Soporta los transportes polling y websocket (con upgrade), el namespace
+// principal "/", eventos con y sin ack. Los paquetes binarios no están soportados.
+
+// Tipos de paquete Engine.IO
+const (
+	eioOpen    = '0'
+	eioClose   = '1'
+	eioPing    = '2'
+	eioPong    = '3'
+	eioMessage = '4'
+	eioUpgrade = '5'
+	eioNoop    = '6'
+)
+
+// Tipos de paquete Socket.IO
+const (
+	sioConnect      = '0'
+	sioDisconnect   = '1'
+	sioEvent        = '2'
+	sioAck          = '3'
+	sioConnectError = '4'
+	sioBinaryEvent  = '5'
+	sioBinaryAck    = '6'
+)
+
+const (
+	eioPingInterval = 25 * time.Second
+	eioPingTimeout  = 20 * time.Second
+	eioMaxPayload   = 1000000
+	eioRecordSep    = "\x1e" // Separador de paquetes en polling (EIO v4)
+)
+
+// sioSession sesión Engine.IO asociada (tras el CONNECT de Socket.IO) a un Client
+type sioSession struct {
+	sid    string
+	server *Server
+	name   string // Nombre solicitado en query (?name=) o en auth
+	key    string // Identidad estable en query (?clientKey=) o en auth
+	token  string // Token en query (?token=) o en auth (ver Server.HasToken)
+
+	mutex      sync.Mutex
+	transport  string // "polling" | "websocket"
+	ws         *websocket.Conn
+	wsMutex    sync.Mutex
+	pollQueue  []string
+	pollNotify chan struct{}
+	polling    bool // Hay un GET de polling pendiente
+	upgrading  bool
+	client     *Client // nil hasta que llega el CONNECT de Socket.IO
+	remoteAddr string
+	lastPong   time.Time
+	closed     bool
+	done       chan struct{}
+}
+
+// handleSocketIO maneja las peticiones Engine.IO (polling y websocket)
+func (s *Server) handleSocketIO(w http.ResponseWriter, r *http.Request) {
+	// CORS para clientes de navegador (polling usa XHR)
+	origin := r.Header.Get("Origin")
+	if origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("EIO") != "4" {
+		http.Error(w, `{"code":5,"message":"Unsupported protocol version"}`, http.StatusBadRequest)
+		return
+	}
+
+	transport := query.Get("transport")
+	sid := query.Get("sid")
+
+	switch transport {
+	case "polling":
+		if sid == "" {
+			s.sioHandshakePolling(w, r)
+			return
+		}
+		sess := s.getSIOSession(sid)
+		if sess == nil {
+			http.Error(w, `{"code":1,"message":"Session ID unknown"}`, http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			sess.handlePollGet(w, r)
+		case http.MethodPost:
+			sess.handlePollPost(w, r)
+		default:
+			http.Error(w, `{"code":2,"message":"Bad handshake method"}`, http.StatusBadRequest)
+		}
+	case "websocket":
+		var sess *sioSession
+		if sid != "" {
+			sess = s.getSIOSession(sid)
+			if sess == nil {
+				http.Error(w, `{"code":1,"message":"Session ID unknown"}`, http.StatusBadRequest)
+				return
+			}
+		}
+		conn, err := s.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Printf("[Socket.IO] Error upgrading connection: %v", err)
+			return
+		}
+		conn.SetReadLimit(eioMaxPayload)
+		if sess == nil {
+			s.sioHandshakeWebSocket(conn, r)
+		} else {
+			sess.handleUpgrade(conn)
+		}
+	default:
+		http.Error(w, `{"code":0,"message":"Transport unknown"}`, http.StatusBadRequest)
+	}
+}
+
+// newSIOSession crea y registra una nueva sesión Engine.IO
+func (s *Server) newSIOSession(r *http.Request, transport string) *sioSession {
+	sess := &sioSession{
+		sid:        uuid.New().String(),
+		server:     s,
+		name:       r.URL.Query().Get("name"),
+		key:        r.URL.Query().Get("clientKey"),
+		token:      r.URL.Query().Get("token"),
+		transport:  transport,
+		pollNotify: make(chan struct{}, 1),
+		remoteAddr: r.RemoteAddr,
+		lastPong:   time.Now(),
+		done:       make(chan struct{}),
+	}
+
+	s.mutex.Lock()
+	s.sioSessions[sess.sid] = sess
+	s.mutex.Unlock()
+
+	go sess.pingLoop()
+
+	return sess
+}
+
+// getSIOSession obtiene una sesión Engine.IO por sid
+func (s *Server) getSIOSession(sid string) *sioSession {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.sioSessions[sid]
+}
+
+// openPacket construye el paquete OPEN del handshake
+func (sess *sioSession) openPacket(upgrades []string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"sid":          sess.sid,
+		"upgrades":     upgrades,
+		"pingInterval": int(eioPingInterval / time.Millisecond),
+		"pingTimeout":  int(eioPingTimeout / time.Millisecond),
+		"maxPayload":   eioMaxPayload,
+	})
+	return string(eioOpen) + string(data)
+}
+
+// sioHandshakePolling inicia una sesión con transporte polling
+func (s *Server) sioHandshakePolling(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"code":2,"message":"Bad handshake method"}`, http.StatusBadRequest)
+		return
+	}
+	sess := s.newSIOSession(r, "polling")
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	io.WriteString(w, sess.openPacket([]string{"websocket"}))
+}
+
+// sioHandshakeWebSocket inicia una sesión directamente sobre websocket
+func (s *Server) sioHandshakeWebSocket(conn *websocket.Conn, r *http.Request) {
+	sess := s.newSIOSession(r, "websocket")
+	sess.ws = conn
+	if err := sess.writeWS(sess.openPacket([]string{})); err != nil {
+		sess.close("transport error")
+		return
+	}
+	sess.readWS()
+}
+
+// handleUpgrade gestiona el upgrade de polling a websocket
+func (sess *sioSession) handleUpgrade(conn *websocket.Conn) {
+	conn.SetReadDeadline(time.Now().Add(eioPingTimeout))
+
+	// 1. El cliente envía "2probe" y espera "3probe"
+	_, data, err := conn.ReadMessage()
+	if err != nil || string(data) != string(eioPing)+"probe" {
+		conn.Close()
+		return
+	}
+	sess.wsMutex.Lock()
+	err = conn.WriteMessage(websocket.TextMessage, []byte(string(eioPong)+"probe"))
+	sess.wsMutex.Unlock()
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	// 2. Liberar el GET de polling pendiente con un NOOP
+	sess.mutex.Lock()
+	sess.upgrading = true
+	sess.mutex.Unlock()
+	sess.notifyPoll()
+
+	// 3. Esperar el paquete UPGRADE
+	_, data, err = conn.ReadMessage()
+	if err != nil || string(data) != string(eioUpgrade) {
+		sess.mutex.Lock()
+		sess.upgrading = false
+		sess.mutex.Unlock()
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	// 4. Cambiar de transporte y vaciar la cola pendiente
+	sess.mutex.Lock()
+	sess.transport = "websocket"
+	sess.ws = conn
+	sess.upgrading = false
+	pending := sess.pollQueue
+	sess.pollQueue = nil
+	sess.mutex.Unlock()
+	sess.notifyPoll()
+
+	for _, pkt := range pending {
+		if err := sess.writeWS(pkt); err != nil {
+			sess.close("transport error")
+			return
+		}
+	}
+
+	sess.readWS()
+}
+
+// readWS lee paquetes del transporte websocket hasta que se cierra
+func (sess *sioSession) readWS() {
+	for {
+		_, data, err := sess.ws.ReadMessage()
+		if err != nil {
+			sess.close("transport close")
+			return
+		}
+		sess.handlePacket(string(data))
+	}
+}
+
+// writeWS escribe un paquete en el websocket
+func (sess *sioSession) writeWS(packet string) error {
+	sess.wsMutex.Lock()
+	defer sess.wsMutex.Unlock()
+	sess.ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return sess.ws.WriteMessage(websocket.TextMessage, []byte(packet))
+}
+
+// handlePollGet entrega los paquetes pendientes (long-polling)
+func (sess *sioSession) handlePollGet(w http.ResponseWriter, r *http.Request) {
+	sess.mutex.Lock()
+	if sess.polling || sess.transport != "polling" {
+		sess.mutex.Unlock()
+		sess.close("transport error")
+		http.Error(w, `{"code":3,"message":"Bad request"}`, http.StatusBadRequest)
+		return
+	}
+	sess.polling = true
+	sess.mutex.Unlock()
+
+	defer func() {
+		sess.mutex.Lock()
+		sess.polling = false
+		sess.mutex.Unlock()
+	}()
+
+	timeout := time.NewTimer(eioPingInterval + eioPingTimeout)
+	defer timeout.Stop()
+
+	for {
+		sess.mutex.Lock()
+		var packets []string
+		switch {
+		case sess.closed:
+			packets = []string{string(eioClose)}
+		case len(sess.pollQueue) > 0:
+			packets = sess.pollQueue
+			sess.pollQueue = nil
+		case sess.upgrading || sess.transport != "polling":
+			packets = []string{string(eioNoop)}
+		}
+		sess.mutex.Unlock()
+
+		if packets != nil {
+			w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+			io.WriteString(w, strings.Join(packets, eioRecordSep))
+			return
+		}
+
+		select {
+		case <-sess.pollNotify:
+		case <-timeout.C:
+			w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+			io.WriteString(w, string(eioNoop))
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handlePollPost recibe paquetes del cliente vía polling
+func (sess *sioSession) handlePollPost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, eioMaxPayload+1))
+	if err != nil || len(body) > eioMaxPayload {
+		sess.close("transport error")
+		http.Error(w, `{"code":3,"message":"Bad request"}`, http.StatusBadRequest)
+		return
+	}
+
+	for _, pkt := range strings.Split(string(body), eioRecordSep) {
+		if pkt != "" {
+			sess.handlePacket(pkt)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	io.WriteString(w, "ok")
+}
+
+// notifyPoll despierta al GET de polling pendiente
+func (sess *sioSession) notifyPoll() {
+	select {
+	case sess.pollNotify <- struct{}{}:
+	default:
+	}
+}
+
+// sendPacket envía un paquete Engine.IO por el transporte activo
+func (sess *sioSession) sendPacket(packet string) {
+	sess.mutex.Lock()
+	if sess.closed {
+		sess.mutex.Unlock()
+		return
+	}
+	if sess.transport == "websocket" && sess.ws != nil {
+		sess.mutex.Unlock()
+		if err := sess.writeWS(packet); err != nil {
+			sess.close("transport error")
+		}
+		return
+	}
+	sess.pollQueue = append(sess.pollQueue, packet)
+	sess.mutex.Unlock()
+	sess.notifyPoll()
+}
+
+// pingLoop envía PING periódicos y cierra la sesión si no hay PONG
+func (sess *sioSession) pingLoop() {
+	ticker := time.NewTicker(eioPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sess.done:
+			return
+		case <-ticker.C:
+			sess.mutex.Lock()
+			lastPong := sess.lastPong
+			sess.mutex.Unlock()
+			if time.Since(lastPong) > eioPingInterval+eioPingTimeout {
+				sess.close("ping timeout")
+				return
+			}
+			sess.sendPacket(string(eioPing))
+		}
+	}
+}
+
+// handlePacket procesa un paquete Engine.IO recibido
+func (sess *sioSession) handlePacket(packet string) {
+	if packet == "" {
+		return
+	}
+
+	switch packet[0] {
+	case eioPing:
+		// Compatibilidad con clientes que envían ping (EIO v3)
+		sess.sendPacket(string(eioPong) + packet[1:])
+	case eioPong:
+		sess.mutex.Lock()
+		sess.lastPong = time.Now()
+		sess.mutex.Unlock()
+	case eioMessage:
+		sess.handleSocketIOPacket(packet[1:])
+	case eioClose:
+		sess.close("client close")
+	case eioNoop, eioUpgrade:
+		// Nada que hacer
+	default:
+		log.Printf("[Socket.IO] Paquete Engine.IO desconocido: %q", packet)
+	}
+}
+
+// handleSocketIOPacket procesa un paquete Socket.IO
+func (sess *sioSession) handleSocketIOPacket(packet string) {
+	if packet == "" {
+		return
+	}
+
+	packetType := packet[0]
+	rest := packet[1:]
+
+	// Namespace opcional: "/admin,..."
+	namespace := "/"
+	if strings.HasPrefix(rest, "/") {
+		if idx := strings.Index(rest, ","); idx >= 0 {
+			namespace = rest[:idx]
+			rest = rest[idx+1:]
+		} else {
+			namespace = rest
+			rest = ""
+		}
+	}
+
+	switch packetType {
+	case sioConnect:
+		if namespace != "/" {
+			sess.sendPacket(string(eioMessage) + string(sioConnectError) + namespace + `,{"message":"Invalid namespace"}`)
+			return
+		}
+		sess.handleConnect(rest)
+	case sioDisconnect:
+		sess.close("client namespace disconnect")
+	case sioEvent:
+		ackID, payload := splitAckID(rest)
+		sess.handleEvent(ackID, payload)
+	case sioAck:
+		// El servidor no emite eventos con ack, se ignoran
+	case sioBinaryEvent, sioBinaryAck:
+		log.Printf("[Socket.IO] Paquetes binarios no soportados (sesión %s)", sess.sid)
+	default:
+		log.Printf("[Socket.IO] Paquete desconocido: %q", packet)
+	}
+}
+
+// handleConnect registra el cliente Socket.IO en el servidor
+func (sess *sioSession) handleConnect(payload string) {
+	sess.mutex.Lock()
+	if sess.client != nil || sess.closed {
+		sess.mutex.Unlock()
+		return
+	}
+
+	// auth opcional: {"name": "...", "clientKey": "...", "token": "..."}
+	if payload != "" {
+		var auth map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &auth); err == nil {
+			if name, ok := auth["name"].(string); ok && name != "" {
+				sess.name = name
+			}
+			if key, ok := auth["clientKey"].(string); ok && key != "" {
+				sess.key = key
+			}
+			if token, ok := auth["token"].(string); ok && token != "" {
+				sess.token = token
+			}
+		}
+	}
+
+	clientID := uuid.New().String()
+	clientName := sess.name
+	key := clientKey(sess.key, clientName, clientID)
+	if clientName == "" {
+		clientName = "SocketIO_" + clientID[:8]
+	}
+
+	client := &Client{
+		ID:          clientID,
+		Name:        clientName,
+		Key:         key,
+		nameKey:     sess.key == "" && sess.name != "",
+		token:       sess.token,
+		send:        make(chan []byte, 256),
+		server:      sess.server,
+		connectedAt: time.Now(),
+		remoteAddr:  sess.remoteAddr,
+		transport:   "socketio",
+		sio:         sess,
+	}
+	sess.client = client
+	sess.mutex.Unlock()
+
+	sess.sendPacket(string(eioMessage) + string(sioConnect) + fmt.Sprintf(`{"sid":%q}`, clientID))
+
+	// Si close() ganó la carrera, el cliente ya quedó dado de baja y no se
+	// registra: no hay cliente zombi ni outboundPump huérfano
+	if !sess.server.registerClient(client) {
+		return
+	}
+
+	welcome := Response{
+		Success: true,
+		Action:  "connected",
+		Message: "Conectado al servidor SRT Stream",
+		Data: map[string]interface{}{
+			"clientId":  clientID,
+			"name":      clientName,
+			"clientKey": client.Key,
+		},
+	}
+	welcomeBytes, _ := json.Marshal(welcome)
+	// SendToClient descarta el mensaje si la sesión ya se cerró (send cerrado)
+	sess.server.SendToClient(clientID, welcomeBytes)
+
+	go sess.outboundPump(client)
+
+	log.Printf("Cliente Socket.IO conectado: %s (%s) desde %s", clientName, clientID, sess.remoteAddr)
+}
+
+// outboundPump convierte las respuestas JSON del cliente en eventos Socket.IO
+func (sess *sioSession) outboundPump(client *Client) {
+	for message := range client.send {
+		sess.server.messagesSent.Add(1)
+		sess.sendPacket(string(eioMessage) + string(sioEvent) + responseToEvent(message))
+	}
+}
+
+// handleEvent procesa un evento ["accion", {...}] y responde con ack o evento
+func (sess *sioSession) handleEvent(ackID string, payload string) {
+	sess.mutex.Lock()
+	client := sess.client
+	sess.mutex.Unlock()
+	if client == nil {
+		return
+	}
+
+	message, err := eventToMessage(payload)
+	var response []byte
+	if err != nil {
+		response = ErrorResponse("invalid_message", err.Error())
+	} else {
+		sess.server.recordMessage(client)
+		response = sess.server.messageHandler(client.ID, message)
+	}
+	if response == nil {
+		return
+	}
+
+	if ackID != "" {
+		sess.server.messagesSent.Add(1)
+		sess.sendPacket(string(eioMessage) + string(sioAck) + ackID + "[" + string(response) + "]")
+		return
+	}
+
+	// La sesión puede cerrarse mientras se procesa el evento: SendToClient
+	// comprueba que el cliente sigue registrado antes de enviar
+	if err := sess.server.SendToClient(client.ID, response); err != nil {
+		log.Printf("[Socket.IO] Respuesta descartada para cliente %s: %v", client.ID, err)
+	}
+}
+
+// close cierra la sesión Engine.IO y desregistra el cliente asociado
+func (sess *sioSession) close(reason string) {
+	sess.mutex.Lock()
+	if sess.closed {
+		sess.mutex.Unlock()
+		return
+	}
+	sess.closed = true
+	close(sess.done)
+	ws := sess.ws
+	client := sess.client
+	sess.mutex.Unlock()
+	sess.notifyPoll()
+
+	sess.server.mutex.Lock()
+	delete(sess.server.sioSessions, sess.sid)
+	sess.server.mutex.Unlock()
+
+	if ws != nil {
+		ws.Close()
+	}
+	if client != nil {
+		sess.server.unregisterClient(client)
+	}
+
+	log.Printf("[Socket.IO] Sesión %s cerrada: %s", sess.sid, reason)
+}
+
+// splitAckID separa el id de ack opcional del payload JSON
+func splitAckID(payload string) (string, string) {
+	i := 0
+	for i < len(payload) && payload[i] >= '0' && payload[i] <= '9' {
+		i++
+	}
+	return payload[:i], payload[i:]
+}
+
+// eventToMessage convierte un evento Socket.IO en un mensaje JSON del protocolo
+// Formatos aceptados:
+//
+//	["play_video", {"filePath": "..."}]
+//	["message", {"action": "play_video", ...}]
+//	["message", "{\"action\":\"play_video\",...}"]
+func eventToMessage(payload string) ([]byte, error) {
+	var args []json.RawMessage
+	if err := json.Unmarshal([]byte(payload), &args); err != nil || len(args) == 0 {
+		return nil, fmt.Errorf("evento Socket.IO inválido")
+	}
+
+	var event string
+	if err := json.Unmarshal(args[0], &event); err != nil {
+		return nil, fmt.Errorf("nombre de evento inválido")
+	}
+
+	var data json.RawMessage
+	if len(args) > 1 {
+		data = args[1]
+		// Permitir JSON serializado como string
+		var str string
+		if err := json.Unmarshal(data, &str); err == nil {
+			data = json.RawMessage(str)
+		}
+	}
+
+	fields := map[string]interface{}{}
+	if len(data) > 0 && !bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("datos del evento '%s' inválidos", event)
+		}
+	}
+
+	if event != "message" || fields["action"] == nil {
+		fields["action"] = event
+	}
+
+	return json.Marshal(fields)
+}
+
+// responseToEvent convierte una respuesta JSON en el payload de un evento Socket.IO
+// usando el campo "action" como nombre de evento: ["play_started", {...}]
+func responseToEvent(message []byte) string {
+	var head struct {
+		Action string `json:"action"`
+	}
+	event := "message"
+	if err := json.Unmarshal(message, &head); err == nil && head.Action != "" {
+		event = head.Action
+	} else if !json.Valid(message) {
+		quoted, _ := json.Marshal(string(message))
+		message = quoted
+	}
+	name, _ := json.Marshal(event)
+	return "[" + string(name) + "," + string(message) + "]"
+}
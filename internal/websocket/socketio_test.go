@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// callbackRecorder registra el orden de los callbacks de conexión
+type callbackRecorder struct {
+	mutex     sync.Mutex
+	connected map[string]bool
+	events    []string
+	errors    []string
+}
+
+func newCallbackRecorder(s *Server) *callbackRecorder {
+	rec := &callbackRecorder{connected: make(map[string]bool)}
+	s.SetClientCallbacks(func(info ClientInfo) {
+		rec.mutex.Lock()
+		defer rec.mutex.Unlock()
+		rec.connected[info.ID] = true
+		rec.events = append(rec.events, "connect")
+	}, func(info ClientInfo) {
+		rec.mutex.Lock()
+		defer rec.mutex.Unlock()
+		if !rec.connected[info.ID] {
+			rec.errors = append(rec.errors, "desconexión sin conexión previa: "+info.ID)
+		}
+		delete(rec.connected, info.ID)
+		rec.events = append(rec.events, "disconnect")
+	})
+	return rec
+}
+
+func newTestSession(s *Server) *sioSession {
+	return s.newSIOSession(httptest.NewRequest("GET", "/socket.io/?EIO=4&transport=polling", nil), "polling")
+}
+
+// Un CONNECT que llega con la sesión ya cerrada no registra el cliente
+func TestSocketIOConnectAfterClose(t *testing.T) {
+	s := NewServer(0, nil)
+	rec := newCallbackRecorder(s)
+
+	sess := newTestSession(s)
+	sess.close("test")
+	sess.handleConnect(`{"name":"tardío"}`)
+
+	if n := len(s.GetClients()); n != 0 {
+		t.Errorf("clientes = %d, se esperaba ninguno", n)
+	}
+	if len(rec.events) != 0 {
+		t.Errorf("callbacks = %v, se esperaba ninguno", rec.events)
+	}
+}
+
+// Cerrar la sesión durante el CONNECT nunca deja un cliente zombi y la
+// desconexión siempre se notifica después de la conexión
+func TestSocketIOCloseDuringConnect(t *testing.T) {
+	s := NewServer(0, nil)
+	rec := newCallbackRecorder(s)
+
+	for i := 0; i < 200; i++ {
+		sess := newTestSession(s)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			sess.handleConnect("")
+		}()
+		go func() {
+			defer wg.Done()
+			sess.close("test")
+		}()
+		wg.Wait()
+	}
+
+	if n := len(s.GetClients()); n != 0 {
+		t.Errorf("clientes zombi = %d", n)
+	}
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	for _, err := range rec.errors {
+		t.Error(err)
+	}
+	if len(rec.connected) != 0 {
+		t.Errorf("%d conexiones sin desconexión", len(rec.connected))
+	}
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"log"
+	"os"
+	"sort"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/logger"
@@ -11,18 +14,68 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 
 	"servidor-stream/internal/app"
+	"servidor-stream/internal/config"
 )
 
 //go:embed all:frontend/dist
 var assets embed.FS
 
 func main() {
+	// Directorio de datos e instancia (también SRTSTREAM_DATA_DIR / SRTSTREAM_INSTANCE)
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	dataDir := flags.String("data-dir", "", "directorio de config.json y channels.json")
+	instance := flags.String("instance", "", "nombre de la instancia (archivos en <data-dir>/instances/<nombre>)")
+	printConfig := flags.Bool("print-config", false, "muestra la configuración efectiva (archivo + entorno + flags) y sale")
+	// Un flag por campo de config.json (--web-socket-port, --video-encoder, ...)
+	configFlags := config.RegisterFlags(flags)
+	if err := flags.Parse(os.Args[1:]); err == flag.ErrHelp {
+		os.Exit(0)
+	} else if err != nil {
+		log.Printf("Argumentos ignorados: %v", err)
+	}
+
+	// Precedencia: flags > SRTSTREAM_* > config.json > valores por defecto
+	if err := config.SetOverrides(configFlags); err != nil {
+		log.Fatal(err)
+	}
+
+	dir, err := config.InitDataDir(*dataDir, *instance)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Directorio de datos: %s", dir)
+
+	if *printConfig {
+		cfg, err := config.Effective()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Fatal(err)
+		}
+		sources := config.Overridden()
+		fields := make([]string, 0, len(sources))
+		for field := range sources {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			log.Printf("%s impuesto por %s", field, sources[field])
+		}
+		return
+	}
+
+	title := "Server Stream"
+	if name := config.Instance(); name != "" {
+		title += " - " + name
+	}
+
 	// Crear instancia de la aplicación
 	application := app.NewApp()
 
 	// Crear opciones de la aplicación Wails
-	err := wails.Run(&options.App{
-		Title:     "Server Stream",
+	err = wails.Run(&options.App{
+		Title:     title,
 		Width:     1400,
 		Height:    900,
 		MinWidth:  1200,